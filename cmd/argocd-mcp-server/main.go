@@ -1,15 +1,27 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"net/http"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
-	mcp_server "github.com/mark3labs/mcp-go/server"
 	"github.com/sirupsen/logrus"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/audit"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/config"
 	"github.com/toyamagu-2021/argocd-mcp-server/internal/logging"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/metrics"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/policy"
 	"github.com/toyamagu-2021/argocd-mcp-server/internal/server"
 	"github.com/toyamagu-2021/argocd-mcp-server/internal/tools"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/tracing"
 )
 
 var (
@@ -20,6 +32,32 @@ var (
 
 func main() {
 	versionFlag := flag.Bool("version", false, "Print version information")
+	transportFlag := flag.String("transport", string(server.TransportStdio), "Transport used to serve MCP clients: stdio, sse or http")
+	listenFlag := flag.String("listen", server.DefaultListenAddr, "Listen address for the sse and http transports")
+	baseURLFlag := flag.String("base-url", "", "Public base URL advertised to SSE clients (defaults to the request host)")
+	shutdownTimeoutFlag := flag.Duration("shutdown-timeout", server.DefaultShutdownTimeout, "Maximum time to wait for in-flight tool calls on shutdown")
+	configFlag := flag.String("config", "", "Path to the YAML configuration file defining ArgoCD contexts")
+	contextFlag := flag.String("context", "", "Name of the ArgoCD context to use (defaults to the current context)")
+	argocdConfigFlag := flag.String("argocd-config", "", "Import contexts from an argocd CLI config file (e.g. ~/.config/argocd/config)")
+	readOnlyFlag := flag.Bool("read-only", false, "Do not register tools that modify ArgoCD")
+	allowToolsFlag := flag.String("allow-tools", "", "Comma-separated tools or toolsets to register; all others are left out (overrides allowTools in the config file)")
+	denyToolsFlag := flag.String("deny-tools", "", "Comma-separated tools or toolsets to leave out (overrides denyTools in the config file)")
+	confirmFlag := flag.Bool("confirm-destructive", false, "Require deletes and pruning syncs to be confirmed: the first call returns a plan and a token that must be passed back")
+	confirmTTLFlag := flag.Duration("confirm-ttl", tools.DefaultConfirmTTL, "How long a confirmation token stays valid")
+	maxResponseBytesFlag := flag.Int("max-response-bytes", tools.DefaultMaxResponseBytes, "Size budget in bytes of list and large-output tools when a call does not pass max_bytes")
+	elicitFlag := flag.Bool("elicit-destructive", false, "Ask the user to approve cascading deletes and pruning syncs through MCP elicitation")
+	elicitationFallbackFlag := flag.String("elicitation-fallback", "", "What to do when the client does not support elicitation: deny (default) or allow (overrides elicitationFallback in the config file)")
+	auditLogFlag := flag.String("audit-log", "", "Write an audit record of every mutating tool call to this JSONL file, or to stderr with '-' (overrides audit.file in the config file)")
+	auditMaxSizeFlag := flag.Int("audit-max-size-mb", 0, "Rotate the audit log once it reaches this size in megabytes (default 100)")
+	auditMaxBackupsFlag := flag.Int("audit-max-backups", 0, "Number of rotated audit logs to keep (default 5)")
+	policyFileFlag := flag.String("policy-file", "", "Path to a YAML rules file evaluated before every mutating tool call (overrides policyFile in the config file)")
+	metricsFlag := flag.Bool("metrics", false, "Serve Prometheus metrics on /metrics of the sse and http transports")
+	metricsListenFlag := flag.String("metrics-listen", "", "Serve Prometheus metrics on /metrics of a separate listen address, e.g. :9090 (works with every transport)")
+	traceExporterFlag := flag.String("trace-exporter", "", "Export OpenTelemetry spans: none (default), otlp, otlp-http or file (overrides tracing.exporter in the config file)")
+	traceEndpointFlag := flag.String("trace-endpoint", "", "OTLP collector address, e.g. localhost:4317 (defaults to OTEL_EXPORTER_OTLP_ENDPOINT)")
+	traceInsecureFlag := flag.Bool("trace-insecure", false, "Connect to the OTLP collector without TLS")
+	traceFileFlag := flag.String("trace-file", "", "File the file trace exporter writes spans to, or '-' for stderr")
+	contextOverrides := config.BindFlags(flag.CommandLine)
 	flag.Parse()
 
 	// Check for version flag
@@ -36,36 +74,264 @@ func main() {
 
 	log := logging.GetLogger()
 
+	transport, err := server.ParseTransport(*transportFlag)
+	if err != nil {
+		log.WithError(err).Fatal("Invalid transport")
+	}
+
 	// Log startup
 	log.WithFields(logrus.Fields{
-		"version": version,
-		"commit":  commit,
-		"date":    date,
-		"pid":     os.Getpid(),
+		"version":   version,
+		"commit":    commit,
+		"date":      date,
+		"pid":       os.Getpid(),
+		"transport": transport,
 	}).Info("Starting ArgoCD MCP Server")
 
-	// Check environment variables
-	if os.Getenv("ARGOCD_AUTH_TOKEN") == "" {
-		log.Fatal("ARGOCD_AUTH_TOKEN environment variable is not set. Please set it using: export ARGOCD_AUTH_TOKEN=$(argocd account generate-token)")
+	// Resolve the ArgoCD contexts from the config file, environment and flags.
+	// The selected context comes first and becomes the default instance.
+	cfg, err := config.ResolveConfig(config.Options{
+		File:         *configFlag,
+		Context:      *contextFlag,
+		ArgoCDConfig: *argocdConfigFlag,
+		Flags:        contextOverrides,
+	})
+	if err != nil {
+		log.WithError(err).Fatal("Failed to load configuration")
+	}
+	contexts := cfg.Contexts
+	argocdContext := contexts[0]
+	clientConfig, err := argocdContext.ClientConfig()
+	if err != nil {
+		log.WithError(err).Fatal("Failed to load configuration")
+	}
+	if clientConfig.AuthToken == "" {
+		log.Fatal("No ArgoCD auth token configured. Set one in the config file, or use: export ARGOCD_AUTH_TOKEN=$(argocd account generate-token)")
 	}
-	if os.Getenv("ARGOCD_SERVER") == "" {
-		log.Fatal("ARGOCD_SERVER environment variable is not set. Please set it using: export ARGOCD_SERVER=your-argocd-server.com")
+	if clientConfig.ServerAddr == "" {
+		log.Fatal("No ArgoCD server configured. Set one in the config file, pass --server, or use: export ARGOCD_SERVER=your-argocd-server.com")
 	}
 
-	log.WithField("server", os.Getenv("ARGOCD_SERVER")).Debug("ArgoCD server configured")
+	log.WithFields(logrus.Fields{
+		"context": argocdContext.Name,
+		"server":  clientConfig.ServerAddr,
+	}).Debug("ArgoCD server configured")
 
 	// 1. Create server instance
 	log.Debug("Creating MCP server instance")
 	s := server.New()
 
-	// 2. Register all tools with the server
-	log.Debug("Registering tools")
-	tools.RegisterAll(s)
+	// 2. Create the ArgoCD connections shared by all tool calls, one per context
+	clients := client.NewPool()
+	if err := clients.Add(argocdContext.Name, clientConfig); err != nil {
+		log.WithError(err).Fatal("Failed to register ArgoCD instance")
+	}
+	for _, other := range contexts[1:] {
+		otherConfig, err := other.ClientConfig()
+		if err == nil && (otherConfig.ServerAddr == "" || otherConfig.AuthToken == "") {
+			err = fmt.Errorf("server address and auth token are required")
+		}
+		if err == nil {
+			err = clients.Add(other.Name, otherConfig)
+		}
+		if err != nil {
+			log.WithError(err).WithField("context", other.Name).Warn("Skipping ArgoCD instance")
+			continue
+		}
+		log.WithFields(logrus.Fields{
+			"context": other.Name,
+			"server":  otherConfig.ServerAddr,
+		}).Debug("ArgoCD instance configured")
+	}
+
+	// 3. Register the enabled tools with the server
+	toolsConfig := tools.Config{
+		ReadOnly:   *readOnlyFlag || cfg.ReadOnly,
+		AllowTools: cfg.AllowTools,
+		DenyTools:  cfg.DenyTools,
+
+		ConfirmDestructive: *confirmFlag || cfg.ConfirmDestructive,
+		ConfirmTTL:         *confirmTTLFlag,
+
+		MaxResponseBytes: *maxResponseBytesFlag,
+	}
+	if *allowToolsFlag != "" {
+		toolsConfig.AllowTools = splitList(*allowToolsFlag)
+	}
+	if *denyToolsFlag != "" {
+		toolsConfig.DenyTools = splitList(*denyToolsFlag)
+	}
+	elicitationFallback := cfg.ElicitationFallback
+	if *elicitationFallbackFlag != "" {
+		elicitationFallback = *elicitationFallbackFlag
+	}
+	toolsConfig.ElicitDestructive = *elicitFlag || cfg.ElicitDestructive
+	toolsConfig.ElicitationFallback, err = tools.ParseElicitationFallback(elicitationFallback)
+	if err != nil {
+		log.WithError(err).Fatal("Invalid elicitation fallback")
+	}
+	if err := toolsConfig.Validate(); err != nil {
+		log.WithError(err).Fatal("Invalid tool selection")
+	}
+
+	var registryOptions []tools.Option
+	if *metricsFlag || *metricsListenFlag != "" {
+		registryOptions = append(registryOptions, tools.WithMiddleware(metrics.ToolMiddleware))
+	}
+	policyFile := cfg.PolicyFile
+	if *policyFileFlag != "" {
+		policyFile = *policyFileFlag
+	}
+	if policyFile != "" {
+		engine, err := policy.Load(policyFile)
+		if err != nil {
+			log.WithError(err).Fatal("Failed to load policy")
+		}
+		registryOptions = append(registryOptions, tools.WithPolicy(engine))
+		log.WithFields(logrus.Fields{
+			"file":  policyFile,
+			"rules": engine.Rules(),
+		}).Info("Policy loaded")
+	}
+
+	auditConfig := cfg.Audit
+	if *auditLogFlag != "" {
+		auditConfig.File = *auditLogFlag
+	}
+	if *auditMaxSizeFlag > 0 {
+		auditConfig.MaxSizeMB = *auditMaxSizeFlag
+	}
+	if *auditMaxBackupsFlag > 0 {
+		auditConfig.MaxBackups = *auditMaxBackupsFlag
+	}
+	auditLog, err := openAuditLog(auditConfig)
+	if err != nil {
+		log.WithError(err).Fatal("Failed to open audit log")
+	}
+	if auditLog != nil {
+		defer func() { _ = auditLog.Close() }()
+		registryOptions = append(registryOptions, tools.WithAudit(auditLog))
+		log.WithField("sink", auditConfig.File).Info("Audit log enabled")
+	}
+
+	tracingConfig := cfg.Tracing
+	if *traceExporterFlag != "" {
+		tracingConfig.Exporter = *traceExporterFlag
+	}
+	if *traceEndpointFlag != "" {
+		tracingConfig.Endpoint = *traceEndpointFlag
+	}
+	if *traceFileFlag != "" {
+		tracingConfig.File = *traceFileFlag
+	}
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		Exporter:       tracingConfig.Exporter,
+		Endpoint:       tracingConfig.Endpoint,
+		Insecure:       *traceInsecureFlag || tracingConfig.Insecure,
+		File:           tracingConfig.File,
+		ServiceVersion: version,
+	})
+	if err != nil {
+		log.WithError(err).Fatal("Failed to set up tracing")
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			log.WithError(err).Warn("Failed to flush spans")
+		}
+	}()
+	if tracingConfig.Exporter != "" && tracingConfig.Exporter != tracing.ExporterNone {
+		registryOptions = append(registryOptions, tools.WithTracing())
+		log.WithField("exporter", tracingConfig.Exporter).Info("Tracing enabled")
+	}
+
+	log.WithFields(logrus.Fields{
+		"readOnly":   toolsConfig.ReadOnly,
+		"allowTools": toolsConfig.AllowTools,
+		"denyTools":  toolsConfig.DenyTools,
+		"confirm":    toolsConfig.ConfirmDestructive,
+		"elicit":     toolsConfig.ElicitDestructive,
+	}).Debug("Registering tools")
+	registry := tools.NewRegistry(clients, toolsConfig, registryOptions...)
+	registry.Register(s.MCPServer)
+	s.SetSubscriber(registry)
+	s.SetCompleter(registry)
 	log.Info("All tools registered successfully")
 
-	// 3. Start server via stdio
-	log.Info("ArgoCD MCP Server started. Waiting for requests on stdin...")
-	if err := mcp_server.ServeStdio(s); err != nil {
+	// 4. Serve until interrupted, draining in-flight tool calls on shutdown
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if *metricsListenFlag != "" {
+		metricsServer := serveMetrics(*metricsListenFlag)
+		defer func() { _ = metricsServer.Close() }()
+	}
+
+	if transport == server.TransportStdio {
+		log.Info("ArgoCD MCP Server started. Waiting for requests on stdin...")
+	}
+	if err := s.Serve(ctx, server.ServeOptions{
+		Transport:       transport,
+		ListenAddr:      *listenFlag,
+		BaseURL:         *baseURLFlag,
+		ShutdownTimeout: *shutdownTimeoutFlag,
+		Metrics:         *metricsFlag,
+	}); err != nil {
+		_ = clients.Close()
 		log.WithError(err).Fatal("Server error")
 	}
+
+	// In-flight tool calls have drained, so the shared connections can be closed
+	if err := clients.Close(); err != nil {
+		log.WithError(err).Warn("Failed to close ArgoCD connections")
+	}
+	log.Info("ArgoCD MCP Server stopped")
+}
+
+// serveMetrics serves Prometheus metrics on addr in the background
+func serveMetrics(addr string) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle(metrics.Path, metrics.Handler())
+	metricsServer := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		if err := metricsServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logging.GetLogger().WithError(err).Error("Metrics server failed")
+		}
+	}()
+	logging.WithField("address", addr).Info("Serving Prometheus metrics")
+	return metricsServer
+}
+
+// openAuditLog opens the configured audit sink; it returns nil when auditing is disabled
+func openAuditLog(cfg config.Audit) (*audit.Logger, error) {
+	switch cfg.File {
+	case "":
+		return nil, nil
+	case "-", "stderr":
+		return audit.NewLogger(os.Stderr), nil
+	}
+
+	maxSizeMB := cfg.MaxSizeMB
+	if maxSizeMB <= 0 {
+		maxSizeMB = 100
+	}
+	maxBackups := cfg.MaxBackups
+	if maxBackups <= 0 {
+		maxBackups = 5
+	}
+	return audit.Open(cfg.File, int64(maxSizeMB)<<20, maxBackups)
+}
+
+// splitList splits a comma-separated flag value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
 }
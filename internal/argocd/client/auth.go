@@ -2,6 +2,7 @@ package client
 
 import (
 	"context"
+	"strings"
 
 	"google.golang.org/grpc/credentials"
 )
@@ -23,3 +24,31 @@ func (c *jwtCredentials) GetRequestMetadata(ctx context.Context, uri ...string)
 func (c *jwtCredentials) RequireTransportSecurity() bool {
 	return false
 }
+
+// headerCredentials sends the configured headers as gRPC metadata of every RPC,
+// as the gRPC-Web proxy sends them as HTTP headers
+type headerCredentials struct {
+	metadata map[string]string
+}
+
+// newHeaderCredentials parses headers of the form "Name: value"; malformed ones are skipped
+func newHeaderCredentials(headers []string) credentials.PerRPCCredentials {
+	md := make(map[string]string, len(headers))
+	for _, h := range headers {
+		name, value, ok := strings.Cut(h, ":")
+		if !ok {
+			continue
+		}
+		// gRPC metadata keys are lowercase
+		md[strings.ToLower(strings.TrimSpace(name))] = strings.TrimSpace(value)
+	}
+	return &headerCredentials{metadata: md}
+}
+
+func (c *headerCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return c.metadata, nil
+}
+
+func (c *headerCredentials) RequireTransportSecurity() bool {
+	return false
+}
@@ -2,7 +2,6 @@ package client
 
 import (
 	"context"
-	"crypto/tls"
 	"fmt"
 	"io"
 	"math"
@@ -10,6 +9,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"sync/atomic"
 
 	applicationpkg "github.com/argoproj/argo-cd/v2/pkg/apiclient/application"
 	applicationsetpkg "github.com/argoproj/argo-cd/v2/pkg/apiclient/applicationset"
@@ -17,11 +17,16 @@ import (
 	projectpkg "github.com/argoproj/argo-cd/v2/pkg/apiclient/project"
 	repositorypkg "github.com/argoproj/argo-cd/v2/pkg/apiclient/repository"
 	sessionpkg "github.com/argoproj/argo-cd/v2/pkg/apiclient/session"
+	versionpkg "github.com/argoproj/argo-cd/v2/pkg/apiclient/version"
 	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
 	"github.com/toyamagu-2021/argocd-mcp-server/internal/grpcwebproxy"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/metrics"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/emptypb"
 )
 
 // MaxGRPCMessageSize contains max grpc message size
@@ -66,6 +71,10 @@ type Client struct {
 	projectClient projectpkg.ProjectServiceClient
 	repoClient    repositorypkg.RepositoryServiceClient
 	sessionClient sessionpkg.SessionServiceClient
+	versionClient versionpkg.VersionServiceClient
+
+	// closed is set by Close, which ends the watches of the client
+	closed atomic.Bool
 }
 
 // New creates a new ArgoCD gRPC client with the provided configuration
@@ -74,9 +83,13 @@ func New(config *Config) (*Client, error) {
 		return nil, err
 	}
 
+	httpClient, err := config.NewHTTPClient()
+	if err != nil {
+		return nil, err
+	}
 	client := &Client{
 		config:     config,
-		httpClient: config.NewHTTPClient(),
+		httpClient: httpClient,
 	}
 
 	if err := client.connect(); err != nil {
@@ -118,27 +131,19 @@ func (c *Client) connect() error {
 		// Force Unix socket connection to be insecure
 		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	} else {
+		// The proxy sends the headers over HTTP; a direct connection sends them as metadata
+		if len(c.config.Headers) > 0 {
+			opts = append(opts, grpc.WithPerRPCCredentials(newHeaderCredentials(c.config.Headers)))
+		}
+
 		// Configure TLS for direct gRPC connection
 		if c.config.PlainText {
 			opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
 		} else {
-			var tlsConfig *tls.Config
-			if c.config.Insecure {
-				tlsConfig = &tls.Config{
-					InsecureSkipVerify: true,
-				}
-			} else {
-				tlsConfig = &tls.Config{}
+			tlsConfig, err := c.config.tlsConfig()
+			if err != nil {
+				return err
 			}
-
-			if c.config.ClientCertFile != "" && c.config.ClientCertKeyFile != "" {
-				cert, err := tls.LoadX509KeyPair(c.config.ClientCertFile, c.config.ClientCertKeyFile)
-				if err != nil {
-					return fmt.Errorf("failed to load client certificates: %w", err)
-				}
-				tlsConfig.Certificates = []tls.Certificate{cert}
-			}
-
 			opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
 		}
 	}
@@ -148,6 +153,15 @@ func (c *Client) connect() error {
 		opts = append(opts, grpc.WithUserAgent(c.config.UserAgent))
 	}
 
+	// Record the latency and status code of every RPC
+	opts = append(opts,
+		grpc.WithChainUnaryInterceptor(metrics.UnaryClientInterceptor()),
+		grpc.WithChainStreamInterceptor(metrics.StreamClientInterceptor()),
+	)
+
+	// Trace every RPC and propagate the trace context to ArgoCD as gRPC metadata
+	opts = append(opts, grpc.WithStatsHandler(otelgrpc.NewClientHandler()))
+
 	// Configure gRPC message size limits
 	opts = append(opts, grpc.WithDefaultCallOptions(
 		grpc.MaxCallRecvMsgSize(MaxGRPCMessageSize),
@@ -169,6 +183,7 @@ func (c *Client) connect() error {
 	c.projectClient = projectpkg.NewProjectServiceClient(conn)
 	c.repoClient = repositorypkg.NewRepositoryServiceClient(conn)
 	c.sessionClient = sessionpkg.NewSessionServiceClient(conn)
+	c.versionClient = versionpkg.NewVersionServiceClient(conn)
 
 	return nil
 }
@@ -176,6 +191,7 @@ func (c *Client) connect() error {
 // Close closes the gRPC connection and any associated resources
 func (c *Client) Close() error {
 	var err error
+	c.closed.Store(true)
 
 	// Close gRPC connection
 	if c.conn != nil {
@@ -194,6 +210,14 @@ func (c *Client) Close() error {
 	return err
 }
 
+// connState reports the state of the underlying gRPC connection
+func (c *Client) connState() connectivity.State {
+	if c.conn == nil {
+		return connectivity.Shutdown
+	}
+	return c.conn.GetState()
+}
+
 // Application operations
 
 // GetApplication retrieves a single ArgoCD application by name
@@ -261,6 +285,21 @@ func (c *Client) UpdateApplication(ctx context.Context, app *v1alpha1.Applicatio
 	return resp, nil
 }
 
+// PatchApplication applies a JSON ("json") or merge ("merge") patch to the
+// whole of an ArgoCD application, as the server reads it when patching
+func (c *Client) PatchApplication(ctx context.Context, name string, patch string, patchType string) (*v1alpha1.Application, error) {
+	req := &applicationpkg.ApplicationPatchRequest{
+		Name:      &name,
+		Patch:     &patch,
+		PatchType: &patchType,
+	}
+	resp, err := c.appClient.Patch(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to patch application: %w", err)
+	}
+	return resp, nil
+}
+
 // DeleteApplication deletes an ArgoCD application
 func (c *Client) DeleteApplication(ctx context.Context, name string, cascade bool) error {
 	req := &applicationpkg.ApplicationDeleteRequest{
@@ -275,14 +314,26 @@ func (c *Client) DeleteApplication(ctx context.Context, name string, cascade boo
 }
 
 // SyncApplication triggers a sync operation for an ArgoCD application
-func (c *Client) SyncApplication(ctx context.Context, name string, revision string, prune bool, dryRun bool) (*v1alpha1.Application, error) {
-	strategy := &v1alpha1.SyncStrategy{}
+func (c *Client) SyncApplication(ctx context.Context, name string, sync SyncRequest) (*v1alpha1.Application, error) {
+	strategy := sync.Strategy
+	if strategy == nil {
+		strategy = &v1alpha1.SyncStrategy{}
+	}
 	req := &applicationpkg.ApplicationSyncRequest{
-		Name:     &name,
-		Revision: &revision,
-		Prune:    &prune,
-		DryRun:   &dryRun,
-		Strategy: strategy,
+		Name:            &name,
+		Revision:        &sync.Revision,
+		Revisions:       sync.Revisions,
+		SourcePositions: sync.SourcePositions,
+		Prune:           &sync.Prune,
+		DryRun:          &sync.DryRun,
+		Strategy:        strategy,
+		RetryStrategy:   sync.Retry,
+	}
+	for i := range sync.Resources {
+		req.Resources = append(req.Resources, &sync.Resources[i])
+	}
+	if len(sync.SyncOptions) > 0 {
+		req.SyncOptions = &applicationpkg.SyncOptions{Items: sync.SyncOptions}
 	}
 	resp, err := c.appClient.Sync(ctx, req)
 	if err != nil {
@@ -384,6 +435,44 @@ func (c *Client) RollbackApplication(ctx context.Context, name string, id int64)
 	return resp, nil
 }
 
+// GetRevisionMetadata retrieves the author, date, tags and message of a revision of an ArgoCD application.
+// sourceIndex and versionID select the source of a multi-source application and may be nil.
+func (c *Client) GetRevisionMetadata(ctx context.Context, name string, revision string, sourceIndex *int32, versionID *int32) (*v1alpha1.RevisionMetadata, error) {
+	req := &applicationpkg.RevisionMetadataQuery{
+		Name:        &name,
+		Revision:    &revision,
+		SourceIndex: sourceIndex,
+		VersionId:   versionID,
+	}
+	resp, err := c.appClient.RevisionMetadata(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get revision metadata: %w", err)
+	}
+	return resp, nil
+}
+
+// GetManagedResources retrieves the live and target states of the resources managed by an ArgoCD application.
+// Empty kind, resourceNamespace and resourceName match every resource.
+func (c *Client) GetManagedResources(ctx context.Context, name string, kind string, resourceNamespace string, resourceName string) ([]*v1alpha1.ResourceDiff, error) {
+	req := &applicationpkg.ResourcesQuery{
+		ApplicationName: &name,
+	}
+	if kind != "" {
+		req.Kind = &kind
+	}
+	if resourceNamespace != "" {
+		req.Namespace = &resourceNamespace
+	}
+	if resourceName != "" {
+		req.Name = &resourceName
+	}
+	resp, err := c.appClient.ManagedResources(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get managed resources: %w", err)
+	}
+	return resp.Items, nil
+}
+
 // GetApplicationResourceTree retrieves the resource tree structure of an ArgoCD application
 func (c *Client) GetApplicationResourceTree(ctx context.Context, name string, appNamespace string, project string) (*v1alpha1.ApplicationTree, error) {
 	// If appNamespace or project not provided, get them from the application
@@ -709,9 +798,10 @@ func (c *Client) ListApplicationSets(ctx context.Context, project string) (*v1al
 }
 
 // GetApplicationSet retrieves an ArgoCD ApplicationSet by name
-func (c *Client) GetApplicationSet(ctx context.Context, name string) (*v1alpha1.ApplicationSet, error) {
+func (c *Client) GetApplicationSet(ctx context.Context, name string, appsetNamespace string) (*v1alpha1.ApplicationSet, error) {
 	req := &applicationsetpkg.ApplicationSetGetQuery{
-		Name: name,
+		Name:            name,
+		AppsetNamespace: appsetNamespace,
 	}
 	resp, err := c.appSetClient.Get(ctx, req)
 	if err != nil {
@@ -756,3 +846,12 @@ func (c *Client) GetUserInfo(ctx context.Context) (*sessionpkg.GetUserInfoRespon
 	}
 	return resp, nil
 }
+
+// GetVersion gets the version information of the ArgoCD server
+func (c *Client) GetVersion(ctx context.Context) (*versionpkg.VersionMessage, error) {
+	resp, err := c.versionClient.Version(ctx, &emptypb.Empty{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get version: %w", err)
+	}
+	return resp, nil
+}
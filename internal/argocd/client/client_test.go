@@ -2,8 +2,16 @@ package client
 
 import (
 	"context"
+	"net"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 func TestConfig_Validate(t *testing.T) {
@@ -39,6 +47,15 @@ func TestConfig_Validate(t *testing.T) {
 			config:  Config{},
 			wantErr: true,
 		},
+		{
+			name: "client certificate without key",
+			config: Config{
+				ServerAddr:     "localhost:60080",
+				AuthToken:      "test-token",
+				ClientCertFile: "client.crt",
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -73,10 +90,72 @@ func TestJWTCredentials_RequireTransportSecurity(t *testing.T) {
 	}
 }
 
+func TestHeaderCredentials_GetRequestMetadata(t *testing.T) {
+	creds := newHeaderCredentials([]string{"X-Team: platform", "X-Trace-Sampled:1", "malformed"})
+
+	md, err := creds.GetRequestMetadata(context.Background())
+	if err != nil {
+		t.Fatalf("GetRequestMetadata() error = %v", err)
+	}
+
+	expected := map[string]string{"x-team": "platform", "x-trace-sampled": "1"}
+	if len(md) != len(expected) {
+		t.Fatalf("GetRequestMetadata() = %v, want %v", md, expected)
+	}
+	for k, v := range expected {
+		if md[k] != v {
+			t.Errorf("GetRequestMetadata()[%q] = %q, want %q", k, md[k], v)
+		}
+	}
+}
+
+func TestClient_SendsHeadersAsMetadata(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	received := make(chan metadata.MD, 1)
+	srv := grpc.NewServer(grpc.UnknownServiceHandler(func(_ any, stream grpc.ServerStream) error {
+		md, _ := metadata.FromIncomingContext(stream.Context())
+		received <- md
+		return status.Error(codes.Unimplemented, "not served")
+	}))
+	go func() { _ = srv.Serve(lis) }()
+	defer srv.Stop()
+
+	c, err := New(&Config{
+		ServerAddr: lis.Addr().String(),
+		AuthToken:  "test-token",
+		PlainText:  true,
+		Headers:    []string{"X-Team: platform"},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer func() { _ = c.Close() }()
+
+	if _, err := c.GetVersion(context.Background()); status.Code(err) != codes.Unimplemented {
+		t.Fatalf("GetVersion() error = %v, want Unimplemented", err)
+	}
+	md := <-received
+	if got := md.Get("x-team"); len(got) != 1 || got[0] != "platform" {
+		t.Errorf("metadata x-team = %v, want [platform]", got)
+	}
+	if got := md.Get("authorization"); len(got) != 1 || got[0] != "Bearer test-token" {
+		t.Errorf("metadata authorization = %v, want [Bearer test-token]", got)
+	}
+}
+
 func TestConfig_NewHTTPClient(t *testing.T) {
+	invalidCert := filepath.Join(t.TempDir(), "invalid.crt")
+	if err := os.WriteFile(invalidCert, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
 	tests := []struct {
-		name   string
-		config Config
+		name    string
+		config  Config
+		wantErr bool
 	}{
 		{
 			name: "default timeout",
@@ -101,11 +180,45 @@ func TestConfig_NewHTTPClient(t *testing.T) {
 				Insecure:   true,
 			},
 		},
+		{
+			name: "missing server certificate",
+			config: Config{
+				ServerAddr: "localhost:60080",
+				AuthToken:  "test-token",
+				CertFile:   filepath.Join(t.TempDir(), "missing.crt"),
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid server certificate",
+			config: Config{
+				ServerAddr: "localhost:60080",
+				AuthToken:  "test-token",
+				CertFile:   invalidCert,
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid client certificate",
+			config: Config{
+				ServerAddr:        "localhost:60080",
+				AuthToken:         "test-token",
+				ClientCertFile:    invalidCert,
+				ClientCertKeyFile: invalidCert,
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			client := tt.config.NewHTTPClient()
+			client, err := tt.config.NewHTTPClient()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewHTTPClient() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
 			if client == nil {
 				t.Error("NewHTTPClient() returned nil")
 				return
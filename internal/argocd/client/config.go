@@ -2,7 +2,10 @@ package client
 
 import (
 	"crypto/tls"
+	"crypto/x509"
+	"fmt"
 	"net/http"
+	"os"
 	"time"
 )
 
@@ -23,16 +26,10 @@ type Config struct {
 }
 
 // NewHTTPClient creates a new HTTP client with TLS configuration for gRPC-Web proxy
-func (c *Config) NewHTTPClient() *http.Client {
-	tlsConfig := &tls.Config{
-		InsecureSkipVerify: c.Insecure,
-	}
-
-	if c.ClientCertFile != "" && c.ClientCertKeyFile != "" {
-		cert, err := tls.LoadX509KeyPair(c.ClientCertFile, c.ClientCertKeyFile)
-		if err == nil {
-			tlsConfig.Certificates = []tls.Certificate{cert}
-		}
+func (c *Config) NewHTTPClient() (*http.Client, error) {
+	tlsConfig, err := c.tlsConfig()
+	if err != nil {
+		return nil, err
 	}
 
 	timeout := c.Timeout
@@ -45,7 +42,32 @@ func (c *Config) NewHTTPClient() *http.Client {
 		Transport: &http.Transport{
 			TLSClientConfig: tlsConfig,
 		},
+	}, nil
+}
+
+// tlsConfig returns the TLS configuration towards the ArgoCD server, with the
+// CA certificates and client certificate of the configuration
+func (c *Config) tlsConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: c.Insecure,
+	}
+
+	if c.CertFile != "" {
+		pool, err := loadCertPool(c.CertFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if c.ClientCertFile != "" && c.ClientCertKeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.ClientCertFile, c.ClientCertKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificates: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
 	}
+	return tlsConfig, nil
 }
 
 // Validate checks that required configuration parameters are present
@@ -56,5 +78,21 @@ func (c *Config) Validate() error {
 	if c.AuthToken == "" {
 		return ErrAuthTokenRequired
 	}
+	if (c.ClientCertFile == "") != (c.ClientCertKeyFile == "") {
+		return ErrClientCertIncomplete
+	}
 	return nil
 }
+
+// loadCertPool reads PEM encoded CA certificates used to verify the ArgoCD server
+func loadCertPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read server certificate: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no valid certificates found in %s", path)
+	}
+	return pool, nil
+}
@@ -7,8 +7,14 @@ var (
 	ErrServerAddrRequired = errors.New("server address is required")
 	// ErrAuthTokenRequired is returned when no authentication token is provided in configuration
 	ErrAuthTokenRequired = errors.New("auth token is required")
+	// ErrClientCertIncomplete is returned when only one of the client certificate and its key is configured
+	ErrClientCertIncomplete = errors.New("client certificate and client certificate key must be set together")
 	// ErrConnectionFailed is returned when unable to establish connection to ArgoCD server
 	ErrConnectionFailed = errors.New("failed to connect to ArgoCD server")
 	// ErrNotImplemented is returned when a feature is not yet implemented
 	ErrNotImplemented = errors.New("feature not implemented")
+	// ErrManagerClosed is returned when a client is requested from a closed Manager
+	ErrManagerClosed = errors.New("client manager is closed")
+	// ErrUnknownInstance is returned when a client is requested for an instance that is not configured
+	ErrUnknownInstance = errors.New("unknown ArgoCD instance")
 )
@@ -5,6 +5,7 @@ import (
 
 	applicationpkg "github.com/argoproj/argo-cd/v2/pkg/apiclient/application"
 	sessionpkg "github.com/argoproj/argo-cd/v2/pkg/apiclient/session"
+	versionpkg "github.com/argoproj/argo-cd/v2/pkg/apiclient/version"
 	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
 )
 
@@ -15,6 +16,25 @@ type LogStream interface {
 	Recv() (*applicationpkg.LogEntry, error)
 }
 
+// SyncRequest describes a sync operation; the zero value syncs every resource
+// of an application to its target revision
+type SyncRequest struct {
+	// Revision overrides the target revision of a single-source application
+	Revision string
+	// Revisions override the target revisions of the sources of a multi-source
+	// application at SourcePositions, which count from 1
+	Revisions       []string
+	SourcePositions []int64
+	Prune           bool
+	DryRun          bool
+	// Resources limits the sync to these resources
+	Resources   []v1alpha1.SyncOperationResource
+	SyncOptions []string
+	// Strategy defaults to the hook strategy
+	Strategy *v1alpha1.SyncStrategy
+	Retry    *v1alpha1.RetryStrategy
+}
+
 // Interface defines the contract for ArgoCD client operations
 type Interface interface {
 	// Application operations
@@ -22,15 +42,19 @@ type Interface interface {
 	ListApplications(ctx context.Context, selector string) (*v1alpha1.ApplicationList, error)
 	CreateApplication(ctx context.Context, app *v1alpha1.Application, upsert bool) (*v1alpha1.Application, error)
 	UpdateApplication(ctx context.Context, app *v1alpha1.Application) (*v1alpha1.Application, error)
+	PatchApplication(ctx context.Context, name string, patch string, patchType string) (*v1alpha1.Application, error)
 	DeleteApplication(ctx context.Context, name string, cascade bool) error
-	SyncApplication(ctx context.Context, name string, revision string, prune bool, dryRun bool) (*v1alpha1.Application, error)
+	SyncApplication(ctx context.Context, name string, sync SyncRequest) (*v1alpha1.Application, error)
 	RollbackApplication(ctx context.Context, name string, id int64) (*v1alpha1.Application, error)
+	GetRevisionMetadata(ctx context.Context, name string, revision string, sourceIndex *int32, versionID *int32) (*v1alpha1.RevisionMetadata, error)
 	RefreshApplication(ctx context.Context, name string, refreshType string) (*v1alpha1.Application, error)
 	GetApplicationManifests(ctx context.Context, name string, revision string) (interface{}, error)
 	GetApplicationEvents(ctx context.Context, name string, resourceNamespace string, resourceName string, resourceUID string, appNamespace string, project string) (interface{}, error)
 	GetApplicationLogs(ctx context.Context, name string, podName string, container string, namespace string, resourceName string, kind string, group string, tailLines int64, sinceSeconds *int64, follow bool, previous bool, filter string, appNamespace string, project string) (LogStream, error)
 	GetApplicationResourceTree(ctx context.Context, name string, appNamespace string, project string) (*v1alpha1.ApplicationTree, error)
+	GetManagedResources(ctx context.Context, name string, kind string, resourceNamespace string, resourceName string) ([]*v1alpha1.ResourceDiff, error)
 	TerminateOperation(ctx context.Context, name string, appNamespace string, project string) error
+	WatchApplications(ctx context.Context, name string, resourceVersion string) (<-chan *v1alpha1.ApplicationWatchEvent, error)
 
 	// Cluster operations
 	ListClusters(ctx context.Context) (*v1alpha1.ClusterList, error)
@@ -55,13 +79,16 @@ type Interface interface {
 
 	// ApplicationSet operations
 	ListApplicationSets(ctx context.Context, project string) (*v1alpha1.ApplicationSetList, error)
-	GetApplicationSet(ctx context.Context, name string) (*v1alpha1.ApplicationSet, error)
+	GetApplicationSet(ctx context.Context, name string, appsetNamespace string) (*v1alpha1.ApplicationSet, error)
 	CreateApplicationSet(ctx context.Context, appSet *v1alpha1.ApplicationSet, upsert bool, dryRun bool) (*v1alpha1.ApplicationSet, error)
 	DeleteApplicationSet(ctx context.Context, name string, appsetNamespace string) error
 
 	// Session operations
 	GetUserInfo(ctx context.Context) (*sessionpkg.GetUserInfoResponse, error)
 
+	// Version operations
+	GetVersion(ctx context.Context) (*versionpkg.VersionMessage, error)
+
 	// Connection management
 	Close() error
 }
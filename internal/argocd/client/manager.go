@@ -0,0 +1,109 @@
+package client
+
+import (
+	"context"
+	"sync"
+
+	"google.golang.org/grpc/connectivity"
+)
+
+// connStater is implemented by clients that can report the state of their connection
+type connStater interface {
+	connState() connectivity.State
+}
+
+// Manager owns a single long-lived ArgoCD client and shares it between callers.
+// The connection is established lazily on first use and re-established when it
+// breaks, so tool calls don't pay for a new TLS handshake or gRPC-Web proxy each time.
+type Manager struct {
+	config    *Config
+	newClient func(*Config) (Interface, error)
+
+	mu     sync.Mutex
+	client Interface
+	closed bool
+}
+
+// NewManager creates a Manager that connects using the provided configuration
+func NewManager(config *Config) *Manager {
+	return &Manager{
+		config: config,
+		newClient: func(config *Config) (Interface, error) {
+			return New(config)
+		},
+	}
+}
+
+// Client returns the shared client, connecting or reconnecting as needed.
+// The returned client is safe for concurrent use; calling Close on it is a no-op
+// because the connection is owned by the Manager.
+func (m *Manager) Client(ctx context.Context) (Interface, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return nil, ErrManagerClosed
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if m.client != nil && !isBroken(m.client) {
+		return sharedClient{m.client}, nil
+	}
+
+	if m.client != nil {
+		_ = m.client.Close()
+		m.client = nil
+	}
+
+	c, err := m.newClient(m.config)
+	if err != nil {
+		return nil, err
+	}
+	m.client = c
+
+	return sharedClient{m.client}, nil
+}
+
+// Close closes the shared connection. Subsequent calls to Client fail with ErrManagerClosed.
+func (m *Manager) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return nil
+	}
+	m.closed = true
+
+	if m.client == nil {
+		return nil
+	}
+	err := m.client.Close()
+	m.client = nil
+	return err
+}
+
+// isBroken reports whether the client's connection can no longer serve requests
+func isBroken(c Interface) bool {
+	stater, ok := c.(connStater)
+	if !ok {
+		return false
+	}
+	switch stater.connState() {
+	case connectivity.TransientFailure, connectivity.Shutdown:
+		return true
+	default:
+		return false
+	}
+}
+
+// sharedClient wraps a managed client so that callers cannot close the shared connection
+type sharedClient struct {
+	Interface
+}
+
+// Close is a no-op; the connection is closed by the owning Manager
+func (sharedClient) Close() error {
+	return nil
+}
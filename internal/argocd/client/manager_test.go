@@ -0,0 +1,112 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"google.golang.org/grpc/connectivity"
+)
+
+// fakeConn is a minimal Interface implementation with a controllable connection state
+type fakeConn struct {
+	Interface
+	state  connectivity.State
+	closed bool
+}
+
+func (f *fakeConn) Close() error {
+	f.closed = true
+	return nil
+}
+
+func (f *fakeConn) connState() connectivity.State {
+	return f.state
+}
+
+func newTestManager(conns *[]*fakeConn) *Manager {
+	m := NewManager(&Config{ServerAddr: "localhost:60080", AuthToken: "test-token"})
+	m.newClient = func(*Config) (Interface, error) {
+		c := &fakeConn{state: connectivity.Ready}
+		*conns = append(*conns, c)
+		return c, nil
+	}
+	return m
+}
+
+func TestManager_ReusesConnection(t *testing.T) {
+	var conns []*fakeConn
+	m := newTestManager(&conns)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c, err := m.Client(context.Background())
+			if err != nil {
+				t.Errorf("Client() error = %v", err)
+				return
+			}
+			// Closing a shared client must not close the managed connection
+			_ = c.Close()
+		}()
+	}
+	wg.Wait()
+
+	if len(conns) != 1 {
+		t.Fatalf("expected 1 connection, got %d", len(conns))
+	}
+	if conns[0].closed {
+		t.Error("shared connection was closed by a caller")
+	}
+}
+
+func TestManager_ReconnectsBrokenConnection(t *testing.T) {
+	var conns []*fakeConn
+	m := newTestManager(&conns)
+
+	if _, err := m.Client(context.Background()); err != nil {
+		t.Fatalf("Client() error = %v", err)
+	}
+	conns[0].state = connectivity.TransientFailure
+
+	if _, err := m.Client(context.Background()); err != nil {
+		t.Fatalf("Client() error = %v", err)
+	}
+
+	if len(conns) != 2 {
+		t.Fatalf("expected 2 connections, got %d", len(conns))
+	}
+	if !conns[0].closed {
+		t.Error("broken connection was not closed")
+	}
+}
+
+func TestManager_Close(t *testing.T) {
+	var conns []*fakeConn
+	m := newTestManager(&conns)
+
+	if _, err := m.Client(context.Background()); err != nil {
+		t.Fatalf("Client() error = %v", err)
+	}
+	if err := m.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if !conns[0].closed {
+		t.Error("connection was not closed")
+	}
+
+	if _, err := m.Client(context.Background()); !errors.Is(err, ErrManagerClosed) {
+		t.Errorf("Client() after Close error = %v, want %v", err, ErrManagerClosed)
+	}
+}
+
+func TestManager_InvalidConfig(t *testing.T) {
+	m := NewManager(&Config{})
+
+	if _, err := m.Client(context.Background()); !errors.Is(err, ErrServerAddrRequired) {
+		t.Errorf("Client() error = %v, want %v", err, ErrServerAddrRequired)
+	}
+}
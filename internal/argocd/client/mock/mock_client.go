@@ -15,6 +15,7 @@ import (
 
 	application "github.com/argoproj/argo-cd/v2/pkg/apiclient/application"
 	session "github.com/argoproj/argo-cd/v2/pkg/apiclient/session"
+	version "github.com/argoproj/argo-cd/v2/pkg/apiclient/version"
 	v1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
 	client "github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client"
 	gomock "go.uber.org/mock/gomock"
@@ -318,18 +319,18 @@ func (mr *MockInterfaceMockRecorder) GetApplicationResourceTree(ctx, name, appNa
 }
 
 // GetApplicationSet mocks base method.
-func (m *MockInterface) GetApplicationSet(ctx context.Context, name string) (*v1alpha1.ApplicationSet, error) {
+func (m *MockInterface) GetApplicationSet(ctx context.Context, name, appsetNamespace string) (*v1alpha1.ApplicationSet, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetApplicationSet", ctx, name)
+	ret := m.ctrl.Call(m, "GetApplicationSet", ctx, name, appsetNamespace)
 	ret0, _ := ret[0].(*v1alpha1.ApplicationSet)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetApplicationSet indicates an expected call of GetApplicationSet.
-func (mr *MockInterfaceMockRecorder) GetApplicationSet(ctx, name any) *gomock.Call {
+func (mr *MockInterfaceMockRecorder) GetApplicationSet(ctx, name, appsetNamespace any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetApplicationSet", reflect.TypeOf((*MockInterface)(nil).GetApplicationSet), ctx, name)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetApplicationSet", reflect.TypeOf((*MockInterface)(nil).GetApplicationSet), ctx, name, appsetNamespace)
 }
 
 // GetCluster mocks base method.
@@ -347,6 +348,21 @@ func (mr *MockInterfaceMockRecorder) GetCluster(ctx, server any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCluster", reflect.TypeOf((*MockInterface)(nil).GetCluster), ctx, server)
 }
 
+// GetManagedResources mocks base method.
+func (m *MockInterface) GetManagedResources(ctx context.Context, name, kind, resourceNamespace, resourceName string) ([]*v1alpha1.ResourceDiff, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetManagedResources", ctx, name, kind, resourceNamespace, resourceName)
+	ret0, _ := ret[0].([]*v1alpha1.ResourceDiff)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetManagedResources indicates an expected call of GetManagedResources.
+func (mr *MockInterfaceMockRecorder) GetManagedResources(ctx, name, kind, resourceNamespace, resourceName any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetManagedResources", reflect.TypeOf((*MockInterface)(nil).GetManagedResources), ctx, name, kind, resourceNamespace, resourceName)
+}
+
 // GetProject mocks base method.
 func (m *MockInterface) GetProject(ctx context.Context, name string) (*v1alpha1.AppProject, error) {
 	m.ctrl.T.Helper()
@@ -377,6 +393,21 @@ func (mr *MockInterfaceMockRecorder) GetRepository(ctx, repo any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRepository", reflect.TypeOf((*MockInterface)(nil).GetRepository), ctx, repo)
 }
 
+// GetRevisionMetadata mocks base method.
+func (m *MockInterface) GetRevisionMetadata(ctx context.Context, name, revision string, sourceIndex, versionID *int32) (*v1alpha1.RevisionMetadata, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRevisionMetadata", ctx, name, revision, sourceIndex, versionID)
+	ret0, _ := ret[0].(*v1alpha1.RevisionMetadata)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRevisionMetadata indicates an expected call of GetRevisionMetadata.
+func (mr *MockInterfaceMockRecorder) GetRevisionMetadata(ctx, name, revision, sourceIndex, versionID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRevisionMetadata", reflect.TypeOf((*MockInterface)(nil).GetRevisionMetadata), ctx, name, revision, sourceIndex, versionID)
+}
+
 // GetUserInfo mocks base method.
 func (m *MockInterface) GetUserInfo(ctx context.Context) (*session.GetUserInfoResponse, error) {
 	m.ctrl.T.Helper()
@@ -392,6 +423,21 @@ func (mr *MockInterfaceMockRecorder) GetUserInfo(ctx any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserInfo", reflect.TypeOf((*MockInterface)(nil).GetUserInfo), ctx)
 }
 
+// GetVersion mocks base method.
+func (m *MockInterface) GetVersion(ctx context.Context) (*version.VersionMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVersion", ctx)
+	ret0, _ := ret[0].(*version.VersionMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVersion indicates an expected call of GetVersion.
+func (mr *MockInterfaceMockRecorder) GetVersion(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVersion", reflect.TypeOf((*MockInterface)(nil).GetVersion), ctx)
+}
+
 // ListApplicationSets mocks base method.
 func (m *MockInterface) ListApplicationSets(ctx context.Context, project string) (*v1alpha1.ApplicationSetList, error) {
 	m.ctrl.T.Helper()
@@ -467,6 +513,21 @@ func (mr *MockInterfaceMockRecorder) ListRepositories(ctx any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRepositories", reflect.TypeOf((*MockInterface)(nil).ListRepositories), ctx)
 }
 
+// PatchApplication mocks base method.
+func (m *MockInterface) PatchApplication(ctx context.Context, name, patch, patchType string) (*v1alpha1.Application, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchApplication", ctx, name, patch, patchType)
+	ret0, _ := ret[0].(*v1alpha1.Application)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PatchApplication indicates an expected call of PatchApplication.
+func (mr *MockInterfaceMockRecorder) PatchApplication(ctx, name, patch, patchType any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchApplication", reflect.TypeOf((*MockInterface)(nil).PatchApplication), ctx, name, patch, patchType)
+}
+
 // RefreshApplication mocks base method.
 func (m *MockInterface) RefreshApplication(ctx context.Context, name, refreshType string) (*v1alpha1.Application, error) {
 	m.ctrl.T.Helper()
@@ -498,18 +559,18 @@ func (mr *MockInterfaceMockRecorder) RollbackApplication(ctx, name, id any) *gom
 }
 
 // SyncApplication mocks base method.
-func (m *MockInterface) SyncApplication(ctx context.Context, name, revision string, prune, dryRun bool) (*v1alpha1.Application, error) {
+func (m *MockInterface) SyncApplication(ctx context.Context, name string, sync client.SyncRequest) (*v1alpha1.Application, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SyncApplication", ctx, name, revision, prune, dryRun)
+	ret := m.ctrl.Call(m, "SyncApplication", ctx, name, sync)
 	ret0, _ := ret[0].(*v1alpha1.Application)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // SyncApplication indicates an expected call of SyncApplication.
-func (mr *MockInterfaceMockRecorder) SyncApplication(ctx, name, revision, prune, dryRun any) *gomock.Call {
+func (mr *MockInterfaceMockRecorder) SyncApplication(ctx, name, sync any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncApplication", reflect.TypeOf((*MockInterface)(nil).SyncApplication), ctx, name, revision, prune, dryRun)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncApplication", reflect.TypeOf((*MockInterface)(nil).SyncApplication), ctx, name, sync)
 }
 
 // TerminateOperation mocks base method.
@@ -585,3 +646,18 @@ func (mr *MockInterfaceMockRecorder) UpdateRepository(ctx, repo any) *gomock.Cal
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRepository", reflect.TypeOf((*MockInterface)(nil).UpdateRepository), ctx, repo)
 }
+
+// WatchApplications mocks base method.
+func (m *MockInterface) WatchApplications(ctx context.Context, name, resourceVersion string) (<-chan *v1alpha1.ApplicationWatchEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WatchApplications", ctx, name, resourceVersion)
+	ret0, _ := ret[0].(<-chan *v1alpha1.ApplicationWatchEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WatchApplications indicates an expected call of WatchApplications.
+func (mr *MockInterfaceMockRecorder) WatchApplications(ctx, name, resourceVersion any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WatchApplications", reflect.TypeOf((*MockInterface)(nil).WatchApplications), ctx, name, resourceVersion)
+}
@@ -0,0 +1,91 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Instance describes a named ArgoCD instance held by a Pool
+type Instance struct {
+	Name    string
+	Server  string
+	Default bool
+}
+
+// Pool holds one Manager per named ArgoCD instance and routes calls to them
+type Pool struct {
+	mu          sync.RWMutex
+	names       []string
+	managers    map[string]*Manager
+	defaultName string
+}
+
+// NewPool creates an empty Pool
+func NewPool() *Pool {
+	return &Pool{
+		managers: make(map[string]*Manager),
+	}
+}
+
+// Add registers an instance. The first instance added becomes the default.
+func (p *Pool) Add(name string, config *Config) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, exists := p.managers[name]; exists {
+		return fmt.Errorf("instance %q is already registered", name)
+	}
+	p.managers[name] = NewManager(config)
+	p.names = append(p.names, name)
+	if p.defaultName == "" {
+		p.defaultName = name
+	}
+	return nil
+}
+
+// Client returns the shared client of the named instance.
+// An empty name selects the default instance.
+func (p *Pool) Client(ctx context.Context, name string) (Interface, error) {
+	p.mu.RLock()
+	if name == "" {
+		name = p.defaultName
+	}
+	manager, exists := p.managers[name]
+	p.mu.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownInstance, name)
+	}
+	return manager.Client(ctx)
+}
+
+// Instances returns the registered instances in the order they were added
+func (p *Pool) Instances() []Instance {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	instances := make([]Instance, 0, len(p.names))
+	for _, name := range p.names {
+		instances = append(instances, Instance{
+			Name:    name,
+			Server:  p.managers[name].config.ServerAddr,
+			Default: name == p.defaultName,
+		})
+	}
+	return instances
+}
+
+// Close closes the connections of all instances
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var err error
+	for _, name := range p.names {
+		if closeErr := p.managers[name].Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
@@ -0,0 +1,69 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestPool_Instances(t *testing.T) {
+	p := NewPool()
+	if err := p.Add("prod", &Config{ServerAddr: "argocd.prod:443"}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if err := p.Add("staging", &Config{ServerAddr: "argocd.staging:443"}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if err := p.Add("prod", &Config{}); err == nil {
+		t.Fatal("expected an error when adding a duplicate instance")
+	}
+
+	instances := p.Instances()
+	if len(instances) != 2 || instances[0].Name != "prod" || instances[1].Name != "staging" {
+		t.Fatalf("unexpected instances %+v", instances)
+	}
+	if !instances[0].Default || instances[1].Default {
+		t.Fatalf("expected the first instance to be the default, got %+v", instances)
+	}
+	if instances[1].Server != "argocd.staging:443" {
+		t.Fatalf("unexpected server %q", instances[1].Server)
+	}
+}
+
+func TestPool_Client(t *testing.T) {
+	p := NewPool()
+	for _, name := range []string{"prod", "staging"} {
+		if err := p.Add(name, &Config{ServerAddr: name + ":443", AuthToken: "test-token"}); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+	}
+
+	created := map[string]int{}
+	for name, m := range p.managers {
+		name := name
+		m.newClient = func(*Config) (Interface, error) {
+			created[name]++
+			return &fakeConn{}, nil
+		}
+	}
+
+	for _, name := range []string{"", "prod", "staging"} {
+		if _, err := p.Client(context.Background(), name); err != nil {
+			t.Fatalf("Client(%q) error = %v", name, err)
+		}
+	}
+	if created["prod"] != 1 || created["staging"] != 1 {
+		t.Fatalf("expected one connection per instance, got %v", created)
+	}
+
+	if _, err := p.Client(context.Background(), "missing"); !errors.Is(err, ErrUnknownInstance) {
+		t.Fatalf("Client() error = %v, want ErrUnknownInstance", err)
+	}
+
+	if err := p.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if _, err := p.Client(context.Background(), "prod"); !errors.Is(err, ErrManagerClosed) {
+		t.Fatalf("Client() after Close error = %v, want ErrManagerClosed", err)
+	}
+}
@@ -0,0 +1,339 @@
+package client
+
+import (
+	"context"
+
+	sessionpkg "github.com/argoproj/argo-cd/v2/pkg/apiclient/session"
+	versionpkg "github.com/argoproj/argo-cd/v2/pkg/apiclient/version"
+	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracedClient starts a span around every call to the wrapped client. Spans of
+// the gRPC calls made by the client become its children.
+type tracedClient struct {
+	next Interface
+}
+
+// WithTracing wraps c so that each of its methods is traced
+func WithTracing(c Interface) Interface {
+	return &tracedClient{next: c}
+}
+
+func (c *tracedClient) start(ctx context.Context, method string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracing.Tracer().Start(ctx, "argocd.client/"+method,
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+// GetApplication traces GetApplication of the wrapped client
+func (c *tracedClient) GetApplication(ctx context.Context, name string) (*v1alpha1.Application, error) {
+	ctx, span := c.start(ctx, "GetApplication", attribute.String("argocd.name", name))
+	result, err := c.next.GetApplication(ctx, name)
+	tracing.End(span, err)
+	return result, err
+}
+
+// ListApplications traces ListApplications of the wrapped client
+func (c *tracedClient) ListApplications(ctx context.Context, selector string) (*v1alpha1.ApplicationList, error) {
+	ctx, span := c.start(ctx, "ListApplications")
+	result, err := c.next.ListApplications(ctx, selector)
+	tracing.End(span, err)
+	return result, err
+}
+
+// CreateApplication traces CreateApplication of the wrapped client
+func (c *tracedClient) CreateApplication(ctx context.Context, app *v1alpha1.Application, upsert bool) (*v1alpha1.Application, error) {
+	ctx, span := c.start(ctx, "CreateApplication")
+	result, err := c.next.CreateApplication(ctx, app, upsert)
+	tracing.End(span, err)
+	return result, err
+}
+
+// UpdateApplication traces UpdateApplication of the wrapped client
+func (c *tracedClient) UpdateApplication(ctx context.Context, app *v1alpha1.Application) (*v1alpha1.Application, error) {
+	ctx, span := c.start(ctx, "UpdateApplication")
+	result, err := c.next.UpdateApplication(ctx, app)
+	tracing.End(span, err)
+	return result, err
+}
+
+// PatchApplication traces PatchApplication of the wrapped client
+func (c *tracedClient) PatchApplication(ctx context.Context, name string, patch string, patchType string) (*v1alpha1.Application, error) {
+	ctx, span := c.start(ctx, "PatchApplication", attribute.String("argocd.name", name))
+	result, err := c.next.PatchApplication(ctx, name, patch, patchType)
+	tracing.End(span, err)
+	return result, err
+}
+
+// DeleteApplication traces DeleteApplication of the wrapped client
+func (c *tracedClient) DeleteApplication(ctx context.Context, name string, cascade bool) error {
+	ctx, span := c.start(ctx, "DeleteApplication", attribute.String("argocd.name", name))
+	err := c.next.DeleteApplication(ctx, name, cascade)
+	tracing.End(span, err)
+	return err
+}
+
+// SyncApplication traces SyncApplication of the wrapped client
+func (c *tracedClient) SyncApplication(ctx context.Context, name string, sync SyncRequest) (*v1alpha1.Application, error) {
+	ctx, span := c.start(ctx, "SyncApplication", attribute.String("argocd.name", name))
+	result, err := c.next.SyncApplication(ctx, name, sync)
+	tracing.End(span, err)
+	return result, err
+}
+
+// RollbackApplication traces RollbackApplication of the wrapped client
+func (c *tracedClient) RollbackApplication(ctx context.Context, name string, id int64) (*v1alpha1.Application, error) {
+	ctx, span := c.start(ctx, "RollbackApplication", attribute.String("argocd.name", name))
+	result, err := c.next.RollbackApplication(ctx, name, id)
+	tracing.End(span, err)
+	return result, err
+}
+
+// GetRevisionMetadata traces GetRevisionMetadata of the wrapped client
+func (c *tracedClient) GetRevisionMetadata(ctx context.Context, name string, revision string, sourceIndex *int32, versionID *int32) (*v1alpha1.RevisionMetadata, error) {
+	ctx, span := c.start(ctx, "GetRevisionMetadata", attribute.String("argocd.name", name), attribute.String("argocd.revision", revision))
+	result, err := c.next.GetRevisionMetadata(ctx, name, revision, sourceIndex, versionID)
+	tracing.End(span, err)
+	return result, err
+}
+
+// RefreshApplication traces RefreshApplication of the wrapped client
+func (c *tracedClient) RefreshApplication(ctx context.Context, name string, refreshType string) (*v1alpha1.Application, error) {
+	ctx, span := c.start(ctx, "RefreshApplication", attribute.String("argocd.name", name))
+	result, err := c.next.RefreshApplication(ctx, name, refreshType)
+	tracing.End(span, err)
+	return result, err
+}
+
+// GetApplicationManifests traces GetApplicationManifests of the wrapped client
+func (c *tracedClient) GetApplicationManifests(ctx context.Context, name string, revision string) (interface{}, error) {
+	ctx, span := c.start(ctx, "GetApplicationManifests", attribute.String("argocd.name", name))
+	result, err := c.next.GetApplicationManifests(ctx, name, revision)
+	tracing.End(span, err)
+	return result, err
+}
+
+// GetApplicationEvents traces GetApplicationEvents of the wrapped client
+func (c *tracedClient) GetApplicationEvents(ctx context.Context, name string, resourceNamespace string, resourceName string, resourceUID string, appNamespace string, project string) (interface{}, error) {
+	ctx, span := c.start(ctx, "GetApplicationEvents", attribute.String("argocd.name", name))
+	result, err := c.next.GetApplicationEvents(ctx, name, resourceNamespace, resourceName, resourceUID, appNamespace, project)
+	tracing.End(span, err)
+	return result, err
+}
+
+// GetApplicationLogs traces GetApplicationLogs of the wrapped client
+func (c *tracedClient) GetApplicationLogs(ctx context.Context, name string, podName string, container string, namespace string, resourceName string, kind string, group string, tailLines int64, sinceSeconds *int64, follow bool, previous bool, filter string, appNamespace string, project string) (LogStream, error) {
+	ctx, span := c.start(ctx, "GetApplicationLogs", attribute.String("argocd.name", name))
+	result, err := c.next.GetApplicationLogs(ctx, name, podName, container, namespace, resourceName, kind, group, tailLines, sinceSeconds, follow, previous, filter, appNamespace, project)
+	tracing.End(span, err)
+	return result, err
+}
+
+// GetManagedResources traces GetManagedResources of the wrapped client
+func (c *tracedClient) GetManagedResources(ctx context.Context, name string, kind string, resourceNamespace string, resourceName string) ([]*v1alpha1.ResourceDiff, error) {
+	ctx, span := c.start(ctx, "GetManagedResources", attribute.String("argocd.name", name))
+	result, err := c.next.GetManagedResources(ctx, name, kind, resourceNamespace, resourceName)
+	tracing.End(span, err)
+	return result, err
+}
+
+// GetApplicationResourceTree traces GetApplicationResourceTree of the wrapped client
+func (c *tracedClient) GetApplicationResourceTree(ctx context.Context, name string, appNamespace string, project string) (*v1alpha1.ApplicationTree, error) {
+	ctx, span := c.start(ctx, "GetApplicationResourceTree", attribute.String("argocd.name", name))
+	result, err := c.next.GetApplicationResourceTree(ctx, name, appNamespace, project)
+	tracing.End(span, err)
+	return result, err
+}
+
+// TerminateOperation traces TerminateOperation of the wrapped client
+func (c *tracedClient) TerminateOperation(ctx context.Context, name string, appNamespace string, project string) error {
+	ctx, span := c.start(ctx, "TerminateOperation", attribute.String("argocd.name", name))
+	err := c.next.TerminateOperation(ctx, name, appNamespace, project)
+	tracing.End(span, err)
+	return err
+}
+
+// WatchApplications traces opening the watch stream of the wrapped client
+func (c *tracedClient) WatchApplications(ctx context.Context, name string, resourceVersion string) (<-chan *v1alpha1.ApplicationWatchEvent, error) {
+	_, span := c.start(ctx, "WatchApplications", attribute.String("argocd.name", name))
+	result, err := c.next.WatchApplications(ctx, name, resourceVersion)
+	tracing.End(span, err)
+	return result, err
+}
+
+// ListClusters traces ListClusters of the wrapped client
+func (c *tracedClient) ListClusters(ctx context.Context) (*v1alpha1.ClusterList, error) {
+	ctx, span := c.start(ctx, "ListClusters")
+	result, err := c.next.ListClusters(ctx)
+	tracing.End(span, err)
+	return result, err
+}
+
+// GetCluster traces GetCluster of the wrapped client
+func (c *tracedClient) GetCluster(ctx context.Context, server string) (*v1alpha1.Cluster, error) {
+	ctx, span := c.start(ctx, "GetCluster", attribute.String("argocd.server", server))
+	result, err := c.next.GetCluster(ctx, server)
+	tracing.End(span, err)
+	return result, err
+}
+
+// CreateCluster traces CreateCluster of the wrapped client
+func (c *tracedClient) CreateCluster(ctx context.Context, cluster *v1alpha1.Cluster, upsert bool) (*v1alpha1.Cluster, error) {
+	ctx, span := c.start(ctx, "CreateCluster")
+	result, err := c.next.CreateCluster(ctx, cluster, upsert)
+	tracing.End(span, err)
+	return result, err
+}
+
+// UpdateCluster traces UpdateCluster of the wrapped client
+func (c *tracedClient) UpdateCluster(ctx context.Context, cluster *v1alpha1.Cluster) (*v1alpha1.Cluster, error) {
+	ctx, span := c.start(ctx, "UpdateCluster")
+	result, err := c.next.UpdateCluster(ctx, cluster)
+	tracing.End(span, err)
+	return result, err
+}
+
+// DeleteCluster traces DeleteCluster of the wrapped client
+func (c *tracedClient) DeleteCluster(ctx context.Context, server string) error {
+	ctx, span := c.start(ctx, "DeleteCluster", attribute.String("argocd.server", server))
+	err := c.next.DeleteCluster(ctx, server)
+	tracing.End(span, err)
+	return err
+}
+
+// ListProjects traces ListProjects of the wrapped client
+func (c *tracedClient) ListProjects(ctx context.Context) (*v1alpha1.AppProjectList, error) {
+	ctx, span := c.start(ctx, "ListProjects")
+	result, err := c.next.ListProjects(ctx)
+	tracing.End(span, err)
+	return result, err
+}
+
+// GetProject traces GetProject of the wrapped client
+func (c *tracedClient) GetProject(ctx context.Context, name string) (*v1alpha1.AppProject, error) {
+	ctx, span := c.start(ctx, "GetProject", attribute.String("argocd.name", name))
+	result, err := c.next.GetProject(ctx, name)
+	tracing.End(span, err)
+	return result, err
+}
+
+// CreateProject traces CreateProject of the wrapped client
+func (c *tracedClient) CreateProject(ctx context.Context, project *v1alpha1.AppProject, upsert bool) (*v1alpha1.AppProject, error) {
+	ctx, span := c.start(ctx, "CreateProject")
+	result, err := c.next.CreateProject(ctx, project, upsert)
+	tracing.End(span, err)
+	return result, err
+}
+
+// UpdateProject traces UpdateProject of the wrapped client
+func (c *tracedClient) UpdateProject(ctx context.Context, project *v1alpha1.AppProject) (*v1alpha1.AppProject, error) {
+	ctx, span := c.start(ctx, "UpdateProject")
+	result, err := c.next.UpdateProject(ctx, project)
+	tracing.End(span, err)
+	return result, err
+}
+
+// DeleteProject traces DeleteProject of the wrapped client
+func (c *tracedClient) DeleteProject(ctx context.Context, name string) error {
+	ctx, span := c.start(ctx, "DeleteProject", attribute.String("argocd.name", name))
+	err := c.next.DeleteProject(ctx, name)
+	tracing.End(span, err)
+	return err
+}
+
+// ListRepositories traces ListRepositories of the wrapped client
+func (c *tracedClient) ListRepositories(ctx context.Context) (*v1alpha1.RepositoryList, error) {
+	ctx, span := c.start(ctx, "ListRepositories")
+	result, err := c.next.ListRepositories(ctx)
+	tracing.End(span, err)
+	return result, err
+}
+
+// GetRepository traces GetRepository of the wrapped client
+func (c *tracedClient) GetRepository(ctx context.Context, repo string) (*v1alpha1.Repository, error) {
+	ctx, span := c.start(ctx, "GetRepository", attribute.String("argocd.repo", repo))
+	result, err := c.next.GetRepository(ctx, repo)
+	tracing.End(span, err)
+	return result, err
+}
+
+// CreateRepository traces CreateRepository of the wrapped client
+func (c *tracedClient) CreateRepository(ctx context.Context, repo *v1alpha1.Repository, upsert bool) (*v1alpha1.Repository, error) {
+	ctx, span := c.start(ctx, "CreateRepository")
+	result, err := c.next.CreateRepository(ctx, repo, upsert)
+	tracing.End(span, err)
+	return result, err
+}
+
+// UpdateRepository traces UpdateRepository of the wrapped client
+func (c *tracedClient) UpdateRepository(ctx context.Context, repo *v1alpha1.Repository) (*v1alpha1.Repository, error) {
+	ctx, span := c.start(ctx, "UpdateRepository")
+	result, err := c.next.UpdateRepository(ctx, repo)
+	tracing.End(span, err)
+	return result, err
+}
+
+// DeleteRepository traces DeleteRepository of the wrapped client
+func (c *tracedClient) DeleteRepository(ctx context.Context, repo string) error {
+	ctx, span := c.start(ctx, "DeleteRepository", attribute.String("argocd.repo", repo))
+	err := c.next.DeleteRepository(ctx, repo)
+	tracing.End(span, err)
+	return err
+}
+
+// ListApplicationSets traces ListApplicationSets of the wrapped client
+func (c *tracedClient) ListApplicationSets(ctx context.Context, project string) (*v1alpha1.ApplicationSetList, error) {
+	ctx, span := c.start(ctx, "ListApplicationSets")
+	result, err := c.next.ListApplicationSets(ctx, project)
+	tracing.End(span, err)
+	return result, err
+}
+
+// GetApplicationSet traces GetApplicationSet of the wrapped client
+func (c *tracedClient) GetApplicationSet(ctx context.Context, name string, appsetNamespace string) (*v1alpha1.ApplicationSet, error) {
+	ctx, span := c.start(ctx, "GetApplicationSet", attribute.String("argocd.name", name))
+	result, err := c.next.GetApplicationSet(ctx, name, appsetNamespace)
+	tracing.End(span, err)
+	return result, err
+}
+
+// CreateApplicationSet traces CreateApplicationSet of the wrapped client
+func (c *tracedClient) CreateApplicationSet(ctx context.Context, appSet *v1alpha1.ApplicationSet, upsert bool, dryRun bool) (*v1alpha1.ApplicationSet, error) {
+	ctx, span := c.start(ctx, "CreateApplicationSet")
+	result, err := c.next.CreateApplicationSet(ctx, appSet, upsert, dryRun)
+	tracing.End(span, err)
+	return result, err
+}
+
+// DeleteApplicationSet traces DeleteApplicationSet of the wrapped client
+func (c *tracedClient) DeleteApplicationSet(ctx context.Context, name string, appsetNamespace string) error {
+	ctx, span := c.start(ctx, "DeleteApplicationSet", attribute.String("argocd.name", name))
+	err := c.next.DeleteApplicationSet(ctx, name, appsetNamespace)
+	tracing.End(span, err)
+	return err
+}
+
+// GetUserInfo traces GetUserInfo of the wrapped client
+func (c *tracedClient) GetUserInfo(ctx context.Context) (*sessionpkg.GetUserInfoResponse, error) {
+	ctx, span := c.start(ctx, "GetUserInfo")
+	result, err := c.next.GetUserInfo(ctx)
+	tracing.End(span, err)
+	return result, err
+}
+
+// GetVersion traces GetVersion of the wrapped client
+func (c *tracedClient) GetVersion(ctx context.Context) (*versionpkg.VersionMessage, error) {
+	ctx, span := c.start(ctx, "GetVersion")
+	result, err := c.next.GetVersion(ctx)
+	tracing.End(span, err)
+	return result, err
+}
+
+// Close closes the wrapped client
+func (c *tracedClient) Close() error {
+	return c.next.Close()
+}
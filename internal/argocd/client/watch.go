@@ -0,0 +1,100 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	applicationpkg "github.com/argoproj/argo-cd/v2/pkg/apiclient/application"
+	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/logging"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Delays between attempts to reconnect a broken watch stream
+var (
+	watchRetryInitial = time.Second
+	watchRetryMax     = 30 * time.Second
+)
+
+// WatchApplications streams changes to the named application, or to all applications
+// when name is empty, starting after resourceVersion. A broken stream is reconnected
+// with backoff and resumed from the last resourceVersion seen. The returned channel
+// is closed once ctx is done, or once the client is closed: callers holding a
+// replaceable client (see Manager) then watch again on a new one.
+func (c *Client) WatchApplications(ctx context.Context, name string, resourceVersion string) (<-chan *v1alpha1.ApplicationWatchEvent, error) {
+	stream, err := c.watchApplications(ctx, name, resourceVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to watch applications: %w", err)
+	}
+
+	events := make(chan *v1alpha1.ApplicationWatchEvent)
+	go func() {
+		defer close(events)
+		delay := watchRetryInitial
+		for {
+			for {
+				event, err := stream.Recv()
+				if err != nil {
+					if ctx.Err() != nil || c.closed.Load() {
+						return
+					}
+					if !errors.Is(err, io.EOF) {
+						logging.WithField("application", name).WithError(err).Debug("Application watch broke, reconnecting")
+					}
+					break
+				}
+				delay = watchRetryInitial
+				resourceVersion = event.Application.ResourceVersion
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(delay):
+				}
+				delay = min(delay*2, watchRetryMax)
+				if c.closed.Load() {
+					return
+				}
+
+				stream, err = c.watchApplications(ctx, name, resourceVersion)
+				if err == nil {
+					break
+				}
+				if expiredResourceVersion(err) {
+					// Start over; the server sends the current state of the applications first
+					resourceVersion = ""
+				}
+				logging.WithField("application", name).WithError(err).Debug("Failed to reconnect application watch")
+			}
+		}
+	}()
+	return events, nil
+}
+
+func (c *Client) watchApplications(ctx context.Context, name string, resourceVersion string) (applicationpkg.ApplicationService_WatchClient, error) {
+	req := &applicationpkg.ApplicationQuery{}
+	if name != "" {
+		req.Name = &name
+	}
+	if resourceVersion != "" {
+		req.ResourceVersion = &resourceVersion
+	}
+	return c.appClient.Watch(ctx, req)
+}
+
+// expiredResourceVersion reports whether a watch cannot resume from the requested resourceVersion
+func expiredResourceVersion(err error) bool {
+	return status.Code(err) == codes.OutOfRange || strings.Contains(err.Error(), "too old resource version")
+}
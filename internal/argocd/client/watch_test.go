@@ -0,0 +1,184 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	applicationpkg "github.com/argoproj/argo-cd/v2/pkg/apiclient/application"
+	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fakeWatchService serves one scripted stream per Watch call
+type fakeWatchService struct {
+	applicationpkg.ApplicationServiceClient
+
+	mu       sync.Mutex
+	streams  []*fakeWatchStream
+	versions []string
+}
+
+func (f *fakeWatchService) Watch(ctx context.Context, in *applicationpkg.ApplicationQuery, opts ...grpc.CallOption) (applicationpkg.ApplicationService_WatchClient, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.versions = append(f.versions, in.GetResourceVersion())
+	if len(f.streams) == 0 {
+		// Block until the watch is cancelled
+		return &fakeWatchStream{ctx: ctx}, nil
+	}
+	stream := f.streams[0]
+	f.streams = f.streams[1:]
+	if stream.openErr {
+		return nil, stream.err
+	}
+	stream.ctx = ctx
+	return stream, nil
+}
+
+func (f *fakeWatchService) requestedVersions() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.versions...)
+}
+
+// fakeWatchStream returns its events, then err, or blocks until cancelled when it has
+// none. With openErr, the Watch call itself fails with err.
+type fakeWatchStream struct {
+	grpc.ClientStream
+	ctx     context.Context
+	events  []*v1alpha1.ApplicationWatchEvent
+	err     error
+	openErr bool
+}
+
+func (s *fakeWatchStream) Recv() (*v1alpha1.ApplicationWatchEvent, error) {
+	if len(s.events) > 0 {
+		event := s.events[0]
+		s.events = s.events[1:]
+		return event, nil
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+	<-s.ctx.Done()
+	return nil, s.ctx.Err()
+}
+
+func watchEvent(name, resourceVersion string) *v1alpha1.ApplicationWatchEvent {
+	return &v1alpha1.ApplicationWatchEvent{
+		Type: "MODIFIED",
+		Application: v1alpha1.Application{
+			ObjectMeta: metav1.ObjectMeta{Name: name, ResourceVersion: resourceVersion},
+		},
+	}
+}
+
+func TestWatchApplications_Reconnects(t *testing.T) {
+	defer func(initial time.Duration) { watchRetryInitial = initial }(watchRetryInitial)
+	watchRetryInitial = time.Millisecond
+
+	service := &fakeWatchService{streams: []*fakeWatchStream{
+		{events: []*v1alpha1.ApplicationWatchEvent{watchEvent("guestbook", "11"), watchEvent("guestbook", "12")}, err: io.EOF},
+		{err: errors.New("connection reset"), openErr: true},
+		{events: []*v1alpha1.ApplicationWatchEvent{watchEvent("guestbook", "13")}, err: status.Error(codes.Unavailable, "server restarted")},
+		{err: status.Error(codes.OutOfRange, "too old resource version"), openErr: true},
+		{events: []*v1alpha1.ApplicationWatchEvent{watchEvent("guestbook", "20")}},
+	}}
+	c := &Client{appClient: service}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events, err := c.WatchApplications(ctx, "guestbook", "10")
+	if err != nil {
+		t.Fatalf("WatchApplications() error = %v", err)
+	}
+
+	var got []string
+	for len(got) < 4 {
+		select {
+		case event := <-events:
+			got = append(got, event.Application.ResourceVersion)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for events, got %v", got)
+		}
+	}
+	if want := []string{"11", "12", "13", "20"}; !equalStrings(got, want) {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+
+	// Resumed from the last version seen, and started over once it expired
+	if want := []string{"10", "12", "12", "13", ""}; !equalStrings(service.requestedVersions(), want) {
+		t.Errorf("resource versions = %v, want %v", service.requestedVersions(), want)
+	}
+
+	cancel()
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Error("expected the events channel to be closed")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("events channel not closed after cancellation")
+	}
+}
+
+func TestWatchApplications_ClosedClient(t *testing.T) {
+	defer func(initial time.Duration) { watchRetryInitial = initial }(watchRetryInitial)
+	watchRetryInitial = time.Millisecond
+
+	// The stream breaks because the client was closed, as when a Manager replaces it
+	service := &fakeWatchService{streams: []*fakeWatchStream{
+		{events: []*v1alpha1.ApplicationWatchEvent{watchEvent("guestbook", "11")}, err: status.Error(codes.Canceled, "grpc: the client connection is closing")},
+	}}
+	c := &Client{appClient: service}
+
+	events, err := c.WatchApplications(context.Background(), "guestbook", "10")
+	if err != nil {
+		t.Fatalf("WatchApplications() error = %v", err)
+	}
+	_ = c.Close()
+	if event := <-events; event.Application.ResourceVersion != "11" {
+		t.Errorf("event resource version = %s, want 11", event.Application.ResourceVersion)
+	}
+
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Error("expected the events channel to be closed")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("events channel not closed after the client was closed")
+	}
+	if want := []string{"10"}; !equalStrings(service.requestedVersions(), want) {
+		t.Errorf("resource versions = %v, want %v", service.requestedVersions(), want)
+	}
+}
+
+func TestWatchApplications_Error(t *testing.T) {
+	service := &fakeWatchService{streams: []*fakeWatchStream{
+		{err: status.Error(codes.PermissionDenied, "denied"), openErr: true},
+	}}
+	c := &Client{appClient: service}
+
+	if _, err := c.WatchApplications(context.Background(), "guestbook", ""); err == nil {
+		t.Error("expected an error when the first watch fails")
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,241 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Outcomes of an audited tool call
+const (
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+	// OutcomePendingConfirmation marks calls that only returned a plan to confirm
+	OutcomePendingConfirmation = "pending_confirmation"
+)
+
+// Redacted replaces the value of arguments that look like secrets
+const Redacted = "[REDACTED]"
+
+// Record is one audited tool call
+type Record struct {
+	Time       time.Time      `json:"time"`
+	SessionID  string         `json:"sessionId,omitempty"`
+	Client     string         `json:"client,omitempty"`
+	Tool       string         `json:"tool"`
+	Instance   string         `json:"instance,omitempty"`
+	Arguments  map[string]any `json:"arguments,omitempty"`
+	User       string         `json:"user,omitempty"`
+	Outcome    string         `json:"outcome"`
+	Error      string         `json:"error,omitempty"`
+	DurationMS int64          `json:"durationMs"`
+	// PrevHash is the SHA-256 of the previous record line, chaining records so
+	// that removed or modified lines can be detected
+	PrevHash string `json:"prevHash"`
+}
+
+// Logger writes audit records as JSON lines
+type Logger struct {
+	mu       sync.Mutex
+	w        io.Writer
+	closer   io.Closer
+	prevHash string
+}
+
+// NewLogger writes audit records to w, for example os.Stderr
+func NewLogger(w io.Writer) *Logger {
+	return &Logger{w: w}
+}
+
+// Open appends audit records to the file at path, rotating it once it grows
+// beyond maxSize bytes and keeping up to maxBackups rotated files. A maxSize of
+// zero disables rotation.
+func Open(path string, maxSize int64, maxBackups int) (*Logger, error) {
+	prevHash, err := lastLineHash(path)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := openRotatingFile(path, maxSize, maxBackups)
+	if err != nil {
+		return nil, err
+	}
+	return &Logger{w: file, closer: file, prevHash: prevHash}, nil
+}
+
+// Log redacts the record arguments, chains it to the previous record and writes it
+func (l *Logger) Log(record Record) error {
+	record.Arguments = Redact(record.Arguments)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	record.PrevHash = l.prevHash
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit record: %w", err)
+	}
+	// A writer may report an error for a record it wrote, such as a failed rotation;
+	// the chain continues from every record written in full
+	n, err := l.w.Write(append(line, '\n'))
+	if n == len(line)+1 {
+		l.prevHash = hashLine(line)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write audit record: %w", err)
+	}
+	return nil
+}
+
+// Close closes the underlying file, if any
+func (l *Logger) Close() error {
+	if l.closer == nil {
+		return nil
+	}
+	return l.closer.Close()
+}
+
+// Verify checks the hash chain of audit records read from r and returns the number
+// of valid records. The first record may chain to a record in a rotated file.
+func Verify(r io.Reader) (int, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	count := 0
+	prevHash := ""
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		var record Record
+		if err := json.Unmarshal(line, &record); err != nil {
+			return count, fmt.Errorf("record %d is not valid JSON: %w", count+1, err)
+		}
+		if count > 0 && record.PrevHash != prevHash {
+			return count, fmt.Errorf("record %d does not chain to the previous record", count+1)
+		}
+		prevHash = hashLine(line)
+		count++
+	}
+	return count, scanner.Err()
+}
+
+// sensitiveKeys are argument name fragments whose values are redacted
+var sensitiveKeys = []string{"token", "password", "secret", "credential", "privatekey", "private_key", "bearer", "apikey", "api_key"}
+
+// Redact returns a copy of args with the values of secret-looking keys replaced,
+// recursively. Strings holding a JSON or YAML document, such as the spec of
+// update_application or the patch of patch_application, are redacted the same way.
+func Redact(args map[string]any) map[string]any {
+	if args == nil {
+		return nil
+	}
+	redacted := make(map[string]any, len(args))
+	for key, value := range args {
+		if isSensitive(key) {
+			redacted[key] = Redacted
+			continue
+		}
+		redacted[key] = redactValue(value)
+	}
+	// Name and value pairs, such as Helm parameters, environment variables and JSON
+	// patch operations, name the secret in a sibling of its value
+	if _, ok := redacted["value"]; ok {
+		for _, key := range []string{"name", "path"} {
+			if name, ok := redacted[key].(string); ok && isSensitive(name) {
+				redacted["value"] = Redacted
+			}
+		}
+	}
+	return redacted
+}
+
+func redactValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		return Redact(v)
+	case []any:
+		items := make([]any, len(v))
+		for i, item := range v {
+			items[i] = redactValue(item)
+		}
+		return items
+	case string:
+		return redactDocument(v)
+	default:
+		return value
+	}
+}
+
+// redactDocument redacts a string holding a JSON or YAML object or list. When
+// something is redacted, the document is returned re-encoded as JSON; other
+// strings are returned as they are.
+func redactDocument(s string) string {
+	if !strings.ContainsAny(s, ":{[") {
+		return s
+	}
+	var doc any
+	if err := yaml.Unmarshal([]byte(s), &doc); err != nil {
+		return s
+	}
+	switch doc.(type) {
+	case map[string]any, []any:
+	default:
+		return s
+	}
+
+	redacted := redactValue(doc)
+	if reflect.DeepEqual(doc, redacted) {
+		return s
+	}
+	data, err := json.Marshal(redacted)
+	if err != nil {
+		return Redacted
+	}
+	return string(data)
+}
+
+func isSensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, fragment := range sensitiveKeys {
+		if strings.Contains(key, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+func hashLine(line []byte) string {
+	sum := sha256.Sum256(line)
+	return hex.EncodeToString(sum[:])
+}
+
+// lastLineHash returns the hash of the last record in an existing audit file,
+// so that a restarted server continues the chain
+func lastLineHash(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read audit log: %w", err)
+	}
+
+	data = bytes.TrimRight(data, "\n")
+	if len(data) == 0 {
+		return "", nil
+	}
+	if i := bytes.LastIndexByte(data, '\n'); i >= 0 {
+		data = data[i+1:]
+	}
+	return hashLine(data), nil
+}
@@ -0,0 +1,161 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedact(t *testing.T) {
+	args := map[string]any{
+		"name":      "guestbook",
+		"authToken": "secret-token",
+		"source": map[string]any{
+			"repoURL":  "https://github.com/example/repo",
+			"password": "hunter2",
+		},
+		"items": []any{map[string]any{"clientSecret": "s"}},
+	}
+
+	redacted := Redact(args)
+	assert.Equal(t, "guestbook", redacted["name"])
+	assert.Equal(t, Redacted, redacted["authToken"])
+	assert.Equal(t, Redacted, redacted["source"].(map[string]any)["password"])
+	assert.Equal(t, "https://github.com/example/repo", redacted["source"].(map[string]any)["repoURL"])
+	assert.Equal(t, Redacted, redacted["items"].([]any)[0].(map[string]any)["clientSecret"])
+
+	// The original arguments are left untouched
+	assert.Equal(t, "secret-token", args["authToken"])
+	assert.Nil(t, Redact(nil))
+}
+
+func TestRedact_Documents(t *testing.T) {
+	args := map[string]any{
+		"name":       "guestbook",
+		"patch":      `{"source":{"helm":{"values":"auth:\n  token: ghp_abc123\n"}}}`,
+		"json_patch": `[{"op":"replace","path":"/source/helm/parameters/0/apiToken","value":"ghp_abc123"},{"op":"replace","path":"/source/targetRevision","value":"v2"}]`,
+		"spec":       "source:\n  helm:\n    parameters:\n    - name: db.password\n      value: hunter2\n    - name: replicas\n      value: \"3\"\n",
+		"selector":   "app=guestbook",
+		"resources":  ":ConfigMap:web/old",
+	}
+
+	redacted := Redact(args)
+	for key, value := range redacted {
+		assert.NotContains(t, value, "ghp_abc123", key)
+		assert.NotContains(t, value, "hunter2", key)
+	}
+
+	var patch map[string]any
+	require.NoError(t, json.Unmarshal([]byte(redacted["patch"].(string)), &patch))
+	var values map[string]any
+	require.NoError(t, json.Unmarshal([]byte(patch["source"].(map[string]any)["helm"].(map[string]any)["values"].(string)), &values))
+	assert.Equal(t, Redacted, values["auth"].(map[string]any)["token"])
+
+	var operations []map[string]any
+	require.NoError(t, json.Unmarshal([]byte(redacted["json_patch"].(string)), &operations))
+	assert.Equal(t, Redacted, operations[0]["value"])
+	assert.Equal(t, "v2", operations[1]["value"])
+
+	var spec map[string]any
+	require.NoError(t, json.Unmarshal([]byte(redacted["spec"].(string)), &spec))
+	parameters := spec["source"].(map[string]any)["helm"].(map[string]any)["parameters"].([]any)
+	assert.Equal(t, Redacted, parameters[0].(map[string]any)["value"])
+	assert.Equal(t, "3", parameters[1].(map[string]any)["value"])
+
+	// Strings without secrets are left as they are
+	assert.Equal(t, "guestbook", redacted["name"])
+	assert.Equal(t, "app=guestbook", redacted["selector"])
+	assert.Equal(t, ":ConfigMap:web/old", redacted["resources"])
+	assert.Equal(t, `{"source":{"targetRevision":"v2"}}`, Redact(map[string]any{"patch": `{"source":{"targetRevision":"v2"}}`})["patch"])
+}
+
+func TestLogger_HashChain(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(&buf)
+
+	for _, tool := range []string{"sync_application", "delete_application", "create_project"} {
+		require.NoError(t, logger.Log(Record{Time: time.Now(), Tool: tool, Outcome: OutcomeSuccess}))
+	}
+
+	count, err := Verify(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	// Removing a record breaks the chain
+	lines := strings.SplitAfter(buf.String(), "\n")
+	tampered := lines[0] + lines[2]
+	_, err = Verify(strings.NewReader(tampered))
+	assert.ErrorContains(t, err, "does not chain")
+
+	// So does modifying one
+	modified := strings.Replace(buf.String(), "delete_application", "refresh_application", 1)
+	_, err = Verify(strings.NewReader(modified))
+	assert.ErrorContains(t, err, "does not chain")
+}
+
+func TestOpen_RotationAndRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	logger, err := Open(path, 300, 2)
+	require.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		require.NoError(t, logger.Log(Record{Tool: "sync_application", Outcome: OutcomeSuccess, Arguments: map[string]any{"name": "guestbook"}}))
+	}
+	require.NoError(t, logger.Close())
+
+	_, err = os.Stat(path + ".1")
+	assert.NoError(t, err)
+	_, err = os.Stat(path + ".2")
+	assert.NoError(t, err)
+	_, err = os.Stat(path + ".3")
+	assert.True(t, os.IsNotExist(err), "only maxBackups rotated files are kept")
+
+	// A reopened log continues the chain of the existing file
+	logger, err = Open(path, 0, 0)
+	require.NoError(t, err)
+	require.NoError(t, logger.Log(Record{Tool: "delete_application", Outcome: OutcomeError, Error: "denied"}))
+	require.NoError(t, logger.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	_, err = Verify(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	var last Record
+	require.NoError(t, json.Unmarshal([]byte(lines[len(lines)-1]), &last))
+	assert.Equal(t, "delete_application", last.Tool)
+	assert.NotEmpty(t, last.PrevHash)
+}
+
+func TestOpen_FailedRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	// A directory in the way of the first backup makes the rotation fail
+	require.NoError(t, os.MkdirAll(filepath.Join(path+".1", "keep"), 0o700))
+
+	logger, err := Open(path, 300, 1)
+	require.NoError(t, err)
+	var rotateErrs int
+	for i := 0; i < 5; i++ {
+		if err := logger.Log(Record{Tool: "sync_application", Outcome: OutcomeSuccess, Arguments: map[string]any{"name": "guestbook"}}); err != nil {
+			assert.ErrorContains(t, err, "failed to rotate audit log")
+			rotateErrs++
+		}
+	}
+	assert.Positive(t, rotateErrs)
+	require.NoError(t, logger.Close())
+
+	// Every record is still written to the current file, in one chain
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	count, err := Verify(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, 5, count)
+}
@@ -0,0 +1,120 @@
+package audit
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// rotatingFile is an append-only file that is rotated once it reaches maxSize.
+// Rotated files are renamed to path.1, path.2, ... with path.1 the most recent.
+type rotatingFile struct {
+	mu         sync.Mutex
+	path       string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+func openRotatingFile(path string, maxSize int64, maxBackups int) (*rotatingFile, error) {
+	f := &rotatingFile{path: path, maxSize: maxSize, maxBackups: maxBackups}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *rotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	f.file = file
+	f.size = info.Size()
+	return nil
+}
+
+// Write appends p, rotating first when p would take the file beyond maxSize. When
+// the rotation fails, p is still appended to the current file and the error is
+// returned with the full count.
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		// An earlier rotation could not reopen the file
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	var rotateErr error
+	if f.maxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.maxSize {
+		rotateErr = f.rotate()
+		if f.file == nil {
+			return 0, rotateErr
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	if err != nil {
+		return n, err
+	}
+	return n, rotateErr
+}
+
+// rotate shifts the backups, moves the current file to path.1 and starts a new
+// file. When the backups cannot be shifted, the current file is reopened, so that
+// records are never written to a closed file; f.file is nil only when that fails too.
+func (f *rotatingFile) rotate() error {
+	closeErr := f.file.Close()
+	f.file = nil
+	if closeErr != nil {
+		return f.reopen(closeErr)
+	}
+	if err := f.shift(); err != nil {
+		return f.reopen(err)
+	}
+	return f.open()
+}
+
+// shift removes the oldest backup and renames the others and the current file up by one
+func (f *rotatingFile) shift() error {
+	if f.maxBackups <= 0 {
+		if err := os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	_ = os.Remove(fmt.Sprintf("%s.%d", f.path, f.maxBackups))
+	for i := f.maxBackups - 1; i >= 1; i-- {
+		_ = os.Rename(fmt.Sprintf("%s.%d", f.path, i), fmt.Sprintf("%s.%d", f.path, i+1))
+	}
+	return os.Rename(f.path, f.path+".1")
+}
+
+// reopen opens the current file again after a failed rotation
+func (f *rotatingFile) reopen(rotateErr error) error {
+	if err := f.open(); err != nil {
+		return fmt.Errorf("failed to rotate audit log: %w; %w", rotateErr, err)
+	}
+	return fmt.Errorf("failed to rotate audit log: %w", rotateErr)
+}
+
+// Close closes the current file
+func (f *rotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	return f.file.Close()
+}
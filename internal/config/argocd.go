@@ -0,0 +1,55 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/argoproj/argo-cd/v2/util/localconfig"
+)
+
+// ImportArgoCDConfig adds the contexts defined in an argocd CLI config file.
+// Contexts already present in c take precedence over imported ones with the same
+// name, and the CLI's current context is used when c does not select one.
+// A missing file is not an error.
+func (c *Config) ImportArgoCDConfig(path string) error {
+	local, err := localconfig.ReadLocalConfig(expandPath(path))
+	if err != nil {
+		return fmt.Errorf("failed to read argocd config %s: %w", path, err)
+	}
+	if local == nil {
+		return nil
+	}
+
+	existing := make(map[string]bool, len(c.Contexts))
+	for _, ctx := range c.Contexts {
+		existing[ctx.Name] = true
+	}
+
+	for _, ref := range local.Contexts {
+		if existing[ref.Name] {
+			continue
+		}
+		resolved, err := local.ResolveContext(ref.Name)
+		if err != nil {
+			return fmt.Errorf("failed to resolve argocd context %q: %w", ref.Name, err)
+		}
+		if resolved.Server.Core {
+			// Core mode talks to the Kubernetes API directly and has no API server to connect to
+			continue
+		}
+		c.Contexts = append(c.Contexts, Context{
+			Name:            resolved.Name,
+			Server:          resolved.Server.Server,
+			AuthToken:       resolved.User.AuthToken,
+			Insecure:        resolved.Server.Insecure,
+			PlainText:       resolved.Server.PlainText,
+			GRPCWeb:         resolved.Server.GRPCWeb,
+			GRPCWebRootPath: resolved.Server.GRPCWebRootPath,
+		})
+		existing[ref.Name] = true
+	}
+
+	if c.CurrentContext == "" && existing[local.CurrentContext] {
+		c.CurrentContext = local.CurrentContext
+	}
+	return nil
+}
@@ -0,0 +1,305 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client"
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultContextName is the name of the context used when no contexts are configured
+const DefaultContextName = "default"
+
+// Config is the server configuration file
+type Config struct {
+	// CurrentContext is the context used when none is selected explicitly
+	CurrentContext string `json:"currentContext,omitempty"`
+	// Contexts are the named ArgoCD connection settings
+	Contexts []Context `json:"contexts,omitempty"`
+	// ArgoCDConfig is the path of an argocd CLI config file whose contexts are imported
+	ArgoCDConfig string `json:"argocdConfig,omitempty"`
+
+	// ReadOnly disables the tools that modify ArgoCD
+	ReadOnly bool `json:"readOnly,omitempty"`
+	// AllowTools restricts the server to the named tools and toolsets
+	AllowTools []string `json:"allowTools,omitempty"`
+	// DenyTools disables the named tools and toolsets
+	DenyTools []string `json:"denyTools,omitempty"`
+	// PolicyFile is the path of a rules file evaluated before every mutating tool call
+	PolicyFile string `json:"policyFile,omitempty"`
+	// ConfirmDestructive requires deletes and pruning syncs to be confirmed with a token
+	ConfirmDestructive bool `json:"confirmDestructive,omitempty"`
+	// ElicitDestructive asks the user to approve dangerous actions through MCP elicitation
+	ElicitDestructive bool `json:"elicitDestructive,omitempty"`
+	// ElicitationFallback is "deny" or "allow" for clients without elicitation support
+	ElicitationFallback string `json:"elicitationFallback,omitempty"`
+
+	// Audit configures the audit log of mutating tool calls
+	Audit Audit `json:"audit,omitempty"`
+	// Tracing configures where OpenTelemetry spans are exported to
+	Tracing Tracing `json:"tracing,omitempty"`
+}
+
+// Audit configures where audit records of mutating tool calls are written
+type Audit struct {
+	// File is the path of the JSONL audit log; "-" or "stderr" writes to stderr
+	File string `json:"file,omitempty"`
+	// MaxSizeMB rotates the file once it reaches this size (default 100)
+	MaxSizeMB int `json:"maxSizeMB,omitempty"`
+	// MaxBackups is the number of rotated files kept (default 5)
+	MaxBackups int `json:"maxBackups,omitempty"`
+}
+
+// Tracing configures the OpenTelemetry span exporter
+type Tracing struct {
+	// Exporter is none, otlp, otlp-http or file
+	Exporter string `json:"exporter,omitempty"`
+	// Endpoint is the OTLP collector address (defaults to the OTEL_EXPORTER_OTLP_* environment variables)
+	Endpoint string `json:"endpoint,omitempty"`
+	// Insecure disables TLS towards the OTLP collector
+	Insecure bool `json:"insecure,omitempty"`
+	// File is the path spans are written to by the file exporter; "-" writes to stderr
+	File string `json:"file,omitempty"`
+}
+
+// Context holds the settings needed to connect to one ArgoCD server
+type Context struct {
+	Name   string `json:"name"`
+	Server string `json:"server,omitempty"`
+
+	// Token sources, in order of precedence
+	AuthToken     string `json:"authToken,omitempty"`
+	AuthTokenEnv  string `json:"authTokenEnv,omitempty"`
+	AuthTokenFile string `json:"authTokenFile,omitempty"`
+
+	Insecure          bool     `json:"insecure,omitempty"`
+	PlainText         bool     `json:"plainText,omitempty"`
+	GRPCWeb           bool     `json:"grpcWeb,omitempty"`
+	GRPCWebRootPath   string   `json:"grpcWebRootPath,omitempty"`
+	CertFile          string   `json:"certFile,omitempty"`
+	ClientCertFile    string   `json:"clientCertFile,omitempty"`
+	ClientCertKeyFile string   `json:"clientCertKeyFile,omitempty"`
+	Headers           []string `json:"headers,omitempty"`
+	UserAgent         string   `json:"userAgent,omitempty"`
+	Timeout           string   `json:"timeout,omitempty"`
+}
+
+// Load reads a YAML configuration file. An empty path returns an empty configuration.
+func Load(path string) (*Config, error) {
+	cfg := &Config{}
+	if path == "" {
+		return cfg, nil
+	}
+
+	data, err := os.ReadFile(expandPath(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	if err := yaml.UnmarshalStrict(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Validate checks that context names are present and unique
+func (c *Config) Validate() error {
+	seen := make(map[string]bool, len(c.Contexts))
+	for i, ctx := range c.Contexts {
+		if ctx.Name == "" {
+			return fmt.Errorf("context #%d has no name", i+1)
+		}
+		if seen[ctx.Name] {
+			return fmt.Errorf("context %q is defined more than once", ctx.Name)
+		}
+		seen[ctx.Name] = true
+	}
+	if c.CurrentContext != "" && !seen[c.CurrentContext] && len(c.Contexts) > 0 {
+		return fmt.Errorf("current context %q is not defined", c.CurrentContext)
+	}
+	return nil
+}
+
+// Context returns a copy of the named context. An empty name selects the current
+// context, or the only context when exactly one is defined. When no contexts are
+// configured at all, an empty default context is returned so that it can be filled
+// in from the environment and command-line flags.
+func (c *Config) Context(name string) (*Context, error) {
+	if name == "" {
+		name = c.CurrentContext
+	}
+
+	if len(c.Contexts) == 0 {
+		if name != "" && name != DefaultContextName {
+			return nil, fmt.Errorf("context %q is not defined", name)
+		}
+		return &Context{Name: DefaultContextName}, nil
+	}
+
+	if name == "" {
+		if len(c.Contexts) > 1 {
+			return nil, fmt.Errorf("multiple contexts are defined; select one with currentContext or --context (available: %s)", strings.Join(c.ContextNames(), ", "))
+		}
+		ctx := c.Contexts[0]
+		return &ctx, nil
+	}
+
+	for _, ctx := range c.Contexts {
+		if ctx.Name == name {
+			ctx := ctx
+			return &ctx, nil
+		}
+	}
+	return nil, fmt.Errorf("context %q is not defined (available: %s)", name, strings.Join(c.ContextNames(), ", "))
+}
+
+// ContextNames returns the names of all configured contexts
+func (c *Config) ContextNames() []string {
+	names := make([]string, 0, len(c.Contexts))
+	for _, ctx := range c.Contexts {
+		names = append(names, ctx.Name)
+	}
+	return names
+}
+
+// ClientConfig resolves the token source and converts the context into an ArgoCD client configuration
+func (c *Context) ClientConfig() (*client.Config, error) {
+	token, err := c.resolveAuthToken()
+	if err != nil {
+		return nil, err
+	}
+
+	var timeout time.Duration
+	if c.Timeout != "" {
+		timeout, err = time.ParseDuration(c.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("context %q: invalid timeout %q: %w", c.Name, c.Timeout, err)
+		}
+	}
+
+	if (c.ClientCertFile == "") != (c.ClientCertKeyFile == "") {
+		return nil, fmt.Errorf("context %q: %w", c.Name, client.ErrClientCertIncomplete)
+	}
+	for _, file := range []string{c.CertFile, c.ClientCertFile, c.ClientCertKeyFile} {
+		if file == "" {
+			continue
+		}
+		if _, err := os.Stat(expandPath(file)); err != nil {
+			return nil, fmt.Errorf("context %q: %w", c.Name, err)
+		}
+	}
+
+	return &client.Config{
+		ServerAddr:        c.Server,
+		AuthToken:         token,
+		PlainText:         c.PlainText,
+		Insecure:          c.Insecure,
+		GRPCWeb:           c.GRPCWeb,
+		GRPCWebRootPath:   c.GRPCWebRootPath,
+		CertFile:          expandPath(c.CertFile),
+		ClientCertFile:    expandPath(c.ClientCertFile),
+		ClientCertKeyFile: expandPath(c.ClientCertKeyFile),
+		Headers:           c.Headers,
+		UserAgent:         c.UserAgent,
+		Timeout:           timeout,
+	}, nil
+}
+
+// resolveAuthToken returns the token from the first configured token source
+func (c *Context) resolveAuthToken() (string, error) {
+	switch {
+	case c.AuthToken != "":
+		return c.AuthToken, nil
+	case c.AuthTokenEnv != "":
+		return os.Getenv(c.AuthTokenEnv), nil
+	case c.AuthTokenFile != "":
+		data, err := os.ReadFile(expandPath(c.AuthTokenFile))
+		if err != nil {
+			return "", fmt.Errorf("context %q: failed to read auth token file: %w", c.Name, err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	default:
+		return "", nil
+	}
+}
+
+// expandPath expands a leading ~ to the user's home directory
+func expandPath(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
+
+// Options selects the context used to connect to ArgoCD and where it is loaded from
+type Options struct {
+	// File is the path of the YAML configuration file (optional)
+	File string
+	// Context is the name of the context to use (optional)
+	Context string
+	// ArgoCDConfig is the path of an argocd CLI config file to import (optional)
+	ArgoCDConfig string
+	// Flags holds command-line overrides (optional)
+	Flags *Flags
+}
+
+// Resolve loads the configuration and returns the selected context. Settings are
+// layered in order of precedence: command-line flags, ARGOCD_* environment
+// variables, the configuration file, and finally the imported argocd CLI config.
+func Resolve(opts Options) (*Context, error) {
+	cfg, err := ResolveConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &cfg.Contexts[0], nil
+}
+
+// ResolveConfig loads the configuration with every configured context, starting
+// with the selected one, which also becomes the current context. Environment
+// variables and command-line flags only apply to the selected context; the others
+// are used as configured.
+func ResolveConfig(opts Options) (*Config, error) {
+	cfg, err := Load(opts.File)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.ArgoCDConfig != "" {
+		cfg.ArgoCDConfig = opts.ArgoCDConfig
+	}
+	if cfg.ArgoCDConfig != "" {
+		if err := cfg.ImportArgoCDConfig(cfg.ArgoCDConfig); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, err := cfg.Context(opts.Context)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx.ApplyEnv()
+	if opts.Flags != nil {
+		opts.Flags.Apply(ctx)
+	}
+
+	contexts := []Context{*ctx}
+	for _, other := range cfg.Contexts {
+		if other.Name != ctx.Name {
+			contexts = append(contexts, other)
+		}
+	}
+	cfg.Contexts = contexts
+	cfg.CurrentContext = ctx.Name
+	return cfg, nil
+}
@@ -0,0 +1,258 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testConfig = `
+currentContext: staging
+contexts:
+  - name: prod
+    server: argocd.prod.example.com:443
+    authTokenEnv: PROD_ARGOCD_TOKEN
+    headers:
+      - "X-Team: platform"
+    timeout: 45s
+  - name: staging
+    server: argocd.staging.example.com:443
+    authToken: staging-token
+    grpcWeb: true
+    grpcWebRootPath: /argocd
+`
+
+const testArgoCDConfig = `
+contexts:
+- name: cli-ctx
+  server: argocd.cli.example.com
+  user: argocd.cli.example.com
+- name: staging
+  server: argocd.cli.example.com
+  user: argocd.cli.example.com
+current-context: cli-ctx
+servers:
+- server: argocd.cli.example.com
+  grpc-web-root-path: ""
+  insecure: true
+users:
+- name: argocd.cli.example.com
+  auth-token: cli-token
+`
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+// clearArgoCDEnv ensures ambient ARGOCD_* variables don't leak into a test
+func clearArgoCDEnv(t *testing.T) {
+	for _, key := range []string{"ARGOCD_SERVER", "ARGOCD_AUTH_TOKEN", "ARGOCD_INSECURE", "ARGOCD_PLAINTEXT", "ARGOCD_GRPC_WEB", "ARGOCD_GRPC_WEB_ROOT_PATH"} {
+		t.Setenv(key, "")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("empty path", func(t *testing.T) {
+		cfg, err := Load("")
+		require.NoError(t, err)
+		assert.Empty(t, cfg.Contexts)
+	})
+
+	t.Run("valid file", func(t *testing.T) {
+		cfg, err := Load(writeFile(t, "config.yaml", testConfig))
+		require.NoError(t, err)
+		assert.Equal(t, "staging", cfg.CurrentContext)
+		assert.Equal(t, []string{"prod", "staging"}, cfg.ContextNames())
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		_, err := Load(writeFile(t, "config.yaml", "contexts:\n  - name: a\n    sever: typo\n"))
+		assert.Error(t, err)
+	})
+
+	t.Run("duplicate context", func(t *testing.T) {
+		_, err := Load(writeFile(t, "config.yaml", "contexts:\n  - name: a\n  - name: a\n"))
+		assert.ErrorContains(t, err, "more than once")
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := Load(filepath.Join(t.TempDir(), "missing.yaml"))
+		assert.Error(t, err)
+	})
+}
+
+func TestConfig_Context(t *testing.T) {
+	cfg, err := Load(writeFile(t, "config.yaml", testConfig))
+	require.NoError(t, err)
+
+	ctx, err := cfg.Context("")
+	require.NoError(t, err)
+	assert.Equal(t, "staging", ctx.Name)
+
+	ctx, err = cfg.Context("prod")
+	require.NoError(t, err)
+	assert.Equal(t, "argocd.prod.example.com:443", ctx.Server)
+
+	// Returned contexts are copies
+	ctx.Server = "changed"
+	again, err := cfg.Context("prod")
+	require.NoError(t, err)
+	assert.Equal(t, "argocd.prod.example.com:443", again.Server)
+
+	_, err = cfg.Context("missing")
+	assert.ErrorContains(t, err, "not defined")
+
+	cfg.CurrentContext = ""
+	_, err = cfg.Context("")
+	assert.ErrorContains(t, err, "multiple contexts")
+
+	empty := &Config{}
+	ctx, err = empty.Context("")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultContextName, ctx.Name)
+}
+
+func TestContext_ClientConfig(t *testing.T) {
+	t.Setenv("PROD_ARGOCD_TOKEN", "prod-token")
+	tokenFile := writeFile(t, "token", "file-token\n")
+
+	tests := []struct {
+		name      string
+		ctx       Context
+		wantToken string
+		wantErr   bool
+	}{
+		{name: "inline token", ctx: Context{Name: "a", AuthToken: "inline"}, wantToken: "inline"},
+		{name: "token from env", ctx: Context{Name: "a", AuthTokenEnv: "PROD_ARGOCD_TOKEN"}, wantToken: "prod-token"},
+		{name: "token from file", ctx: Context{Name: "a", AuthTokenFile: tokenFile}, wantToken: "file-token"},
+		{name: "missing token file", ctx: Context{Name: "a", AuthTokenFile: tokenFile + ".missing"}, wantErr: true},
+		{name: "invalid timeout", ctx: Context{Name: "a", Timeout: "soon"}, wantErr: true},
+		{name: "client certificate without key", ctx: Context{Name: "a", AuthToken: "inline", ClientCertFile: tokenFile}, wantErr: true},
+		{name: "missing server certificate", ctx: Context{Name: "a", AuthToken: "inline", CertFile: tokenFile + ".missing"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientConfig, err := tt.ctx.ClientConfig()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantToken, clientConfig.AuthToken)
+		})
+	}
+
+	clientConfig, err := (&Context{Name: "a", Timeout: "45s", Headers: []string{"X-Team: platform"}, UserAgent: "agent"}).ClientConfig()
+	require.NoError(t, err)
+	assert.Equal(t, 45*time.Second, clientConfig.Timeout)
+	assert.Equal(t, []string{"X-Team: platform"}, clientConfig.Headers)
+	assert.Equal(t, "agent", clientConfig.UserAgent)
+}
+
+func TestConfig_ImportArgoCDConfig(t *testing.T) {
+	cfg, err := Load(writeFile(t, "config.yaml", testConfig))
+	require.NoError(t, err)
+	cfg.CurrentContext = ""
+
+	require.NoError(t, cfg.ImportArgoCDConfig(writeFile(t, "argocd", testArgoCDConfig)))
+
+	assert.Equal(t, []string{"prod", "staging", "cli-ctx"}, cfg.ContextNames())
+	assert.Equal(t, "cli-ctx", cfg.CurrentContext)
+
+	ctx, err := cfg.Context("cli-ctx")
+	require.NoError(t, err)
+	assert.Equal(t, "argocd.cli.example.com", ctx.Server)
+	assert.Equal(t, "cli-token", ctx.AuthToken)
+	assert.True(t, ctx.Insecure)
+
+	// Contexts from the config file win over imported ones
+	ctx, err = cfg.Context("staging")
+	require.NoError(t, err)
+	assert.Equal(t, "argocd.staging.example.com:443", ctx.Server)
+
+	// A missing argocd config is not an error
+	assert.NoError(t, cfg.ImportArgoCDConfig(filepath.Join(t.TempDir(), "missing")))
+}
+
+func TestResolve(t *testing.T) {
+	path := writeFile(t, "config.yaml", testConfig)
+
+	t.Run("config file only", func(t *testing.T) {
+		clearArgoCDEnv(t)
+		ctx, err := Resolve(Options{File: path})
+		require.NoError(t, err)
+		assert.Equal(t, "argocd.staging.example.com:443", ctx.Server)
+		assert.True(t, ctx.GRPCWeb)
+	})
+
+	t.Run("environment overrides config file", func(t *testing.T) {
+		clearArgoCDEnv(t)
+		t.Setenv("ARGOCD_SERVER", "env.example.com:443")
+		t.Setenv("ARGOCD_GRPC_WEB", "false")
+		ctx, err := Resolve(Options{File: path})
+		require.NoError(t, err)
+		assert.Equal(t, "env.example.com:443", ctx.Server)
+		assert.False(t, ctx.GRPCWeb)
+		assert.Equal(t, "staging-token", ctx.AuthToken)
+	})
+
+	t.Run("flags override environment", func(t *testing.T) {
+		clearArgoCDEnv(t)
+		t.Setenv("ARGOCD_SERVER", "env.example.com:443")
+
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		flags := BindFlags(fs)
+		require.NoError(t, fs.Parse([]string{"--server=flag.example.com:443", "--header", "X-A: 1", "--header", "X-B: 2", "--timeout=10s"}))
+
+		ctx, err := Resolve(Options{File: path, Context: "prod", Flags: flags})
+		require.NoError(t, err)
+		assert.Equal(t, "flag.example.com:443", ctx.Server)
+		assert.Equal(t, []string{"X-Team: platform", "X-A: 1", "X-B: 2"}, ctx.Headers)
+		assert.Equal(t, "10s", ctx.Timeout)
+		// Flags that were not set leave the context untouched
+		assert.Equal(t, "PROD_ARGOCD_TOKEN", ctx.AuthTokenEnv)
+	})
+
+	t.Run("environment only", func(t *testing.T) {
+		clearArgoCDEnv(t)
+		t.Setenv("ARGOCD_SERVER", "env.example.com:443")
+		t.Setenv("ARGOCD_AUTH_TOKEN", "env-token")
+		t.Setenv("ARGOCD_INSECURE", "true")
+
+		ctx, err := Resolve(Options{})
+		require.NoError(t, err)
+		assert.Equal(t, DefaultContextName, ctx.Name)
+		assert.Equal(t, "env.example.com:443", ctx.Server)
+		assert.Equal(t, "env-token", ctx.AuthToken)
+		assert.True(t, ctx.Insecure)
+	})
+}
+
+func TestResolveConfig(t *testing.T) {
+	clearArgoCDEnv(t)
+	t.Setenv("ARGOCD_SERVER", "env.example.com:443")
+
+	cfg, err := ResolveConfig(Options{File: writeFile(t, "config.yaml", testConfig+"readOnly: true\ndenyTools: [clusters]\n"), Context: "prod"})
+	require.NoError(t, err)
+	assert.Equal(t, "prod", cfg.CurrentContext)
+	assert.True(t, cfg.ReadOnly)
+	assert.Equal(t, []string{"clusters"}, cfg.DenyTools)
+
+	contexts := cfg.Contexts
+	require.Len(t, contexts, 2)
+
+	// The selected context comes first and carries the overrides
+	assert.Equal(t, "prod", contexts[0].Name)
+	assert.Equal(t, "env.example.com:443", contexts[0].Server)
+	assert.Equal(t, "staging", contexts[1].Name)
+	assert.Equal(t, "argocd.staging.example.com:443", contexts[1].Server)
+}
@@ -0,0 +1,33 @@
+package config
+
+import (
+	"os"
+	"strconv"
+)
+
+// ApplyEnv overrides context settings with the ARGOCD_* environment variables that are set
+func (c *Context) ApplyEnv() {
+	if v := os.Getenv("ARGOCD_SERVER"); v != "" {
+		c.Server = v
+	}
+	if v := os.Getenv("ARGOCD_AUTH_TOKEN"); v != "" {
+		c.AuthToken = v
+	}
+	setBoolFromEnv(&c.Insecure, "ARGOCD_INSECURE")
+	setBoolFromEnv(&c.PlainText, "ARGOCD_PLAINTEXT")
+	setBoolFromEnv(&c.GRPCWeb, "ARGOCD_GRPC_WEB")
+	if v := os.Getenv("ARGOCD_GRPC_WEB_ROOT_PATH"); v != "" {
+		c.GRPCWebRootPath = v
+	}
+}
+
+// setBoolFromEnv sets target from a boolean environment variable when it is set to a valid value
+func setBoolFromEnv(target *bool, envVar string) {
+	v := os.Getenv(envVar)
+	if v == "" {
+		return
+	}
+	if b, err := strconv.ParseBool(v); err == nil {
+		*target = b
+	}
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"flag"
+	"strings"
+)
+
+// Flags holds command-line overrides for the selected context.
+// Only flags that were set explicitly are applied.
+type Flags struct {
+	fs     *flag.FlagSet
+	values Context
+}
+
+// BindFlags registers the context override flags on fs
+func BindFlags(fs *flag.FlagSet) *Flags {
+	f := &Flags{fs: fs}
+	fs.StringVar(&f.values.Server, "server", "", "ArgoCD server address (overrides the context and ARGOCD_SERVER)")
+	fs.StringVar(&f.values.AuthTokenFile, "auth-token-file", "", "File containing the ArgoCD auth token")
+	fs.BoolVar(&f.values.Insecure, "insecure", false, "Skip TLS certificate verification")
+	fs.BoolVar(&f.values.PlainText, "plaintext", false, "Connect without TLS")
+	fs.BoolVar(&f.values.GRPCWeb, "grpc-web", false, "Connect using the gRPC-Web protocol")
+	fs.StringVar(&f.values.GRPCWebRootPath, "grpc-web-root-path", "", "Root path for gRPC-Web requests")
+	fs.StringVar(&f.values.CertFile, "server-crt", "", "CA certificate file used to verify the ArgoCD server")
+	fs.StringVar(&f.values.ClientCertFile, "client-crt", "", "Client certificate file")
+	fs.StringVar(&f.values.ClientCertKeyFile, "client-crt-key", "", "Client certificate key file")
+	fs.Var((*stringList)(&f.values.Headers), "header", "Additional header sent with every request as 'Name: value' (repeatable)")
+	fs.StringVar(&f.values.UserAgent, "user-agent", "", "User agent sent to the ArgoCD server")
+	fs.StringVar(&f.values.Timeout, "timeout", "", "Timeout for gRPC-Web HTTP requests (e.g. 30s)")
+	return f
+}
+
+// Apply overrides ctx with the flags that were set on the command line
+func (f *Flags) Apply(ctx *Context) {
+	f.fs.Visit(func(fl *flag.Flag) {
+		switch fl.Name {
+		case "server":
+			ctx.Server = f.values.Server
+		case "auth-token-file":
+			// An explicit token file replaces any other token source
+			ctx.AuthToken = ""
+			ctx.AuthTokenEnv = ""
+			ctx.AuthTokenFile = f.values.AuthTokenFile
+		case "insecure":
+			ctx.Insecure = f.values.Insecure
+		case "plaintext":
+			ctx.PlainText = f.values.PlainText
+		case "grpc-web":
+			ctx.GRPCWeb = f.values.GRPCWeb
+		case "grpc-web-root-path":
+			ctx.GRPCWebRootPath = f.values.GRPCWebRootPath
+		case "server-crt":
+			ctx.CertFile = f.values.CertFile
+		case "client-crt":
+			ctx.ClientCertFile = f.values.ClientCertFile
+		case "client-crt-key":
+			ctx.ClientCertKeyFile = f.values.ClientCertKeyFile
+		case "header":
+			ctx.Headers = append(ctx.Headers, f.values.Headers...)
+		case "user-agent":
+			ctx.UserAgent = f.values.UserAgent
+		case "timeout":
+			ctx.Timeout = f.values.Timeout
+		}
+	})
+}
+
+// stringList is a repeatable string flag
+type stringList []string
+
+func (s *stringList) String() string {
+	return strings.Join(*s, ", ")
+}
+
+func (s *stringList) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
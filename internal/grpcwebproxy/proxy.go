@@ -3,6 +3,7 @@ package grpcwebproxy
 import (
 	"bytes"
 	"compress/gzip"
+	"context"
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
@@ -15,6 +16,12 @@ import (
 	"sync"
 	"time"
 
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/metrics"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
@@ -54,7 +61,7 @@ func NewGRPCWebProxy(serverAddr string, plainText bool, httpClient *http.Client,
 }
 
 // executeRequest sends a gRPC-Web request and returns the response
-func (p *GRPCWebProxy) executeRequest(fullMethodName string, msg []byte, md metadata.MD) (*http.Response, error) {
+func (p *GRPCWebProxy) executeRequest(ctx context.Context, fullMethodName string, msg []byte, md metadata.MD) (resp *http.Response, err error) {
 	// Construct URL
 	schema := "https"
 	if p.plainText {
@@ -70,8 +77,19 @@ func (p *GRPCWebProxy) executeRequest(fullMethodName string, msg []byte, md meta
 
 	requestURL := fmt.Sprintf("%s://%s%s%s", schema, p.serverAddr, rootPath, fullMethodName)
 
-	// Create request with framed message
-	req, err := http.NewRequest(http.MethodPost, requestURL, bytes.NewReader(toFrame(msg)))
+	ctx, span := tracing.Tracer().Start(ctx, "POST "+fullMethodName,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", http.MethodPost),
+			attribute.String("url.full", requestURL),
+			attribute.String("rpc.method", fullMethodName),
+		),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	// Create request with framed message, bound to the context so that a
+	// cancelled call stops the HTTP request and the response stream
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, requestURL, bytes.NewReader(toFrame(msg)))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -98,11 +116,15 @@ func (p *GRPCWebProxy) executeRequest(fullMethodName string, msg []byte, md meta
 		}
 	}
 
+	// Propagate the trace context, replacing the one copied from the gRPC metadata
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
 	// Execute request
-	resp, err := p.httpClient.Do(req)
+	resp, err = p.httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute request: %w", err)
 	}
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
 
 	return resp, nil
 }
@@ -161,9 +183,15 @@ func (p *GRPCWebProxy) handleStream(stream grpc.ServerStream) error {
 		return err
 	}
 
+	// Continue the trace of the gRPC client, whose context arrives as metadata
+	ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+
 	// Execute gRPC-Web request
-	resp, err := p.executeRequest(fullMethodName, msg, md)
+	resp, err := p.executeRequest(ctx, fullMethodName, msg, md)
 	if err != nil {
+		if ctx.Err() != nil {
+			return status.FromContextError(ctx.Err()).Err()
+		}
 		return status.Error(codes.Internal, err.Error())
 	}
 	defer func() { _ = resp.Body.Close() }()
@@ -199,21 +227,20 @@ func (p *GRPCWebProxy) handleStream(stream grpc.ServerStream) error {
 		return status.Error(codes.Code(code), grpcMessage)
 	}
 
-	// Read and parse response frames
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return status.Error(codes.Internal, fmt.Sprintf("failed to read response: %v", err))
-	}
-
-	// Send response frames back to client
-	reader := bytes.NewReader(respBody)
+	// Forward response frames as they arrive, so that streams deliver their
+	// messages before they end and stop as soon as the call is cancelled
+	body := &countingReader{r: resp.Body}
+	defer func() { metrics.ObserveProxyResponse(fullMethodName, body.n) }()
 	var trailerFrame []byte
 	for {
-		frame, isTrailer, err := parseFrame(reader)
+		frame, isTrailer, err := parseFrame(body)
 		if err != nil {
 			if err == io.EOF {
 				break
 			}
+			if ctx.Err() != nil {
+				return status.FromContextError(ctx.Err()).Err()
+			}
 			return status.Error(codes.Internal, fmt.Sprintf("failed to parse frame: %v", err))
 		}
 
@@ -254,10 +281,12 @@ func (p *GRPCWebProxy) UseProxy() (net.Addr, io.Closer, error) {
 		}
 		p.proxyServer = server
 		p.proxyListener = listener
+		metrics.ProxyStarted(p.serverAddr)
 	}
 
 	// Increment user count
 	p.proxyUsersCount++
+	metrics.AddProxyUsers(p.serverAddr, 1)
 
 	// Return closer that decrements count and stops proxy if no users
 	closer := &proxyCloser{
@@ -277,15 +306,51 @@ func (c *proxyCloser) Close() error {
 	defer c.proxy.proxyMutex.Unlock()
 
 	c.proxy.proxyUsersCount--
+	metrics.AddProxyUsers(c.proxy.serverAddr, -1)
 	if c.proxy.proxyUsersCount == 0 && c.proxy.proxyServer != nil {
 		c.proxy.proxyServer.Stop()
 		c.proxy.proxyListener = nil
 		c.proxy.proxyServer = nil
+		metrics.ProxyStopped(c.proxy.serverAddr)
 	}
 
 	return nil
 }
 
+// countingReader counts the bytes read from a response body
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += n
+	return n, err
+}
+
+// metadataCarrier adapts incoming gRPC metadata to a propagation.TextMapCarrier
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	if values := metadata.MD(c).Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
 // parseGRPCTrailer parses the gRPC status from a trailer frame
 func parseGRPCTrailer(trailer []byte) error {
 	// Trailers are in HTTP/2 header format
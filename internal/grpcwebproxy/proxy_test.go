@@ -2,10 +2,19 @@ package grpcwebproxy
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/grpc/metadata"
 )
 
 func TestToFrame(t *testing.T) {
@@ -566,6 +575,95 @@ func TestHeaderRemovalInExecuteRequest(t *testing.T) {
 	}
 }
 
+func TestExecuteRequestPropagatesTraceContext(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	}()
+
+	var traceparent string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "parent")
+	proxy := NewGRPCWebProxy(strings.TrimPrefix(srv.URL, "http://"), true, srv.Client(), "", nil)
+	md := metadata.Pairs("traceparent", "00-00000000000000000000000000000001-0000000000000001-01")
+	resp, err := proxy.executeRequest(ctx, "/application.ApplicationService/Get", nil, md)
+	if err != nil {
+		t.Fatalf("executeRequest() error = %v", err)
+	}
+	_ = resp.Body.Close()
+	parent.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+	httpSpan := spans[0]
+	if httpSpan.Name() != "POST /application.ApplicationService/Get" {
+		t.Errorf("span name = %q", httpSpan.Name())
+	}
+	if httpSpan.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Errorf("HTTP span is not a child of the caller span")
+	}
+	want := fmt.Sprintf("00-%s-%s-01", httpSpan.SpanContext().TraceID(), httpSpan.SpanContext().SpanID())
+	if traceparent != want {
+		t.Errorf("traceparent = %q, want %q", traceparent, want)
+	}
+}
+
+func TestExecuteRequestHonorsCancellation(t *testing.T) {
+	// The server streams one message and then holds the response open
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(toFrame([]byte("first")))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	proxy := NewGRPCWebProxy(strings.TrimPrefix(srv.URL, "http://"), true, srv.Client(), "", nil)
+	resp, err := proxy.executeRequest(ctx, "/application.ApplicationService/PodLogs", nil, nil)
+	if err != nil {
+		t.Fatalf("executeRequest() error = %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	// Messages arrive before the stream ends
+	frame, _, err := parseFrame(resp.Body)
+	if err != nil || string(frame) != "first" {
+		t.Fatalf("parseFrame() = %q, %v", frame, err)
+	}
+
+	cancel()
+	done := make(chan error, 1)
+	go func() {
+		_, _, err := parseFrame(resp.Body)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("reading a cancelled stream succeeded")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("cancelling the context did not stop the response stream")
+	}
+
+	if _, err := proxy.executeRequest(ctx, "/application.ApplicationService/Get", nil, nil); err == nil {
+		t.Error("executeRequest() with a cancelled context succeeded")
+	}
+}
+
 func parseHeaders(headerStrings []string) (http.Header, error) {
 	headers := http.Header{}
 	for _, kv := range headerStrings {
@@ -0,0 +1,260 @@
+// Package metrics exposes Prometheus metrics about tool calls, ArgoCD RPCs and
+// the gRPC-Web proxy.
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Path is the HTTP path metrics are served on
+const Path = "/metrics"
+
+const namespace = "argocd_mcp"
+
+// Outcomes of a tool call
+const (
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+)
+
+// Error types of failed tool calls, as reported with SetErrorType. Errors
+// reported by ArgoCD are typed by their gRPC status code instead, for example
+// not_found or permission_denied.
+const (
+	ErrorTypeInternal     = "internal"
+	ErrorTypeInvalid      = "invalid_argument"
+	ErrorTypeConnection   = "connection"
+	ErrorTypePolicy       = "policy"
+	ErrorTypeApproval     = "approval"
+	ErrorTypeConfirmation = "confirmation"
+	ErrorTypeOther        = "other"
+)
+
+// payloadBuckets range from 256B to 16MiB
+var payloadBuckets = prometheus.ExponentialBuckets(256, 4, 9)
+
+var (
+	registry = prometheus.NewRegistry()
+
+	toolCalls = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tool_calls_total",
+		Help:      "Tool calls by tool and outcome.",
+	}, []string{"tool", "outcome"})
+
+	toolErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tool_errors_total",
+		Help:      "Failed tool calls by tool and error type.",
+	}, []string{"tool", "error_type"})
+
+	toolDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "tool_call_duration_seconds",
+		Help:      "Tool call latency by tool.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"tool"})
+
+	toolResponseSize = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "tool_response_size_bytes",
+		Help:      "Size of the text returned by tool calls.",
+		Buckets:   payloadBuckets,
+	}, []string{"tool"})
+
+	rpcDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "argocd_rpc_duration_seconds",
+		Help:      "Latency of ArgoCD gRPC calls by method and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "code"})
+
+	proxyUsers = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "grpcweb_proxy_active_users",
+		Help:      "Clients currently using the gRPC-Web proxy of a server.",
+	}, []string{"server"})
+
+	proxyStarts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "grpcweb_proxy_starts_total",
+		Help:      "Times the gRPC-Web proxy of a server was started.",
+	}, []string{"server"})
+
+	proxyStops = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "grpcweb_proxy_stops_total",
+		Help:      "Times the gRPC-Web proxy of a server was stopped.",
+	}, []string{"server"})
+
+	proxyResponseSize = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "grpcweb_response_size_bytes",
+		Help:      "Size of gRPC-Web response bodies by method.",
+		Buckets:   payloadBuckets,
+	}, []string{"method"})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		toolCalls,
+		toolErrors,
+		toolDuration,
+		toolResponseSize,
+		rpcDuration,
+		proxyUsers,
+		proxyStarts,
+		proxyStops,
+		proxyResponseSize,
+	)
+}
+
+// Handler serves the collected metrics in the Prometheus text format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// errorTypeKey is the context key of the error type a tool call reports
+type errorTypeKey struct{}
+
+// SetErrorType reports the type of the error a tool call is about to return.
+// It does nothing outside ToolMiddleware.
+func SetErrorType(ctx context.Context, errorType string) {
+	if reported, ok := ctx.Value(errorTypeKey{}).(*string); ok {
+		*reported = errorType
+	}
+}
+
+// ToolMiddleware records the outcome, latency and response size of every call.
+// It has the signature of tools.Middleware.
+func ToolMiddleware(tool mcp.Tool, next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		var reported string
+		result, err := next(context.WithValue(ctx, errorTypeKey{}, &reported), request)
+		toolDuration.WithLabelValues(tool.Name).Observe(time.Since(start).Seconds())
+
+		switch {
+		case err != nil:
+			toolCalls.WithLabelValues(tool.Name, OutcomeError).Inc()
+			toolErrors.WithLabelValues(tool.Name, ErrorTypeInternal).Inc()
+		case result != nil && result.IsError:
+			toolCalls.WithLabelValues(tool.Name, OutcomeError).Inc()
+			toolErrors.WithLabelValues(tool.Name, ErrorType(reported, resultText(result))).Inc()
+		default:
+			toolCalls.WithLabelValues(tool.Name, OutcomeSuccess).Inc()
+		}
+
+		if result != nil {
+			toolResponseSize.WithLabelValues(tool.Name).Observe(float64(len(resultText(result))))
+		}
+		return result, err
+	}
+}
+
+// grpcCodePattern finds the status code in an error formatted by the gRPC status package
+var grpcCodePattern = regexp.MustCompile(`rpc error: code = (\w+)`)
+
+// ErrorType classifies a failed tool call by the error type it reported, or
+// else by the gRPC status code of the ArgoCD error in its message
+func ErrorType(reported, message string) string {
+	if reported != "" {
+		return reported
+	}
+	if match := grpcCodePattern.FindStringSubmatch(message); match != nil {
+		return snakeCase(match[1])
+	}
+	return ErrorTypeOther
+}
+
+// UnaryClientInterceptor records the latency and status code of unary ArgoCD RPCs
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		ObserveRPC(method, err, time.Since(start))
+		return err
+	}
+}
+
+// StreamClientInterceptor records the latency and status code of establishing
+// streaming ArgoCD RPCs
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := time.Now()
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		ObserveRPC(method, err, time.Since(start))
+		return stream, err
+	}
+}
+
+// ObserveRPC records one ArgoCD RPC
+func ObserveRPC(method string, err error, duration time.Duration) {
+	rpcDuration.WithLabelValues(method, status.Code(err).String()).Observe(duration.Seconds())
+}
+
+// ProxyStarted records that the gRPC-Web proxy of server was started
+func ProxyStarted(server string) {
+	proxyStarts.WithLabelValues(server).Inc()
+}
+
+// ProxyStopped records that the gRPC-Web proxy of server was stopped
+func ProxyStopped(server string) {
+	proxyStops.WithLabelValues(server).Inc()
+}
+
+// AddProxyUsers records delta clients starting (or, when negative, stopping) to
+// use the gRPC-Web proxy of server
+func AddProxyUsers(server string, delta int) {
+	proxyUsers.WithLabelValues(server).Add(float64(delta))
+}
+
+// ObserveProxyResponse records the size of a gRPC-Web response body
+func ObserveProxyResponse(method string, size int) {
+	proxyResponseSize.WithLabelValues(method).Observe(float64(size))
+}
+
+// resultText joins the text content of a tool result
+func resultText(result *mcp.CallToolResult) string {
+	var sb strings.Builder
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			sb.WriteString(text.Text)
+		}
+	}
+	return sb.String()
+}
+
+// snakeCase converts a gRPC code name such as NotFound to not_found
+func snakeCase(name string) string {
+	if name == codes.OK.String() {
+		return "ok"
+	}
+	var sb strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				sb.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
@@ -0,0 +1,104 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestErrorType(t *testing.T) {
+	tests := []struct {
+		name     string
+		reported string
+		message  string
+		want     string
+	}{
+		{name: "grpc code", message: "Failed to get application: rpc error: code = NotFound desc = app not found", want: "not_found"},
+		{name: "grpc code in two words", message: "Failed to sync application: rpc error: code = PermissionDenied desc = denied", want: "permission_denied"},
+		{name: "reported", reported: ErrorTypePolicy, message: `Policy rule "no-prod" denied delete_application: nope`, want: ErrorTypePolicy},
+		{name: "reported over grpc code", reported: ErrorTypeConnection, message: "Failed to create gRPC client: rpc error: code = Unavailable desc = down", want: ErrorTypeConnection},
+		{name: "message text is not classified", message: "Application name is required", want: ErrorTypeOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ErrorType(tt.reported, tt.message))
+		})
+	}
+}
+
+func TestToolMiddleware(t *testing.T) {
+	tool := mcp.NewTool("metrics_test_tool")
+	results := []*mcp.CallToolResult{
+		mcp.NewToolResultText("hello"),
+		mcp.NewToolResultError("Failed to get application: rpc error: code = NotFound desc = missing"),
+	}
+	handler := ToolMiddleware(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result := results[0]
+		results = results[1:]
+		return result, nil
+	})
+
+	for range 2 {
+		_, err := handler(context.Background(), mcp.CallToolRequest{})
+		require.NoError(t, err)
+	}
+
+	invalid := ToolMiddleware(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		SetErrorType(ctx, ErrorTypeInvalid)
+		return mcp.NewToolResultError("Application name is required"), nil
+	})
+	_, err := invalid(context.Background(), mcp.CallToolRequest{})
+	require.NoError(t, err)
+
+	failing := ToolMiddleware(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return nil, errors.New("boom")
+	})
+	_, err = failing(context.Background(), mcp.CallToolRequest{})
+	require.Error(t, err)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(toolCalls.WithLabelValues(tool.Name, OutcomeSuccess)))
+	assert.Equal(t, 3.0, testutil.ToFloat64(toolCalls.WithLabelValues(tool.Name, OutcomeError)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(toolErrors.WithLabelValues(tool.Name, "not_found")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(toolErrors.WithLabelValues(tool.Name, ErrorTypeInvalid)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(toolErrors.WithLabelValues(tool.Name, ErrorTypeInternal)))
+	assert.Equal(t, 1, testutil.CollectAndCount(toolDuration, namespace+"_tool_call_duration_seconds"))
+}
+
+func TestUnaryClientInterceptor(t *testing.T) {
+	interceptor := UnaryClientInterceptor()
+	invoker := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		return status.Error(codes.Unavailable, "down")
+	}
+
+	err := interceptor(context.Background(), "/test.Service/Method", nil, nil, nil, invoker)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, Path, nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `argocd_mcp_argocd_rpc_duration_seconds_count{code="Unavailable",method="/test.Service/Method"} 1`)
+}
+
+func TestProxyMetrics(t *testing.T) {
+	const server = "proxy-metrics-test:443"
+
+	ProxyStarted(server)
+	AddProxyUsers(server, 1)
+	AddProxyUsers(server, 1)
+	AddProxyUsers(server, -1)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(proxyStarts.WithLabelValues(server)))
+	assert.Equal(t, 0.0, testutil.ToFloat64(proxyStops.WithLabelValues(server)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(proxyUsers.WithLabelValues(server)))
+}
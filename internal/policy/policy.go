@@ -0,0 +1,233 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/google/cel-go/cel"
+	"sigs.k8s.io/yaml"
+)
+
+// Rule denies a tool call when its CEL expression evaluates to true.
+//
+// The expression can use the following variables:
+//   - tool: the tool name
+//   - instance: the ArgoCD instance the call targets
+//   - args: the tool arguments
+//   - app: the Application the call acts on (empty when there is none)
+//   - proposed: the Application as update_application or patch_application would leave it (empty for other tools)
+//   - project: the AppProject of the application, or the project being created in (empty when there is none)
+//   - appset: the ApplicationSet the call acts on (empty when there is none)
+type Rule struct {
+	// Name identifies the rule in denial messages
+	Name string `json:"name"`
+	// Description explains the rule; it is shown when Message is empty
+	Description string `json:"description,omitempty"`
+	// Tools limits the rule to the named tools; an empty list applies it to every mutating tool
+	Tools []string `json:"tools,omitempty"`
+	// Deny is a CEL expression that denies the call when it evaluates to true
+	Deny string `json:"deny"`
+	// Message is returned to the caller when the rule denies a call
+	Message string `json:"message,omitempty"`
+}
+
+// File is the declarative rules file
+type File struct {
+	Rules []Rule `json:"rules"`
+}
+
+// Input is what a rule is evaluated against
+type Input struct {
+	Tool           string
+	Instance       string
+	Arguments      map[string]any
+	Application    *v1alpha1.Application
+	Proposed       *v1alpha1.Application
+	Project        *v1alpha1.AppProject
+	ApplicationSet *v1alpha1.ApplicationSet
+}
+
+// DeniedError is returned when a rule denies a tool call
+type DeniedError struct {
+	Rule    string
+	Message string
+}
+
+// Error implements the error interface
+func (e *DeniedError) Error() string {
+	return fmt.Sprintf("denied by policy rule %q: %s", e.Rule, e.Message)
+}
+
+// Engine evaluates compiled rules in order; the first rule that matches denies the call
+type Engine struct {
+	rules []compiledRule
+}
+
+type compiledRule struct {
+	Rule
+	program cel.Program
+}
+
+// Load reads and compiles a YAML rules file
+func Load(path string) (*Engine, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy file: %w", err)
+	}
+
+	var file File
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse policy file %s: %w", path, err)
+	}
+
+	engine, err := New(file.Rules)
+	if err != nil {
+		return nil, fmt.Errorf("invalid policy file %s: %w", path, err)
+	}
+	return engine, nil
+}
+
+// New compiles rules into an Engine
+func New(rules []Rule) (*Engine, error) {
+	env, err := cel.NewEnv(
+		cel.Variable("tool", cel.StringType),
+		cel.Variable("instance", cel.StringType),
+		cel.Variable("args", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("app", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("proposed", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("project", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("appset", cel.MapType(cel.StringType, cel.DynType)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CEL environment: %w", err)
+	}
+
+	engine := &Engine{}
+	seen := make(map[string]bool, len(rules))
+	for i, rule := range rules {
+		if rule.Name == "" {
+			return nil, fmt.Errorf("rule #%d has no name", i+1)
+		}
+		if seen[rule.Name] {
+			return nil, fmt.Errorf("rule %q is defined more than once", rule.Name)
+		}
+		seen[rule.Name] = true
+
+		if rule.Deny == "" {
+			return nil, fmt.Errorf("rule %q has no deny expression", rule.Name)
+		}
+		ast, issues := env.Compile(rule.Deny)
+		if issues != nil && issues.Err() != nil {
+			return nil, fmt.Errorf("rule %q: %w", rule.Name, issues.Err())
+		}
+		if ast.OutputType() != cel.BoolType && ast.OutputType() != cel.DynType {
+			return nil, fmt.Errorf("rule %q: deny expression must evaluate to a bool, not %s", rule.Name, ast.OutputType())
+		}
+		program, err := env.Program(ast)
+		if err != nil {
+			return nil, fmt.Errorf("rule %q: %w", rule.Name, err)
+		}
+		engine.rules = append(engine.rules, compiledRule{Rule: rule, program: program})
+	}
+	return engine, nil
+}
+
+// Rules returns the number of rules in the engine
+func (e *Engine) Rules() int {
+	return len(e.rules)
+}
+
+// Evaluate returns a *DeniedError when a rule denies the call. Rules that fail to
+// evaluate deny the call as well, so that a broken rule never lets a call through.
+func (e *Engine) Evaluate(in Input) error {
+	activation, err := in.activation()
+	if err != nil {
+		return err
+	}
+
+	for _, rule := range e.rules {
+		if len(rule.Tools) > 0 && !slices.Contains(rule.Tools, in.Tool) {
+			continue
+		}
+
+		out, _, err := rule.program.Eval(activation)
+		if err != nil {
+			return &DeniedError{Rule: rule.Name, Message: fmt.Sprintf("rule could not be evaluated: %v", err)}
+		}
+		deny, ok := out.Value().(bool)
+		if !ok {
+			return &DeniedError{Rule: rule.Name, Message: fmt.Sprintf("rule evaluated to %v instead of a bool", out.Value())}
+		}
+		if deny {
+			return &DeniedError{Rule: rule.Name, Message: rule.message()}
+		}
+	}
+	return nil
+}
+
+// message returns the text shown when the rule denies a call
+func (r compiledRule) message() string {
+	switch {
+	case r.Message != "":
+		return r.Message
+	case r.Description != "":
+		return r.Description
+	default:
+		return r.Deny
+	}
+}
+
+// activation converts the input into CEL variables
+func (in Input) activation() (map[string]any, error) {
+	app, err := toMap(in.Application)
+	if err != nil {
+		return nil, err
+	}
+	proposed, err := toMap(in.Proposed)
+	if err != nil {
+		return nil, err
+	}
+	project, err := toMap(in.Project)
+	if err != nil {
+		return nil, err
+	}
+	appset, err := toMap(in.ApplicationSet)
+	if err != nil {
+		return nil, err
+	}
+
+	args := in.Arguments
+	if args == nil {
+		args = map[string]any{}
+	}
+
+	return map[string]any{
+		"tool":     in.Tool,
+		"instance": in.Instance,
+		"args":     args,
+		"app":      app,
+		"proposed": proposed,
+		"project":  project,
+		"appset":   appset,
+	}, nil
+}
+
+// toMap converts an object to its JSON representation. Nil objects become an empty map.
+func toMap[T any](obj *T) (map[string]any, error) {
+	result := map[string]any{}
+	if obj == nil {
+		return result, nil
+	}
+
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert %T for policy evaluation: %w", obj, err)
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to convert %T for policy evaluation: %w", obj, err)
+	}
+	return result, nil
+}
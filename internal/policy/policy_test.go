@@ -0,0 +1,135 @@
+package policy
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testRules = `
+rules:
+  - name: sync-team-a-only
+    description: Only applications of project team-a may be synced
+    tools: [sync_application]
+    deny: app.spec.project != "team-a"
+  - name: no-prod-deletes
+    tools: [delete_application]
+    deny: app.spec.destination.server == "https://prod.example.com"
+    message: Applications deployed to the prod cluster cannot be deleted
+  - name: no-prune-kube-system
+    tools: [sync_application]
+    deny: '"prune" in args && args.prune == true && app.spec.destination.namespace == "kube-system"'
+`
+
+func newApp(project, server, namespace string) *v1alpha1.Application {
+	return &v1alpha1.Application{
+		Spec: v1alpha1.ApplicationSpec{
+			Project: project,
+			Destination: v1alpha1.ApplicationDestination{
+				Server:    server,
+				Namespace: namespace,
+			},
+		},
+	}
+}
+
+func TestEngine_Evaluate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(testRules), 0o600))
+	engine, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, 3, engine.Rules())
+
+	tests := []struct {
+		name        string
+		input       Input
+		wantRule    string
+		wantMessage string
+	}{
+		{
+			name:  "sync in allowed project",
+			input: Input{Tool: "sync_application", Application: newApp("team-a", "https://dev.example.com", "web")},
+		},
+		{
+			name:        "sync in another project",
+			input:       Input{Tool: "sync_application", Application: newApp("team-b", "https://dev.example.com", "web")},
+			wantRule:    "sync-team-a-only",
+			wantMessage: "Only applications of project team-a may be synced",
+		},
+		{
+			name:        "delete on prod",
+			input:       Input{Tool: "delete_application", Application: newApp("team-b", "https://prod.example.com", "web")},
+			wantRule:    "no-prod-deletes",
+			wantMessage: "Applications deployed to the prod cluster cannot be deleted",
+		},
+		{
+			name:  "delete elsewhere",
+			input: Input{Tool: "delete_application", Application: newApp("team-b", "https://dev.example.com", "web")},
+		},
+		{
+			name: "prune in kube-system",
+			input: Input{
+				Tool:        "sync_application",
+				Arguments:   map[string]any{"prune": true},
+				Application: newApp("team-a", "https://dev.example.com", "kube-system"),
+			},
+			wantRule: "no-prune-kube-system",
+		},
+		{
+			name:  "sync without prune in kube-system",
+			input: Input{Tool: "sync_application", Application: newApp("team-a", "https://dev.example.com", "kube-system")},
+		},
+		{
+			name:  "rules scoped to other tools",
+			input: Input{Tool: "create_project"},
+		},
+		{
+			name:     "rule that cannot be evaluated denies",
+			input:    Input{Tool: "sync_application"},
+			wantRule: "sync-team-a-only",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := engine.Evaluate(tt.input)
+			if tt.wantRule == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			var denied *DeniedError
+			require.True(t, errors.As(err, &denied), "expected a DeniedError, got %v", err)
+			assert.Equal(t, tt.wantRule, denied.Rule)
+			if tt.wantMessage != "" {
+				assert.Equal(t, tt.wantMessage, denied.Message)
+			}
+		})
+	}
+}
+
+func TestNew_InvalidRules(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules []Rule
+	}{
+		{name: "missing name", rules: []Rule{{Deny: "true"}}},
+		{name: "duplicate name", rules: []Rule{{Name: "a", Deny: "true"}, {Name: "a", Deny: "false"}}},
+		{name: "missing expression", rules: []Rule{{Name: "a"}}},
+		{name: "syntax error", rules: []Rule{{Name: "a", Deny: "app.spec.project =="}}},
+		{name: "unknown variable", rules: []Rule{{Name: "a", Deny: "cluster == 'prod'"}}},
+		{name: "not a bool", rules: []Rule{{Name: "a", Deny: "tool"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.rules)
+			assert.Error(t, err)
+		})
+	}
+}
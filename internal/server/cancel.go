@@ -0,0 +1,119 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	mcp_server "github.com/mark3labs/mcp-go/server"
+)
+
+// MethodNotificationCancelled is sent by clients to cancel a request
+const MethodNotificationCancelled = "notifications/cancelled"
+
+// requestIDKey carries the JSON-RPC id of a tool call from the hooks to the middleware
+const requestIDKey = "argocd-mcp/request-id"
+
+// ErrCancelled is the cause of the context of a tool call cancelled by the client
+var ErrCancelled = errors.New("request cancelled by the client")
+
+// callKey identifies a tool call within the server
+type callKey struct {
+	session string
+	id      string
+}
+
+// calls tracks the cancel functions of running tool calls
+type calls struct {
+	mu      sync.Mutex
+	cancels map[callKey]context.CancelCauseFunc
+}
+
+// addCancellationHooks records the JSON-RPC id of tool calls for cancellable
+func (s *Server) addCancellationHooks(hooks *mcp_server.Hooks) {
+	hooks.AddBeforeCallTool(func(ctx context.Context, id any, request *mcp.CallToolRequest) {
+		if requestID, ok := id.(mcp.RequestId); ok && requestID.IsNil() {
+			return
+		}
+		if request.Params.Meta == nil {
+			request.Params.Meta = &mcp.Meta{}
+		}
+		if request.Params.Meta.AdditionalFields == nil {
+			request.Params.Meta.AdditionalFields = make(map[string]any)
+		}
+		request.Params.Meta.AdditionalFields[requestIDKey] = requestIDString(id)
+	})
+}
+
+// cancellable runs tool calls in a context that notifications/cancelled cancels
+func (s *Server) cancellable(next mcp_server.ToolHandlerFunc) mcp_server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var id string
+		if meta := request.Params.Meta; meta != nil {
+			id, _ = meta.AdditionalFields[requestIDKey].(string)
+			delete(meta.AdditionalFields, requestIDKey)
+		}
+		if id == "" {
+			return next(ctx, request)
+		}
+
+		ctx, cancel := context.WithCancelCause(ctx)
+		key := callKey{session: sessionID(ctx), id: id}
+		s.calls.mu.Lock()
+		if s.calls.cancels == nil {
+			s.calls.cancels = make(map[callKey]context.CancelCauseFunc)
+		}
+		s.calls.cancels[key] = cancel
+		s.calls.mu.Unlock()
+		defer func() {
+			s.calls.mu.Lock()
+			delete(s.calls.cancels, key)
+			s.calls.mu.Unlock()
+			cancel(nil)
+		}()
+
+		return next(ctx, request)
+	}
+}
+
+// handleCancelled cancels the tool call named by a notifications/cancelled
+func (s *Server) handleCancelled(ctx context.Context, notification mcp.JSONRPCNotification) {
+	requestID, ok := notification.Params.AdditionalFields["requestId"]
+	if !ok || requestID == nil {
+		return
+	}
+	cause := ErrCancelled
+	if reason, _ := notification.Params.AdditionalFields["reason"].(string); reason != "" {
+		cause = fmt.Errorf("%w: %s", ErrCancelled, reason)
+	}
+
+	key := callKey{session: sessionID(ctx), id: requestIDString(requestID)}
+	s.calls.mu.Lock()
+	cancel := s.calls.cancels[key]
+	s.calls.mu.Unlock()
+	if cancel != nil {
+		cancel(cause)
+	}
+}
+
+// Cancelled reports whether ctx belongs to a tool call the client cancelled
+func Cancelled(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), ErrCancelled)
+}
+
+func sessionID(ctx context.Context) string {
+	if session := mcp_server.ClientSessionFromContext(ctx); session != nil {
+		return session.SessionID()
+	}
+	return ""
+}
+
+// requestIDString normalizes a JSON-RPC id, which is a string or a number
+func requestIDString(id any) string {
+	if requestID, ok := id.(mcp.RequestId); ok {
+		return requestID.String()
+	}
+	return mcp.NewRequestId(id).String()
+}
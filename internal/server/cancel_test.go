@@ -0,0 +1,72 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_Cancellation(t *testing.T) {
+	s := New()
+	started := make(chan struct{}, 2)
+	s.AddTool(mcp.NewTool("block"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// The request id stays internal to the server
+		assert.NotContains(t, request.Params.Meta.AdditionalFields, requestIDKey)
+		started <- struct{}{}
+		select {
+		case <-ctx.Done():
+			return mcp.NewToolResultText(fmt.Sprintf("cancelled=%t cause=%v", Cancelled(ctx), context.Cause(ctx))), nil
+		case <-time.After(5 * time.Second):
+			return mcp.NewToolResultText("finished"), nil
+		}
+	})
+
+	sessionA, sessionB := &stubSession{id: "a"}, &stubSession{id: "b"}
+	ctxA, ctxB := s.WithContext(context.Background(), sessionA), s.WithContext(context.Background(), sessionB)
+	require.NoError(t, s.RegisterSession(ctxA, sessionA))
+	require.NoError(t, s.RegisterSession(ctxB, sessionB))
+
+	call := func(ctx context.Context, id string) <-chan mcp.JSONRPCMessage {
+		response := make(chan mcp.JSONRPCMessage, 1)
+		go func() {
+			response <- s.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":`+id+`,"method":"tools/call","params":{"name":"block"}}`))
+		}()
+		<-started
+		return response
+	}
+	cancel := func(ctx context.Context, id string) {
+		assert.Nil(t, s.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":`+id+`,"reason":"user pressed stop"}}`)))
+	}
+	text := func(response <-chan mcp.JSONRPCMessage) string {
+		select {
+		case message := <-response:
+			require.IsType(t, mcp.JSONRPCResponse{}, message)
+			return message.(mcp.JSONRPCResponse).Result.(mcp.CallToolResult).Content[0].(mcp.TextContent).Text
+		case <-time.After(2 * time.Second):
+			t.Fatal("tool call was not cancelled")
+			return ""
+		}
+	}
+
+	responseA, responseB := call(ctxA, "1"), call(ctxB, `"1"`)
+
+	// Cancelling an unknown request, or the same id in another session, does nothing
+	cancel(ctxA, "99")
+	cancel(ctxB, "1")
+	cancel(ctxA, `"1"`)
+	select {
+	case <-responseA:
+		t.Fatal("the wrong tool call was cancelled")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	cancel(ctxA, "1")
+	assert.Equal(t, "cancelled=true cause=request cancelled by the client: user pressed stop", text(responseA))
+	cancel(ctxB, `"1"`)
+	assert.Contains(t, text(responseB), "cancelled=true")
+}
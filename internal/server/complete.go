@@ -0,0 +1,419 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/logging"
+)
+
+// The MCP server library neither answers completion/complete nor advertises the
+// completions capability. Transports therefore answer completion requests before
+// handing messages over, and add the capability to the initialize response on the
+// way out.
+
+// MethodCompletionComplete is the completion request of the MCP specification
+const MethodCompletionComplete = "completion/complete"
+
+// Types of the objects whose arguments can be completed. Tool references are an
+// extension of the MCP specification, which only defines prompts and resources.
+const (
+	CompletionRefPrompt   = "ref/prompt"
+	CompletionRefResource = "ref/resource"
+	CompletionRefTool     = "ref/tool"
+)
+
+// MaxCompletionValues is the maximum number of values in a completion result
+const MaxCompletionValues = 100
+
+// CompletionTimeout bounds how long a completion request may take
+const CompletionTimeout = 10 * time.Second
+
+// ErrCompletionsUnsupported is returned when no Completer has been set
+var ErrCompletionsUnsupported = errors.New("completions are not supported")
+
+// CompletionRef identifies the prompt, resource template or tool whose argument is completed
+type CompletionRef struct {
+	Type string `json:"type"`
+	// Name of the prompt or tool
+	Name string `json:"name,omitempty"`
+	// URI template of the resource
+	URI string `json:"uri,omitempty"`
+}
+
+// CompletionParams are the parameters of a completion/complete request
+type CompletionParams struct {
+	Ref      CompletionRef `json:"ref"`
+	Argument struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	} `json:"argument"`
+	// Context holds the arguments the client has already resolved
+	Context struct {
+		Arguments map[string]string `json:"arguments,omitempty"`
+	} `json:"context"`
+}
+
+// Completer suggests values for the arguments of prompts, resource templates and tools
+type Completer interface {
+	// Complete returns the suggested values, best match first
+	Complete(ctx context.Context, params CompletionParams) ([]string, error)
+}
+
+// SetCompleter sets the handler of completion/complete requests
+func (s *Server) SetCompleter(completer Completer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.completer = completer
+}
+
+func (s *Server) getCompleter() Completer {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.completer
+}
+
+// completionCall is a decoded completion request
+type completionCall struct {
+	ID     mcp.RequestId    `json:"id"`
+	Method string           `json:"method"`
+	Params CompletionParams `json:"params"`
+}
+
+// parseCompletion decodes message when it is a completion request
+func parseCompletion(message []byte) (*completionCall, bool) {
+	if !bytes.Contains(message, []byte(MethodCompletionComplete)) {
+		return nil, false
+	}
+	var call completionCall
+	if err := json.Unmarshal(message, &call); err != nil || call.Method != MethodCompletionComplete || call.ID.IsNil() {
+		return nil, false
+	}
+	return &call, true
+}
+
+// complete answers message when it is a completion request. It returns nil for
+// any other message, which must then be handed over to the MCP server.
+func (s *Server) complete(ctx context.Context, message []byte) []byte {
+	call, ok := parseCompletion(message)
+	if !ok {
+		return nil
+	}
+	return s.answerCompletion(ctx, call)
+}
+
+// answerCompletion runs the completer for call, for at most CompletionTimeout,
+// and returns the encoded response
+func (s *Server) answerCompletion(ctx context.Context, call *completionCall) []byte {
+	ctx, cancel := context.WithTimeout(ctx, CompletionTimeout)
+	defer cancel()
+
+	var response any
+	completer := s.getCompleter()
+	if completer == nil {
+		response = mcp.NewJSONRPCError(call.ID, mcp.METHOD_NOT_FOUND, ErrCompletionsUnsupported.Error(), nil)
+	} else if values, err := completer.Complete(ctx, call.Params); err != nil {
+		response = mcp.NewJSONRPCError(call.ID, mcp.INVALID_PARAMS, err.Error(), nil)
+	} else {
+		result := mcp.CompleteResult{}
+		result.Completion.Values = values
+		if result.Completion.Values == nil {
+			result.Completion.Values = []string{}
+		}
+		if len(values) > MaxCompletionValues {
+			result.Completion.Values = values[:MaxCompletionValues]
+			result.Completion.Total = len(values)
+			result.Completion.HasMore = true
+		}
+		response = mcp.JSONRPCResponse{JSONRPC: mcp.JSONRPC_VERSION, ID: call.ID, Result: result}
+	}
+
+	data, err := json.Marshal(response)
+	if err != nil {
+		logging.GetLogger().WithError(err).Error("Failed to encode completion response")
+		data, _ = json.Marshal(mcp.NewJSONRPCError(call.ID, mcp.INTERNAL_ERROR, "failed to encode completion response", nil))
+	}
+	return data
+}
+
+// advertiseCompletions adds the completions capability to an initialize response;
+// other messages are returned unchanged
+func (s *Server) advertiseCompletions(message []byte) []byte {
+	if s.getCompleter() == nil || !bytes.Contains(message, []byte(`"serverInfo"`)) || !bytes.Contains(message, []byte(`"protocolVersion"`)) {
+		return message
+	}
+	capabilities := []byte(`"capabilities":{`)
+	i := bytes.Index(message, capabilities)
+	if i < 0 || bytes.Contains(message, []byte(`"completions"`)) {
+		return message
+	}
+	i += len(capabilities)
+	insert := `"completions":{},`
+	if i < len(message) && message[i] == '}' {
+		insert = `"completions":{}`
+	}
+	return slices.Concat(message[:i], []byte(insert), message[i:])
+}
+
+// stdioWriter serializes the writes to stdout of the MCP server and of the
+// completion handler, and advertises completions in the initialize response
+type stdioWriter struct {
+	mu     sync.Mutex
+	server *Server
+	out    io.Writer
+}
+
+func (w *stdioWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := w.out.Write(w.server.advertiseCompletions(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// stdioReader rewrites the subscription requests of a newline-delimited stream
+// and answers its completion requests on out. Completions are answered in the
+// background, so that a slow one does not hold up the messages behind it.
+type stdioReader struct {
+	ctx      context.Context
+	server   *Server
+	reader   *bufio.Reader
+	out      *stdioWriter
+	pending  []byte
+	inflight sync.WaitGroup
+}
+
+func (s *Server) newStdioReader(ctx context.Context, r io.Reader, out *stdioWriter) *stdioReader {
+	return &stdioReader{ctx: ctx, server: s, reader: bufio.NewReader(r), out: out}
+}
+
+func (r *stdioReader) Read(p []byte) (int, error) {
+	for len(r.pending) == 0 {
+		line, err := r.reader.ReadBytes('\n')
+		if len(line) == 0 {
+			// Let the completions still running answer before the stream ends
+			r.inflight.Wait()
+			return 0, err
+		}
+		trimmed := bytes.TrimRight(line, "\r\n")
+		if call, ok := parseCompletion(trimmed); ok {
+			r.inflight.Add(1)
+			go func() {
+				defer r.inflight.Done()
+				response := r.server.answerCompletion(r.ctx, call)
+				if _, err := r.out.Write(append(response, '\n')); err != nil {
+					logging.GetLogger().WithError(err).Error("Failed to write completion response")
+				}
+			}()
+			continue
+		}
+		if rewritten := rewriteSubscription(trimmed); !bytes.Equal(rewritten, trimmed) {
+			line = append(rewritten, '\n')
+		}
+		r.pending = line
+	}
+
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+// streamableCompletions answers completion requests posted to the Streamable
+// HTTP transport and advertises completions in its initialize responses
+func (s *Server) streamableCompletions(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := peekBody(w, r)
+		if !ok {
+			return
+		}
+		if response := s.complete(r.Context(), body); response != nil {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write(response)
+			return
+		}
+		if bytes.Contains(body, []byte(`"`+string(mcp.MethodInitialize)+`"`)) {
+			w = &completionsResponseWriter{ResponseWriter: w, server: s}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// completionsResponseWriter advertises completions in the initialize response
+// written through it
+type completionsResponseWriter struct {
+	http.ResponseWriter
+	server *Server
+}
+
+func (w *completionsResponseWriter) WriteHeader(status int) {
+	// The body grows by the capability
+	w.Header().Del("Content-Length")
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *completionsResponseWriter) Write(p []byte) (int, error) {
+	if _, err := w.ResponseWriter.Write(w.server.advertiseCompletions(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (w *completionsResponseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// sseCompletions answers completion requests posted to the SSE transport on the
+// event stream of their session. Event streams are recorded as they open so that
+// responses can be written to them.
+type sseCompletions struct {
+	server *Server
+
+	mu      sync.Mutex
+	streams map[string]*sseStream
+}
+
+func newSSECompletions(s *Server) *sseCompletions {
+	return &sseCompletions{server: s, streams: make(map[string]*sseStream)}
+}
+
+// sseStream is the event stream of an SSE session
+type sseStream struct {
+	http.ResponseWriter
+	completions *sseCompletions
+
+	mu        sync.Mutex
+	sessionID string
+}
+
+// Write records the session announced by the endpoint event and advertises
+// completions in the initialize response
+func (s *sseStream) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sessionID == "" && bytes.HasPrefix(p, []byte("event: endpoint\n")) {
+		s.sessionID = sseSessionID(p)
+		s.completions.register(s.sessionID, s)
+	}
+	if _, err := s.ResponseWriter.Write(s.completions.server.advertiseCompletions(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Flush flushes the stream; send writes to it from other goroutines
+func (s *sseStream) Flush() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.flush()
+}
+
+// flush flushes the underlying writer; the caller holds s.mu
+func (s *sseStream) flush() {
+	if flusher, ok := s.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// send writes a message event to the stream
+func (s *sseStream) send(message []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, _ = fmt.Fprintf(s.ResponseWriter, "event: message\ndata: %s\n\n", message)
+	s.flush()
+}
+
+// sseSessionID extracts the sessionId query parameter from an endpoint event
+func sseSessionID(event []byte) string {
+	data := bytes.TrimPrefix(event, []byte("event: endpoint\ndata: "))
+	data = bytes.TrimSpace(data)
+	endpoint, err := url.Parse(string(data))
+	if err != nil {
+		return ""
+	}
+	return endpoint.Query().Get("sessionId")
+}
+
+func (c *sseCompletions) register(sessionID string, stream *sseStream) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.streams[sessionID] = stream
+}
+
+func (c *sseCompletions) stream(sessionID string) *sseStream {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.streams[sessionID]
+}
+
+// events wraps the handler of the event stream
+func (c *sseCompletions) events(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stream := &sseStream{ResponseWriter: w, completions: c}
+		defer func() {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			if c.streams[stream.sessionID] == stream {
+				delete(c.streams, stream.sessionID)
+			}
+		}()
+		next.ServeHTTP(stream, r)
+	})
+}
+
+// messages wraps the handler of posted messages
+func (c *sseCompletions) messages(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := peekBody(w, r)
+		if !ok {
+			return
+		}
+		if !bytes.Contains(body, []byte(MethodCompletionComplete)) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		stream := c.stream(r.URL.Query().Get("sessionId"))
+		if stream == nil {
+			// Let the MCP server report the unknown session
+			next.ServeHTTP(w, r)
+			return
+		}
+		response := c.server.complete(r.Context(), body)
+		if response == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		stream.send(response)
+	})
+}
+
+// peekBody reads the body of a POST request and puts it back for the next
+// handler. It reports false when the request has been answered with an error.
+func peekBody(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	if r.Method != http.MethodPost || r.Body == nil {
+		return nil, true
+	}
+	body, err := io.ReadAll(r.Body)
+	_ = r.Body.Close()
+	if err != nil {
+		http.Error(w, "failed to read request body", http.StatusBadRequest)
+		return nil, false
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	r.ContentLength = int64(len(body))
+	return body, true
+}
@@ -0,0 +1,244 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCompleter suggests the values starting with the argument value
+type fakeCompleter struct {
+	values []string
+	params []CompletionParams
+}
+
+func (c *fakeCompleter) Complete(ctx context.Context, params CompletionParams) ([]string, error) {
+	c.params = append(c.params, params)
+	if params.Argument.Name == "broken" {
+		return nil, errors.New("unknown argument broken")
+	}
+	var values []string
+	for _, value := range c.values {
+		if strings.HasPrefix(value, params.Argument.Value) {
+			values = append(values, value)
+		}
+	}
+	return values, nil
+}
+
+func completionRequest(id int, argument, value string) string {
+	return fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":"completion/complete","params":{"ref":{"type":"ref/prompt","name":"troubleshoot_application"},"argument":{"name":%q,"value":%q},"context":{"arguments":{"instance":"prod"}}}}`, id, argument, value)
+}
+
+// completionValues decodes the values of a completion response
+func completionValues(t *testing.T, response []byte) []string {
+	t.Helper()
+	var decoded struct {
+		Result mcp.CompleteResult `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	require.NoError(t, json.Unmarshal(response, &decoded), string(response))
+	require.Nil(t, decoded.Error, string(response))
+	return decoded.Result.Completion.Values
+}
+
+func TestServer_Complete(t *testing.T) {
+	s := New()
+
+	// Other messages are handed over to the MCP server
+	assert.Nil(t, s.complete(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"ping"}`)))
+	assert.Nil(t, s.complete(context.Background(), []byte(`{"jsonrpc":"2.0","method":"completion/complete"}`)))
+
+	response := s.complete(context.Background(), []byte(completionRequest(1, "name", "")))
+	assert.Contains(t, string(response), ErrCompletionsUnsupported.Error())
+	assert.Contains(t, string(response), fmt.Sprint(mcp.METHOD_NOT_FOUND))
+
+	completer := &fakeCompleter{values: []string{"guestbook", "guestbook-dev", "helm-guestbook"}}
+	s.SetCompleter(completer)
+
+	response = s.complete(context.Background(), []byte(completionRequest(2, "name", "guest")))
+	assert.Equal(t, []string{"guestbook", "guestbook-dev"}, completionValues(t, response))
+	require.Len(t, completer.params, 1)
+	assert.Equal(t, CompletionRef{Type: CompletionRefPrompt, Name: "troubleshoot_application"}, completer.params[0].Ref)
+	assert.Equal(t, "prod", completer.params[0].Context.Arguments["instance"])
+
+	// No match is an empty list rather than null
+	response = s.complete(context.Background(), []byte(completionRequest(3, "name", "zzz")))
+	assert.Contains(t, string(response), `"values":[]`)
+
+	response = s.complete(context.Background(), []byte(completionRequest(4, "broken", "")))
+	assert.Contains(t, string(response), "unknown argument broken")
+
+	// Results are capped
+	completer.values = make([]string, MaxCompletionValues+5)
+	for i := range completer.values {
+		completer.values[i] = fmt.Sprintf("app-%03d", i)
+	}
+	response = s.complete(context.Background(), []byte(completionRequest(5, "name", "")))
+	var decoded struct {
+		Result mcp.CompleteResult `json:"result"`
+	}
+	require.NoError(t, json.Unmarshal(response, &decoded))
+	assert.Len(t, decoded.Result.Completion.Values, MaxCompletionValues)
+	assert.Equal(t, MaxCompletionValues+5, decoded.Result.Completion.Total)
+	assert.True(t, decoded.Result.Completion.HasMore)
+}
+
+func TestServer_AdvertiseCompletions(t *testing.T) {
+	s := New()
+	initialize := []byte(`{"jsonrpc":"2.0","id":1,"result":{"protocolVersion":"2025-06-18","capabilities":{"tools":{}},"serverInfo":{"name":"argocd-mcp-server"}}}`)
+
+	// Not advertised without a completer
+	assert.Equal(t, initialize, s.advertiseCompletions(initialize))
+
+	s.SetCompleter(&fakeCompleter{})
+	assert.JSONEq(t,
+		`{"jsonrpc":"2.0","id":1,"result":{"protocolVersion":"2025-06-18","capabilities":{"completions":{},"tools":{}},"serverInfo":{"name":"argocd-mcp-server"}}}`,
+		string(s.advertiseCompletions(initialize)))
+	assert.JSONEq(t,
+		`{"result":{"protocolVersion":"2025-06-18","capabilities":{"completions":{}},"serverInfo":{}}}`,
+		string(s.advertiseCompletions([]byte(`{"result":{"protocolVersion":"2025-06-18","capabilities":{},"serverInfo":{}}}`))))
+
+	other := []byte(`{"jsonrpc":"2.0","id":2,"result":{"capabilities":{}}}`)
+	assert.Equal(t, other, s.advertiseCompletions(other))
+}
+
+func TestServer_StdioReader(t *testing.T) {
+	s := New()
+	s.SetCompleter(&fakeCompleter{values: []string{"guestbook"}})
+
+	input := `{"jsonrpc":"2.0","id":1,"method":"ping"}` + "\n" +
+		completionRequest(2, "name", "g") + "\n" +
+		`{"jsonrpc":"2.0","id":3,"method":"resources/subscribe","params":{"uri":"argocd://applications/a"}}` + "\n"
+
+	var out bytes.Buffer
+	forwarded, err := io.ReadAll(s.newStdioReader(context.Background(), strings.NewReader(input), &stdioWriter{server: s, out: &out}))
+	require.NoError(t, err)
+
+	// The completion is answered directly; other messages reach the MCP server
+	lines := strings.Split(strings.TrimSuffix(string(forwarded), "\n"), "\n")
+	require.Len(t, lines, 2)
+	assert.Equal(t, `{"jsonrpc":"2.0","id":1,"method":"ping"}`, lines[0])
+	assert.Contains(t, lines[1], `"method":"ping"`)
+	assert.Contains(t, lines[1], `"argocd-mcp/uri":"argocd://applications/a"`)
+
+	assert.True(t, strings.HasSuffix(out.String(), "\n"))
+	assert.Equal(t, []string{"guestbook"}, completionValues(t, out.Bytes()))
+}
+
+// blockingCompleter answers once it is released
+type blockingCompleter struct {
+	started  chan struct{}
+	release  chan struct{}
+	deadline bool
+}
+
+func (c *blockingCompleter) Complete(ctx context.Context, params CompletionParams) ([]string, error) {
+	_, c.deadline = ctx.Deadline()
+	close(c.started)
+	<-c.release
+	return []string{"guestbook"}, nil
+}
+
+func TestServer_StdioReaderSlowCompletion(t *testing.T) {
+	s := New()
+	completer := &blockingCompleter{started: make(chan struct{}), release: make(chan struct{})}
+	s.SetCompleter(completer)
+
+	input := completionRequest(1, "name", "g") + "\n" + `{"jsonrpc":"2.0","id":2,"method":"ping"}` + "\n"
+	var out bytes.Buffer
+	reader := bufio.NewReader(s.newStdioReader(context.Background(), strings.NewReader(input), &stdioWriter{server: s, out: &out}))
+
+	// The message behind the completion is handed over while the completion runs
+	line, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, `{"jsonrpc":"2.0","id":2,"method":"ping"}`+"\n", line)
+	<-completer.started
+	assert.True(t, completer.deadline, "completions run with a timeout")
+
+	close(completer.release)
+	_, err = reader.ReadString('\n')
+	assert.ErrorIs(t, err, io.EOF)
+	// The stream ends only once the completion has been answered
+	assert.Equal(t, []string{"guestbook"}, completionValues(t, out.Bytes()))
+}
+
+func TestServer_StreamableHTTPCompletions(t *testing.T) {
+	s := New()
+	s.SetCompleter(&fakeCompleter{values: []string{"guestbook"}})
+	server := httptest.NewServer(s.httpHandler(ServeOptions{Transport: TransportHTTP}))
+	defer server.Close()
+
+	post := func(body string) []byte {
+		request, err := http.NewRequest(http.MethodPost, server.URL+StreamableHTTPPath, strings.NewReader(body))
+		require.NoError(t, err)
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set("Accept", "application/json, text/event-stream")
+		response, err := http.DefaultClient.Do(request)
+		require.NoError(t, err)
+		defer func() { _ = response.Body.Close() }()
+		require.Equal(t, http.StatusOK, response.StatusCode)
+		data, err := io.ReadAll(response.Body)
+		require.NoError(t, err)
+		return data
+	}
+
+	initialize := post(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1.0.0"}}}`)
+	var decoded struct {
+		Result mcp.InitializeResult `json:"result"`
+	}
+	require.NoError(t, json.Unmarshal(initialize, &decoded), string(initialize))
+	assert.Contains(t, string(initialize), `"completions":{}`)
+	assert.Equal(t, "argocd-mcp-server", decoded.Result.ServerInfo.Name)
+
+	assert.Equal(t, []string{"guestbook"}, completionValues(t, post(completionRequest(2, "name", "g"))))
+}
+
+func TestServer_SSECompletions(t *testing.T) {
+	s := New()
+	s.SetCompleter(&fakeCompleter{values: []string{"guestbook"}})
+	server := httptest.NewServer(s.httpHandler(ServeOptions{Transport: TransportSSE}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/sse", nil)
+	require.NoError(t, err)
+	stream, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	defer func() { _ = stream.Body.Close() }()
+
+	events := bufio.NewReader(stream.Body)
+	readData := func() string {
+		for {
+			line, err := events.ReadString('\n')
+			require.NoError(t, err)
+			if data, ok := strings.CutPrefix(line, "data: "); ok {
+				return strings.TrimSpace(data)
+			}
+		}
+	}
+
+	endpoint := readData()
+	response, err := http.Post(server.URL+endpoint, "application/json", strings.NewReader(completionRequest(1, "name", "g")))
+	require.NoError(t, err)
+	_ = response.Body.Close()
+	assert.Equal(t, http.StatusAccepted, response.StatusCode)
+
+	assert.Equal(t, []string{"guestbook"}, completionValues(t, []byte(readData())))
+}
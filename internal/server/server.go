@@ -1,16 +1,95 @@
 package server
 
 import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
 	mcp_server "github.com/mark3labs/mcp-go/server"
 )
 
+// ErrShuttingDown is returned when a tool call arrives while the server is draining
+var ErrShuttingDown = errors.New("server is shutting down")
+
+// Server wraps the MCP server and tracks in-flight tool calls so that
+// shutdown can wait for them to finish regardless of the transport in use
+type Server struct {
+	*mcp_server.MCPServer
+
+	mu         sync.RWMutex
+	draining   bool
+	inflight   sync.WaitGroup
+	subscriber Subscriber
+	completer  Completer
+	calls      calls
+}
+
 // New creates and returns a new MCP server instance
-func New() *mcp_server.MCPServer {
-	s := mcp_server.NewMCPServer(
+func New() *Server {
+	s := &Server{}
+	hooks := s.subscriptionHooks()
+	s.addCancellationHooks(hooks)
+	s.MCPServer = mcp_server.NewMCPServer(
 		"argocd-mcp-server",
 		"1.0.0",
 		// Add recovery middleware to protect server from panics in handlers
 		mcp_server.WithRecovery(),
+		// Track in-flight tool calls so shutdown can drain them
+		mcp_server.WithToolHandlerMiddleware(s.trackInFlight),
+		// Cancel tool calls on notifications/cancelled
+		mcp_server.WithToolHandlerMiddleware(s.cancellable),
+		// Serve resources/subscribe, which the transports rewrite into pings
+		mcp_server.WithResourceCapabilities(true, false),
+		mcp_server.WithHooks(hooks),
 	)
+	s.AddNotificationHandler(MethodNotificationCancelled, s.handleCancelled)
 	return s
 }
+
+// trackInFlight counts running tool calls and refuses new ones while draining
+func (s *Server) trackInFlight(next mcp_server.ToolHandlerFunc) mcp_server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		s.mu.RLock()
+		if s.draining {
+			s.mu.RUnlock()
+			return mcp.NewToolResultError(ErrShuttingDown.Error()), nil
+		}
+		s.inflight.Add(1)
+		s.mu.RUnlock()
+		defer s.inflight.Done()
+
+		return next(ctx, request)
+	}
+}
+
+// Ready reports whether the server is accepting new tool calls
+func (s *Server) Ready() error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.draining {
+		return ErrShuttingDown
+	}
+	return nil
+}
+
+// Drain stops accepting new tool calls and waits for in-flight calls to complete
+// or for the context to be done, whichever happens first
+func (s *Server) Drain(ctx context.Context) error {
+	s.mu.Lock()
+	s.draining = true
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.inflight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
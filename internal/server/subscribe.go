@@ -0,0 +1,162 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	mcp_server "github.com/mark3labs/mcp-go/server"
+)
+
+// The MCP server library answers resources/subscribe and resources/unsubscribe
+// with "method not found". Transports therefore rewrite these requests into pings
+// carrying the original method and URI in _meta before handing them over; the
+// request hook below performs the (un)subscription and either fails the request
+// or lets the ping return the empty result both methods expect.
+
+// Subscription requests of the MCP specification
+const (
+	MethodResourcesSubscribe   = "resources/subscribe"
+	MethodResourcesUnsubscribe = "resources/unsubscribe"
+)
+
+// Keys of the _meta of a rewritten subscription request
+const (
+	subscriptionMethodKey = "argocd-mcp/method"
+	subscriptionURIKey    = "argocd-mcp/uri"
+)
+
+// ErrSubscriptionsUnsupported is returned when no Subscriber has been set
+var ErrSubscriptionsUnsupported = errors.New("resource subscriptions are not supported")
+
+// Subscriber handles resource subscriptions of client sessions. The session is
+// available from the context through server.ClientSessionFromContext.
+type Subscriber interface {
+	// Subscribe starts sending notifications/resources/updated for uri to the session
+	Subscribe(ctx context.Context, uri string) error
+	// Unsubscribe stops the notifications for uri
+	Unsubscribe(ctx context.Context, uri string) error
+	// UnsubscribeSession drops all subscriptions of a session that has ended
+	UnsubscribeSession(sessionID string)
+}
+
+// SetSubscriber sets the handler of resources/subscribe and resources/unsubscribe requests
+func (s *Server) SetSubscriber(subscriber Subscriber) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subscriber = subscriber
+}
+
+func (s *Server) getSubscriber() Subscriber {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.subscriber
+}
+
+// subscriptionHooks returns the hooks serving rewritten subscription requests
+func (s *Server) subscriptionHooks() *mcp_server.Hooks {
+	hooks := &mcp_server.Hooks{}
+	hooks.AddOnRequestInitialization(s.handleSubscription)
+	hooks.AddOnUnregisterSession(func(ctx context.Context, session mcp_server.ClientSession) {
+		if subscriber := s.getSubscriber(); subscriber != nil {
+			subscriber.UnsubscribeSession(session.SessionID())
+		}
+	})
+	return hooks
+}
+
+// handleSubscription (un)subscribes when message is a rewritten subscription request
+func (s *Server) handleSubscription(ctx context.Context, _ any, message any) error {
+	raw, ok := message.(json.RawMessage)
+	if !ok || !bytes.Contains(raw, []byte(subscriptionMethodKey)) {
+		return nil
+	}
+
+	var request struct {
+		Method string `json:"method"`
+		Params struct {
+			Meta map[string]string `json:"_meta"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal(raw, &request); err != nil || request.Method != string(mcp.MethodPing) {
+		return nil
+	}
+	method, uri := request.Params.Meta[subscriptionMethodKey], request.Params.Meta[subscriptionURIKey]
+	if method == "" {
+		return nil
+	}
+
+	subscriber := s.getSubscriber()
+	if subscriber == nil {
+		return ErrSubscriptionsUnsupported
+	}
+	if uri == "" {
+		return fmt.Errorf("%s requires a uri", method)
+	}
+	if method == MethodResourcesUnsubscribe {
+		return subscriber.Unsubscribe(ctx, uri)
+	}
+	return subscriber.Subscribe(ctx, uri)
+}
+
+// rewriteSubscription turns a subscription request into a ping the MCP server
+// library can answer; other messages are returned unchanged
+func rewriteSubscription(message []byte) []byte {
+	if !bytes.Contains(message, []byte(MethodResourcesSubscribe)) && !bytes.Contains(message, []byte(MethodResourcesUnsubscribe)) {
+		return message
+	}
+
+	var request struct {
+		JSONRPC string          `json:"jsonrpc"`
+		ID      json.RawMessage `json:"id"`
+		Method  string          `json:"method"`
+		Params  struct {
+			URI string `json:"uri"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal(message, &request); err != nil {
+		return message
+	}
+	if request.Method != MethodResourcesSubscribe && request.Method != MethodResourcesUnsubscribe {
+		return message
+	}
+
+	rewritten, err := json.Marshal(map[string]any{
+		"jsonrpc": request.JSONRPC,
+		"id":      request.ID,
+		"method":  mcp.MethodPing,
+		"params": map[string]any{
+			"_meta": map[string]string{
+				subscriptionMethodKey: request.Method,
+				subscriptionURIKey:    request.Params.URI,
+			},
+		},
+	})
+	if err != nil {
+		return message
+	}
+	return rewritten
+}
+
+// rewriteSubscriptions rewrites subscription requests posted to an HTTP transport
+func rewriteSubscriptions(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := peekBody(w, r)
+		if !ok {
+			return
+		}
+		if body == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		body = rewriteSubscription(body)
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		r.ContentLength = int64(len(body))
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,119 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	mcp_server "github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSubscriber records subscriptions and fails for URIs it does not know
+type fakeSubscriber struct {
+	subscribed   []string
+	unsubscribed []string
+	ended        []string
+}
+
+func (s *fakeSubscriber) Subscribe(ctx context.Context, uri string) error {
+	if !strings.HasPrefix(uri, "argocd://applications/") {
+		return errors.New("unsupported uri")
+	}
+	s.subscribed = append(s.subscribed, uri)
+	return nil
+}
+
+func (s *fakeSubscriber) Unsubscribe(ctx context.Context, uri string) error {
+	s.unsubscribed = append(s.unsubscribed, uri)
+	return nil
+}
+
+func (s *fakeSubscriber) UnsubscribeSession(sessionID string) {
+	s.ended = append(s.ended, sessionID)
+}
+
+func TestRewriteSubscription(t *testing.T) {
+	subscribe := `{"jsonrpc":"2.0","id":7,"method":"resources/subscribe","params":{"uri":"argocd://applications/guestbook"}}`
+	assert.JSONEq(t,
+		`{"jsonrpc":"2.0","id":7,"method":"ping","params":{"_meta":{"argocd-mcp/method":"resources/subscribe","argocd-mcp/uri":"argocd://applications/guestbook"}}}`,
+		string(rewriteSubscription([]byte(subscribe))))
+
+	// Other messages, including ones that merely mention the method, are left alone
+	for _, message := range []string{
+		`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"x","arguments":{"q":"resources/subscribe"}}}`,
+		`not json resources/subscribe`,
+	} {
+		assert.Equal(t, message, string(rewriteSubscription([]byte(message))))
+	}
+}
+
+func TestServer_Subscriptions(t *testing.T) {
+	s := New()
+	subscriber := &fakeSubscriber{}
+
+	handle := func(message string) mcp.JSONRPCMessage {
+		return s.HandleMessage(context.Background(), rewriteSubscription([]byte(message)))
+	}
+
+	// Without a subscriber, subscriptions fail
+	response := handle(`{"jsonrpc":"2.0","id":1,"method":"resources/subscribe","params":{"uri":"argocd://applications/a"}}`)
+	require.IsType(t, mcp.JSONRPCError{}, response)
+	assert.Contains(t, response.(mcp.JSONRPCError).Error.Message, ErrSubscriptionsUnsupported.Error())
+
+	s.SetSubscriber(subscriber)
+	response = handle(`{"jsonrpc":"2.0","id":2,"method":"resources/subscribe","params":{"uri":"argocd://applications/a"}}`)
+	require.IsType(t, mcp.JSONRPCResponse{}, response)
+	assert.EqualValues(t, 2, response.(mcp.JSONRPCResponse).ID.Value())
+
+	response = handle(`{"jsonrpc":"2.0","id":3,"method":"resources/subscribe","params":{"uri":"argocd://projects/p"}}`)
+	require.IsType(t, mcp.JSONRPCError{}, response)
+	assert.Contains(t, response.(mcp.JSONRPCError).Error.Message, "unsupported uri")
+
+	response = handle(`{"jsonrpc":"2.0","id":4,"method":"resources/unsubscribe","params":{"uri":"argocd://applications/a"}}`)
+	require.IsType(t, mcp.JSONRPCResponse{}, response)
+
+	assert.Equal(t, []string{"argocd://applications/a"}, subscriber.subscribed)
+	assert.Equal(t, []string{"argocd://applications/a"}, subscriber.unsubscribed)
+
+	// Ending a session drops its subscriptions
+	session := &stubSession{id: "gone"}
+	require.NoError(t, s.RegisterSession(context.Background(), session))
+	s.UnregisterSession(context.Background(), session.SessionID())
+	assert.Equal(t, []string{"gone"}, subscriber.ended)
+}
+
+func TestRewriteSubscriptions_HTTP(t *testing.T) {
+	var body string
+	handler := rewriteSubscriptions(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+	}))
+
+	request := httptest.NewRequest(http.MethodPost, StreamableHTTPPath,
+		strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"resources/unsubscribe","params":{"uri":"argocd://applications/a"}}`))
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+	assert.Contains(t, body, `"method":"ping"`)
+	assert.Contains(t, body, `"argocd-mcp/method":"resources/unsubscribe"`)
+}
+
+// stubSession is a minimal client session
+type stubSession struct {
+	id string
+}
+
+func (s *stubSession) Initialize()       {}
+func (s *stubSession) Initialized() bool { return true }
+func (s *stubSession) NotificationChannel() chan<- mcp.JSONRPCNotification {
+	return make(chan mcp.JSONRPCNotification, 1)
+}
+func (s *stubSession) SessionID() string { return s.id }
+
+var _ mcp_server.ClientSession = (*stubSession)(nil)
@@ -0,0 +1,186 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	mcp_server "github.com/mark3labs/mcp-go/server"
+	"github.com/sirupsen/logrus"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/logging"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/metrics"
+)
+
+// Transport identifies how MCP clients connect to the server
+type Transport string
+
+const (
+	// TransportStdio serves a single client over stdin/stdout
+	TransportStdio Transport = "stdio"
+	// TransportSSE serves clients over the HTTP+SSE transport
+	TransportSSE Transport = "sse"
+	// TransportHTTP serves clients over the Streamable HTTP transport
+	TransportHTTP Transport = "http"
+)
+
+const (
+	// DefaultListenAddr is the address used by network transports when none is given
+	DefaultListenAddr = ":8080"
+	// DefaultShutdownTimeout bounds how long shutdown waits for in-flight tool calls
+	DefaultShutdownTimeout = 30 * time.Second
+
+	// HealthPath is the liveness endpoint served by network transports
+	HealthPath = "/healthz"
+	// ReadyPath is the readiness endpoint served by network transports
+	ReadyPath = "/readyz"
+	// StreamableHTTPPath is the MCP endpoint served by the http transport
+	StreamableHTTPPath = "/mcp"
+)
+
+// ParseTransport converts a transport name into a Transport
+func ParseTransport(name string) (Transport, error) {
+	switch Transport(name) {
+	case TransportStdio, TransportSSE, TransportHTTP:
+		return Transport(name), nil
+	default:
+		return "", fmt.Errorf("unsupported transport %q (expected one of: stdio, sse, http)", name)
+	}
+}
+
+// ServeOptions configures how the server is exposed to MCP clients
+type ServeOptions struct {
+	Transport       Transport
+	ListenAddr      string
+	BaseURL         string
+	ShutdownTimeout time.Duration
+	// Metrics serves Prometheus metrics on /metrics of the sse and http transports
+	Metrics bool
+}
+
+// Serve runs the server on the configured transport until ctx is cancelled or the
+// transport fails. On cancellation, in-flight tool calls are drained before the
+// transport is closed.
+func (s *Server) Serve(ctx context.Context, opts ServeOptions) error {
+	if opts.Transport == "" {
+		opts.Transport = TransportStdio
+	}
+	if opts.ListenAddr == "" {
+		opts.ListenAddr = DefaultListenAddr
+	}
+	if opts.ShutdownTimeout == 0 {
+		opts.ShutdownTimeout = DefaultShutdownTimeout
+	}
+
+	// Transports run on a context that outlives ctx so in-flight tool calls
+	// keep working while the server drains
+	serveCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancel()
+
+	var httpServer *http.Server
+	errCh := make(chan error, 1)
+
+	switch opts.Transport {
+	case TransportStdio:
+		stdioServer := mcp_server.NewStdioServer(s.MCPServer)
+		stdout := &stdioWriter{server: s, out: os.Stdout}
+		go func() {
+			errCh <- stdioServer.Listen(serveCtx, s.newStdioReader(serveCtx, os.Stdin, stdout), stdout)
+		}()
+	case TransportSSE, TransportHTTP:
+		httpServer = &http.Server{
+			Addr:              opts.ListenAddr,
+			Handler:           s.httpHandler(opts),
+			ReadHeaderTimeout: 10 * time.Second,
+			BaseContext:       func(net.Listener) context.Context { return serveCtx },
+		}
+		go func() {
+			errCh <- httpServer.ListenAndServe()
+		}()
+		logging.WithFields(logrus.Fields{
+			"transport": opts.Transport,
+			"address":   opts.ListenAddr,
+		}).Info("Listening for MCP clients")
+	default:
+		return fmt.Errorf("unsupported transport %q", opts.Transport)
+	}
+
+	select {
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) || errors.Is(err, context.Canceled) {
+			return nil
+		}
+		return err
+	case <-ctx.Done():
+	}
+
+	logging.WithField("timeout", opts.ShutdownTimeout).Info("Shutting down, draining in-flight tool calls")
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), opts.ShutdownTimeout)
+	defer shutdownCancel()
+
+	if err := s.Drain(shutdownCtx); err != nil {
+		logging.WithField("error", err).Warn("Timed out waiting for in-flight tool calls")
+	}
+
+	// Closing the base context ends long-lived SSE streams
+	cancel()
+
+	if httpServer != nil {
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			_ = httpServer.Close()
+			return fmt.Errorf("failed to shut down HTTP server: %w", err)
+		}
+	}
+
+	if err := <-errCh; err != nil && !errors.Is(err, http.ErrServerClosed) && !errors.Is(err, context.Canceled) {
+		return err
+	}
+	return nil
+}
+
+// httpHandler builds the HTTP routes for the network transports
+func (s *Server) httpHandler(opts ServeOptions) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(HealthPath, s.handleHealth)
+	mux.HandleFunc(ReadyPath, s.handleReady)
+	if opts.Metrics {
+		mux.Handle(metrics.Path, metrics.Handler())
+	}
+
+	switch opts.Transport {
+	case TransportSSE:
+		sseOpts := []mcp_server.SSEOption{}
+		if opts.BaseURL != "" {
+			sseOpts = append(sseOpts, mcp_server.WithBaseURL(opts.BaseURL))
+		}
+		sseServer := mcp_server.NewSSEServer(s.MCPServer, sseOpts...)
+		completions := newSSECompletions(s)
+		mux.Handle(sseServer.CompleteSsePath(), completions.events(sseServer))
+		mux.Handle(sseServer.CompleteMessagePath(), completions.messages(rewriteSubscriptions(sseServer)))
+	case TransportHTTP:
+		mux.Handle(StreamableHTTPPath, s.streamableCompletions(rewriteSubscriptions(mcp_server.NewStreamableHTTPServer(s.MCPServer,
+			mcp_server.WithEndpointPath(StreamableHTTPPath),
+		))))
+	}
+
+	return mux
+}
+
+// handleHealth reports liveness; it succeeds as long as the process is serving HTTP
+func (s *Server) handleHealth(w http.ResponseWriter, _ *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("ok\n"))
+}
+
+// handleReady reports readiness; it fails once the server starts draining
+func (s *Server) handleReady(w http.ResponseWriter, _ *http.Request) {
+	if err := s.Ready(); err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("ok\n"))
+}
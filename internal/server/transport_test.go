@@ -0,0 +1,100 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTransport(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Transport
+		wantErr bool
+	}{
+		{name: "stdio", input: "stdio", want: TransportStdio},
+		{name: "sse", input: "sse", want: TransportSSE},
+		{name: "http", input: "http", want: TransportHTTP},
+		{name: "unknown", input: "websocket", wantErr: true},
+		{name: "empty", input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTransport(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestServer_HealthAndReadiness(t *testing.T) {
+	s := New()
+	handler := s.httpHandler(ServeOptions{Transport: TransportHTTP})
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	assert.Equal(t, http.StatusOK, get(HealthPath).Code)
+	assert.Equal(t, http.StatusOK, get(ReadyPath).Code)
+
+	require.NoError(t, s.Drain(context.Background()))
+
+	assert.Equal(t, http.StatusOK, get(HealthPath).Code)
+	assert.Equal(t, http.StatusServiceUnavailable, get(ReadyPath).Code)
+}
+
+func TestServer_DrainWaitsForInFlightCalls(t *testing.T) {
+	s := New()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := s.trackInFlight(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		close(started)
+		<-release
+		return mcp.NewToolResultText("done"), nil
+	})
+
+	go func() { _, _ = handler(context.Background(), mcp.CallToolRequest{}) }()
+	<-started
+
+	// Drain must not complete while the call is still running
+	shortCtx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, s.Drain(shortCtx), context.DeadlineExceeded)
+
+	// New calls are refused once draining has started
+	result, err := handler(context.Background(), mcp.CallToolRequest{})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+
+	close(release)
+	require.NoError(t, s.Drain(context.Background()))
+}
+
+func TestServer_MetricsEndpoint(t *testing.T) {
+	s := New()
+
+	rec := httptest.NewRecorder()
+	s.httpHandler(ServeOptions{Transport: TransportHTTP}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = httptest.NewRecorder()
+	s.httpHandler(ServeOptions{Transport: TransportHTTP, Metrics: true}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "go_goroutines")
+}
@@ -0,0 +1,137 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/audit"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/logging"
+)
+
+// WithAudit records every destructive tool call to logger
+func WithAudit(logger *audit.Logger) Option {
+	return func(r *Registry) {
+		r.audit = logger
+	}
+}
+
+// auditUsers caches the ArgoCD user of each instance; the token of an instance does not change
+type auditUsers struct {
+	mu    sync.Mutex
+	users map[string]string
+}
+
+// auditUserTimeout bounds the lookup of the ArgoCD user of an audited call
+const auditUserTimeout = 10 * time.Second
+
+// auditCallKey is the context key of the auditCallState of a call
+type auditCallKey struct{}
+
+// auditCallState collects what the handler of an audited call reports to auditCall
+type auditCallState struct {
+	pendingConfirmation bool
+}
+
+// markPendingConfirmation records that a call only returned a plan to confirm
+func markPendingConfirmation(ctx context.Context) {
+	if state, ok := ctx.Value(auditCallKey{}).(*auditCallState); ok {
+		state.pendingConfirmation = true
+	}
+}
+
+// auditCall wraps the handler of a destructive tool so that every call is recorded
+func (r *Registry) auditCall(tool mcp.Tool, next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	if r.audit == nil || !isDestructive(tool) {
+		return next
+	}
+
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		// Look the user up first, so that calls cancelled meanwhile are still attributed
+		user := r.auditUser(ctx, request)
+		state := &auditCallState{}
+		result, err := next(context.WithValue(ctx, auditCallKey{}, state), request)
+
+		record := audit.Record{
+			Time:       start.UTC(),
+			Tool:       tool.Name,
+			Instance:   r.instanceName(request),
+			Arguments:  request.GetArguments(),
+			User:       user,
+			Outcome:    audit.OutcomeSuccess,
+			DurationMS: time.Since(start).Milliseconds(),
+		}
+		if session := server.ClientSessionFromContext(ctx); session != nil {
+			record.SessionID = session.SessionID()
+			if info, ok := session.(server.SessionWithClientInfo); ok {
+				client := info.GetClientInfo()
+				record.Client = strings.TrimSpace(client.Name + " " + client.Version)
+			}
+		}
+		switch {
+		case err != nil:
+			record.Outcome = audit.OutcomeError
+			record.Error = err.Error()
+		case result != nil && result.IsError:
+			record.Outcome = audit.OutcomeError
+			record.Error = resultText(result)
+		case state.pendingConfirmation:
+			record.Outcome = audit.OutcomePendingConfirmation
+		}
+
+		if logErr := r.audit.Log(record); logErr != nil {
+			logging.WithField("tool", tool.Name).WithError(logErr).Error("Failed to write audit record")
+		}
+		return result, err
+	}
+}
+
+// auditUser returns the ArgoCD user the call was made as. The lookup does not
+// end with the call, which may be cancelled while it runs.
+func (r *Registry) auditUser(ctx context.Context, request mcp.CallToolRequest) string {
+	instance := r.instanceName(request)
+
+	r.auditUsers.mu.Lock()
+	user, ok := r.auditUsers.users[instance]
+	r.auditUsers.mu.Unlock()
+	if ok {
+		return user
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), auditUserTimeout)
+	defer cancel()
+	argoClient, err := r.client(ctx, request)
+	if err != nil {
+		return ""
+	}
+	defer func() { _ = argoClient.Close() }()
+
+	userInfo, err := argoClient.GetUserInfo(ctx)
+	if err != nil {
+		logging.WithField("instance", instance).WithError(err).Warn("Failed to get ArgoCD user for audit record")
+		return ""
+	}
+
+	r.auditUsers.mu.Lock()
+	defer r.auditUsers.mu.Unlock()
+	if r.auditUsers.users == nil {
+		r.auditUsers.users = make(map[string]string)
+	}
+	r.auditUsers.users[instance] = userInfo.Username
+	return userInfo.Username
+}
+
+// resultText joins the text content of a tool result
+func resultText(result *mcp.CallToolResult) string {
+	var texts []string
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			texts = append(texts, text.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
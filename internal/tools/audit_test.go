@@ -0,0 +1,114 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	sessionpkg "github.com/argoproj/argo-cd/v2/pkg/apiclient/session"
+	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client/mock"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/audit"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRegistry_WithAudit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := mock.NewMockInterface(ctrl)
+	mockClient.EXPECT().Close().Return(nil).AnyTimes()
+	// The user is looked up once per instance
+	mockClient.EXPECT().GetUserInfo(gomock.Any()).Return(&sessionpkg.GetUserInfoResponse{Username: "admin"}, nil).Times(1)
+	mockClient.EXPECT().DeleteApplication(gomock.Any(), "ok-app", true).Return(nil)
+	mockClient.EXPECT().DeleteApplication(gomock.Any(), "bad-app", true).Return(errors.New("permission denied"))
+	mockClient.EXPECT().GetApplication(gomock.Any(), "test-app").Return(nil, errors.New("not found"))
+
+	var buf bytes.Buffer
+	registry := newMockRegistry(mockClient, WithAudit(audit.NewLogger(&buf)))
+
+	for _, args := range []map[string]interface{}{
+		{"name": "ok-app", "auth_token": "should-not-leak"},
+		{"name": "bad-app"},
+	} {
+		_, err := findTool(t, registry, "delete_application").Handler(context.Background(), mcp.CallToolRequest{
+			Params: mcp.CallToolParams{Name: "delete_application", Arguments: args},
+		})
+		require.NoError(t, err)
+	}
+	// Read-only tools are not audited
+	_, err := findTool(t, registry, "get_application").Handler(context.Background(), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Name: "get_application", Arguments: map[string]interface{}{"name": "test-app"}},
+	})
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.NotContains(t, buf.String(), "should-not-leak")
+
+	var records []audit.Record
+	for _, line := range lines {
+		var record audit.Record
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+		records = append(records, record)
+	}
+
+	assert.Equal(t, "delete_application", records[0].Tool)
+	assert.Equal(t, DefaultInstanceName, records[0].Instance)
+	assert.Equal(t, "admin", records[0].User)
+	assert.Equal(t, audit.OutcomeSuccess, records[0].Outcome)
+	assert.Equal(t, audit.Redacted, records[0].Arguments["auth_token"])
+
+	assert.Equal(t, "admin", records[1].User)
+	assert.Equal(t, audit.OutcomeError, records[1].Outcome)
+	assert.Contains(t, records[1].Error, "permission denied")
+}
+
+func TestRegistry_WithAudit_Confirmation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := mock.NewMockInterface(ctrl)
+	mockClient.EXPECT().Close().Return(nil).AnyTimes()
+	// The user is looked up before the call runs, with a context the call's cancellation does not reach
+	mockClient.EXPECT().GetUserInfo(gomock.Any()).DoAndReturn(func(ctx context.Context) (*sessionpkg.GetUserInfoResponse, error) {
+		require.NoError(t, ctx.Err())
+		return &sessionpkg.GetUserInfoResponse{Username: "admin"}, nil
+	})
+	mockClient.EXPECT().GetApplicationResourceTree(gomock.Any(), "guestbook", "", "").Return(&v1alpha1.ApplicationTree{}, nil)
+	mockClient.EXPECT().DeleteApplication(gomock.Any(), "guestbook", true).DoAndReturn(func(ctx context.Context, name string, cascade bool) error {
+		return ctx.Err()
+	})
+
+	var buf bytes.Buffer
+	registry := NewRegistry(ClientFactory(func(ctx context.Context) (client.Interface, error) {
+		return mockClient, nil
+	}), Config{ConfirmDestructive: true}, WithAudit(audit.NewLogger(&buf)))
+	handler := findTool(t, registry, "delete_application").Handler
+
+	plan := resultPlan(t, callTool(t, handler, "delete_application", map[string]interface{}{"name": "guestbook"}))
+	// The confirmed call is cancelled while it runs
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := handler(ctx, mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "delete_application", Arguments: map[string]interface{}{
+		"name": "guestbook", ConfirmTokenArgument: plan.ConfirmToken,
+	}}})
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	var planned, confirmed audit.Record
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &planned))
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &confirmed))
+	assert.Equal(t, audit.OutcomePendingConfirmation, planned.Outcome)
+	assert.Equal(t, "admin", planned.User)
+	assert.Equal(t, audit.OutcomeError, confirmed.Outcome)
+	assert.Equal(t, "admin", confirmed.User)
+}
@@ -0,0 +1,355 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client"
+	serverpkg "github.com/toyamagu-2021/argocd-mcp-server/internal/server"
+)
+
+// completionCacheTTL is how long listed names are reused for completions
+const completionCacheTTL = 30 * time.Second
+
+// completionSource is the kind of object whose names complete an argument
+type completionSource string
+
+const (
+	sourceApplications  completionSource = "applications"
+	sourceAppNamespaces completionSource = "application namespaces"
+	sourceProjects      completionSource = "projects"
+	sourceClusters      completionSource = "clusters"
+	sourceRepositories  completionSource = "repositories"
+	sourceInstances     completionSource = "instances"
+)
+
+// completionKey identifies a cached list of names
+type completionKey struct {
+	instance string
+	source   completionSource
+}
+
+// completionEntry is a cached list of names
+type completionEntry struct {
+	values  []string
+	expires time.Time
+}
+
+// completions caches the names listed from ArgoCD for argument completion
+type completions struct {
+	clients ClientProvider
+	now     func() time.Time
+
+	mu      sync.Mutex
+	entries map[completionKey]completionEntry
+}
+
+func newCompletions(clients ClientProvider) *completions {
+	return &completions{
+		clients: clients,
+		now:     time.Now,
+		entries: make(map[completionKey]completionEntry),
+	}
+}
+
+// Complete implements server.Completer. Values come from the cached application,
+// project, cluster and repository lists of the instance named by the instance
+// argument, and are ranked by prefix, substring and fuzzy match.
+func (r *Registry) Complete(ctx context.Context, params serverpkg.CompletionParams) ([]string, error) {
+	source, err := r.completionSource(params.Ref, params.Argument.Name)
+	if err != nil || source == "" {
+		return nil, err
+	}
+
+	var values []string
+	if source == sourceInstances {
+		for _, instance := range r.clients.Instances() {
+			values = append(values, instance.Name)
+		}
+	} else {
+		values, err = r.completions.values(ctx, params.Context.Arguments[InstanceArgument], source)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return matchCompletions(values, params.Argument.Value), nil
+}
+
+// completionSource returns the source completing an argument of the referenced
+// prompt, resource template or tool; it is empty for arguments without suggestions.
+// Only enabled prompts, resource templates and tools are completed, from the lists
+// of enabled tools.
+func (r *Registry) completionSource(ref serverpkg.CompletionRef, argument string) (completionSource, error) {
+	var source completionSource
+	switch ref.Type {
+	case serverpkg.CompletionRefPrompt:
+		prompts := r.Prompts()
+		i := slices.IndexFunc(prompts, func(p server.ServerPrompt) bool { return p.Prompt.Name == ref.Name })
+		if i < 0 {
+			return "", fmt.Errorf("unknown prompt %q", ref.Name)
+		}
+		if !slices.ContainsFunc(prompts[i].Prompt.Arguments, func(a mcp.PromptArgument) bool { return a.Name == argument }) {
+			return "", fmt.Errorf("prompt %q has no argument %q", ref.Name, argument)
+		}
+		// The names of runbook prompts are application names
+		source = argumentSource(argument, sourceApplications)
+	case serverpkg.CompletionRefResource:
+		uri, _, _ := strings.Cut(ref.URI, "{?")
+		for _, t := range r.resourceTemplates() {
+			if t.uri == uri && r.config.enabled(t.set, t.tool) {
+				source = argumentSource(argument, setSource(t.set))
+			}
+		}
+	case serverpkg.CompletionRefTool:
+		set, ok := r.enabledToolset(ref.Name)
+		if !ok {
+			return "", fmt.Errorf("unknown tool %q", ref.Name)
+		}
+		nameSource := setSource(set)
+		if ref.Name == CreateAppTool.Name || ref.Name == CreateProjectTool.Name {
+			// New names have nothing to complete from
+			nameSource = ""
+		}
+		source = argumentSource(argument, nameSource)
+	default:
+		return "", fmt.Errorf("unsupported reference type %q", ref.Type)
+	}
+
+	if source == "" || !r.sourceEnabled(source) {
+		return "", nil
+	}
+	return source, nil
+}
+
+// enabledToolset returns the toolset of an enabled tool
+func (r *Registry) enabledToolset(name string) (string, bool) {
+	for _, set := range r.toolsets() {
+		for _, tool := range set.tools {
+			if tool.Tool.Name == name {
+				return set.name, r.config.enabled(set.name, tool.Tool)
+			}
+		}
+	}
+	return "", false
+}
+
+// argumentSource returns the source completing an argument. What a name argument
+// refers to depends on what it belongs to, so it is completed from nameSource.
+func argumentSource(argument string, nameSource completionSource) completionSource {
+	switch argument {
+	case "name":
+		return nameSource
+	case "project":
+		return sourceProjects
+	case "repo_url", "repo":
+		return sourceRepositories
+	case "app_namespace":
+		return sourceAppNamespaces
+	case "cluster", "server", "dest_server", "destination_server":
+		return sourceClusters
+	case InstanceArgument:
+		return sourceInstances
+	}
+	return ""
+}
+
+// setSource returns the source of the names of the objects a toolset acts on
+func setSource(set string) completionSource {
+	switch set {
+	case ToolsetApplications:
+		return sourceApplications
+	case ToolsetProjects:
+		return sourceProjects
+	case ToolsetClusters:
+		return sourceClusters
+	}
+	return ""
+}
+
+// sourceEnabled reports whether the tool listing the names of a source is enabled,
+// so that completions reveal nothing the tools do not
+func (r *Registry) sourceEnabled(source completionSource) bool {
+	switch source {
+	case sourceApplications, sourceAppNamespaces:
+		return r.config.enabled(ToolsetApplications, ListAppsTool)
+	case sourceProjects:
+		return r.config.enabled(ToolsetProjects, ListProjectsTool)
+	case sourceClusters:
+		return r.config.enabled(ToolsetClusters, ListClusterTool)
+	case sourceRepositories:
+		return r.config.enabled(ToolsetRepositories, ListRepositoryTool)
+	case sourceInstances:
+		return r.config.enabled(ToolsetSession, ListInstancesTool)
+	}
+	return false
+}
+
+// values returns the names of a source, listing them again once the cached list expires
+func (c *completions) values(ctx context.Context, instance string, source completionSource) ([]string, error) {
+	if instance == "" {
+		for _, i := range c.clients.Instances() {
+			if i.Default {
+				instance = i.Name
+			}
+		}
+	}
+	key := completionKey{instance: instance, source: source}
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && c.now().Before(entry.expires) {
+		return entry.values, nil
+	}
+
+	argoClient, err := c.clients.Client(ctx, instance)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gRPC client: %w", err)
+	}
+	defer func() { _ = argoClient.Close() }()
+
+	values, err := listCompletions(ctx, argoClient, source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", source, err)
+	}
+
+	c.mu.Lock()
+	c.entries[key] = completionEntry{values: values, expires: c.now().Add(completionCacheTTL)}
+	c.mu.Unlock()
+	return values, nil
+}
+
+// listCompletions lists the names of a source from ArgoCD
+func listCompletions(ctx context.Context, argoClient client.Interface, source completionSource) ([]string, error) {
+	var values []string
+	switch source {
+	case sourceApplications, sourceAppNamespaces:
+		apps, err := argoClient.ListApplications(ctx, "")
+		if err != nil {
+			return nil, err
+		}
+		for _, app := range apps.Items {
+			if source == sourceApplications {
+				values = append(values, app.Name)
+			} else if app.Namespace != "" {
+				values = append(values, app.Namespace)
+			}
+		}
+	case sourceProjects:
+		projects, err := argoClient.ListProjects(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, project := range projects.Items {
+			values = append(values, project.Name)
+		}
+	case sourceClusters:
+		clusters, err := argoClient.ListClusters(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, cluster := range clusters.Items {
+			values = append(values, cluster.Server)
+		}
+	case sourceRepositories:
+		repos, err := argoClient.ListRepositories(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, repo := range repos.Items {
+			values = append(values, repo.Repo)
+		}
+	}
+	slices.Sort(values)
+	return slices.Compact(values), nil
+}
+
+// Ranks of completion matches, best first
+const (
+	matchPrefix = iota
+	matchSubstring
+	matchSubsequence
+	matchTypo
+	noMatch
+)
+
+// matchCompletions returns the values matching the typed value, best match
+// first. Values starting with it come first, then values containing it, then
+// values containing its characters in order, then values within a few typos.
+func matchCompletions(values []string, typed string) []string {
+	typed = strings.ToLower(typed)
+	type match struct {
+		value string
+		rank  int
+	}
+	var matches []match
+	for _, value := range values {
+		if rank := matchRank(strings.ToLower(value), typed); rank != noMatch {
+			matches = append(matches, match{value: value, rank: rank})
+		}
+	}
+	slices.SortStableFunc(matches, func(a, b match) int {
+		if a.rank != b.rank {
+			return a.rank - b.rank
+		}
+		return strings.Compare(a.value, b.value)
+	})
+
+	result := make([]string, 0, len(matches))
+	for _, m := range matches {
+		result = append(result, m.value)
+	}
+	return result
+}
+
+func matchRank(value, typed string) int {
+	switch {
+	case strings.HasPrefix(value, typed):
+		return matchPrefix
+	case strings.Contains(value, typed):
+		return matchSubstring
+	case isSubsequence(typed, value):
+		return matchSubsequence
+	case editDistance(typed, value) <= max(1, len(typed)/3):
+		return matchTypo
+	}
+	return noMatch
+}
+
+// isSubsequence reports whether the characters of s appear in t in order
+func isSubsequence(s, t string) bool {
+	i := 0
+	for j := 0; i < len(s) && j < len(t); j++ {
+		if s[i] == t[j] {
+			i++
+		}
+	}
+	return i == len(s)
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
@@ -0,0 +1,190 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client/mock"
+	serverpkg "github.com/toyamagu-2021/argocd-mcp-server/internal/server"
+	"go.uber.org/mock/gomock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func completionParams(ref serverpkg.CompletionRef, argument, value string) serverpkg.CompletionParams {
+	params := serverpkg.CompletionParams{Ref: ref}
+	params.Argument.Name = argument
+	params.Argument.Value = value
+	return params
+}
+
+func TestMatchCompletions(t *testing.T) {
+	values := []string{"guestbook", "helm-guestbook", "guestbook-dev", "billing", "kustomize-guestbook", "payments"}
+
+	tests := []struct {
+		name  string
+		typed string
+		want  []string
+	}{
+		{name: "empty matches everything", typed: "", want: []string{"billing", "guestbook", "guestbook-dev", "helm-guestbook", "kustomize-guestbook", "payments"}},
+		{name: "prefix before substring", typed: "guest", want: []string{"guestbook", "guestbook-dev", "helm-guestbook", "kustomize-guestbook"}},
+		{name: "case insensitive, then typos", typed: "GUESTBOOK-D", want: []string{"guestbook-dev", "guestbook"}},
+		{name: "subsequence", typed: "hgb", want: []string{"helm-guestbook"}},
+		{name: "typo", typed: "paymnets", want: []string{"payments"}},
+		{name: "no match", typed: "zzz", want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, matchCompletions(values, tt.typed))
+		})
+	}
+}
+
+func TestRegistry_CompletionSource(t *testing.T) {
+	r := newMockRegistry(nil)
+
+	tests := []struct {
+		name     string
+		ref      serverpkg.CompletionRef
+		argument string
+		want     completionSource
+		wantErr  string
+	}{
+		{name: "tool application name", ref: serverpkg.CompletionRef{Type: serverpkg.CompletionRefTool, Name: GetAppTool.Name}, argument: "name", want: sourceApplications},
+		{name: "tool project name", ref: serverpkg.CompletionRef{Type: serverpkg.CompletionRefTool, Name: GetProjectTool.Name}, argument: "name", want: sourceProjects},
+		{name: "new application name", ref: serverpkg.CompletionRef{Type: serverpkg.CompletionRefTool, Name: CreateAppTool.Name}, argument: "name"},
+		{name: "tool repo url", ref: serverpkg.CompletionRef{Type: serverpkg.CompletionRefTool, Name: CreateAppTool.Name}, argument: "repo_url", want: sourceRepositories},
+		{name: "tool cluster", ref: serverpkg.CompletionRef{Type: serverpkg.CompletionRefTool, Name: ListAppsTool.Name}, argument: "cluster", want: sourceClusters},
+		{name: "prompt name", ref: serverpkg.CompletionRef{Type: serverpkg.CompletionRefPrompt, Name: ExplainOutOfSyncPrompt}, argument: "name", want: sourceApplications},
+		{name: "prompt app namespace", ref: serverpkg.CompletionRef{Type: serverpkg.CompletionRefPrompt, Name: ExplainOutOfSyncPrompt}, argument: "app_namespace", want: sourceAppNamespaces},
+		{name: "resource application", ref: serverpkg.CompletionRef{Type: serverpkg.CompletionRefResource, URI: ApplicationTreeResourceURI}, argument: "name", want: sourceApplications},
+		{name: "resource project", ref: serverpkg.CompletionRef{Type: serverpkg.CompletionRefResource, URI: ProjectResourceURI + "{?format}"}, argument: "name", want: sourceProjects},
+		{name: "resource cluster", ref: serverpkg.CompletionRef{Type: serverpkg.CompletionRefResource, URI: ClusterResourceURI}, argument: "server", want: sourceClusters},
+		{name: "instance", ref: serverpkg.CompletionRef{Type: serverpkg.CompletionRefTool, Name: GetAppTool.Name}, argument: InstanceArgument, want: sourceInstances},
+		{name: "prompt without the argument", ref: serverpkg.CompletionRef{Type: serverpkg.CompletionRefPrompt, Name: OnboardRepositoryPrompt}, argument: "name", wantErr: `prompt "onboard_repository" has no argument "name"`},
+		{name: "free text argument", ref: serverpkg.CompletionRef{Type: serverpkg.CompletionRefTool, Name: ListAppsTool.Name}, argument: "selector"},
+		{name: "unknown tool", ref: serverpkg.CompletionRef{Type: serverpkg.CompletionRefTool, Name: "nope"}, argument: "name", wantErr: `unknown tool "nope"`},
+		{name: "unknown prompt", ref: serverpkg.CompletionRef{Type: serverpkg.CompletionRefPrompt, Name: "nope"}, argument: "name", wantErr: `unknown prompt "nope"`},
+		{name: "unknown reference", ref: serverpkg.CompletionRef{Type: "ref/other"}, argument: "name", wantErr: "unsupported reference type"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.completionSource(tt.ref, tt.argument)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRegistry_CompletionSourceEnabled(t *testing.T) {
+	r := NewRegistry(ClientFactory(func(ctx context.Context) (client.Interface, error) {
+		return nil, nil
+	}), Config{DenyTools: []string{ToolsetClusters, ListProjectsTool.Name, GetAppTool.Name}})
+
+	// Arguments of disabled tools and prompts are not completed
+	_, err := r.completionSource(serverpkg.CompletionRef{Type: serverpkg.CompletionRefTool, Name: GetAppTool.Name}, "name")
+	assert.ErrorContains(t, err, `unknown tool "get_application"`)
+	_, err = r.completionSource(serverpkg.CompletionRef{Type: serverpkg.CompletionRefPrompt, Name: TroubleshootApplicationPrompt}, "name")
+	assert.ErrorContains(t, err, `unknown prompt "troubleshoot_application"`)
+	source, err := r.completionSource(serverpkg.CompletionRef{Type: serverpkg.CompletionRefResource, URI: ApplicationResourceURI}, "name")
+	require.NoError(t, err)
+	assert.Empty(t, source)
+
+	// Nor are names that only disabled tools list
+	source, err = r.completionSource(serverpkg.CompletionRef{Type: serverpkg.CompletionRefTool, Name: ListAppsTool.Name}, "project")
+	require.NoError(t, err)
+	assert.Empty(t, source)
+	source, err = r.completionSource(serverpkg.CompletionRef{Type: serverpkg.CompletionRefTool, Name: CreateAppTool.Name}, "dest_server")
+	require.NoError(t, err)
+	assert.Empty(t, source)
+
+	source, err = r.completionSource(serverpkg.CompletionRef{Type: serverpkg.CompletionRefResource, URI: ApplicationTreeResourceURI}, "name")
+	require.NoError(t, err)
+	assert.Equal(t, sourceApplications, source)
+}
+
+func TestRegistry_Complete(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := mock.NewMockInterface(ctrl)
+	mockClient.EXPECT().Close().Return(nil).AnyTimes()
+	r := newMockRegistry(mockClient)
+	now := time.Now()
+	r.completions.now = func() time.Time { return now }
+
+	appRef := serverpkg.CompletionRef{Type: serverpkg.CompletionRefTool, Name: GetAppTool.Name}
+	apps := &v1alpha1.ApplicationList{Items: []v1alpha1.Application{
+		{ObjectMeta: metav1.ObjectMeta{Name: "guestbook", Namespace: "argocd"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "helm-guestbook", Namespace: "team-a"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "payments", Namespace: "team-a"}},
+	}}
+
+	// The list is fetched once and reused until it expires
+	mockClient.EXPECT().ListApplications(gomock.Any(), "").Return(apps, nil).Times(2)
+	values, err := r.Complete(context.Background(), completionParams(appRef, "name", "guest"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"guestbook", "helm-guestbook"}, values)
+
+	values, err = r.Complete(context.Background(), completionParams(appRef, "name", "gestbook"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"guestbook", "helm-guestbook"}, values)
+
+	now = now.Add(completionCacheTTL)
+	values, err = r.Complete(context.Background(), completionParams(appRef, "name", "pay"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"payments"}, values)
+
+	t.Run("namespaces", func(t *testing.T) {
+		mockClient.EXPECT().ListApplications(gomock.Any(), "").Return(apps, nil)
+		values, err := r.Complete(context.Background(), completionParams(appRef, "app_namespace", ""))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"argocd", "team-a"}, values)
+	})
+
+	t.Run("projects, clusters and repositories", func(t *testing.T) {
+		mockClient.EXPECT().ListProjects(gomock.Any()).Return(&v1alpha1.AppProjectList{Items: []v1alpha1.AppProject{
+			{ObjectMeta: metav1.ObjectMeta{Name: "default"}}, {ObjectMeta: metav1.ObjectMeta{Name: "team-a"}},
+		}}, nil)
+		mockClient.EXPECT().ListClusters(gomock.Any()).Return(&v1alpha1.ClusterList{Items: []v1alpha1.Cluster{
+			{Name: "in-cluster", Server: "https://kubernetes.default.svc"}, {Name: "prod", Server: "https://prod.example.com"},
+		}}, nil)
+		mockClient.EXPECT().ListRepositories(gomock.Any()).Return(&v1alpha1.RepositoryList{Items: v1alpha1.Repositories{
+			{Repo: "https://github.com/org/apps"}, {Repo: "https://github.com/org/infra"},
+		}}, nil)
+
+		values, err := r.Complete(context.Background(), completionParams(appRef, "project", "tem"))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"team-a"}, values)
+
+		values, err = r.Complete(context.Background(), completionParams(serverpkg.CompletionRef{Type: serverpkg.CompletionRefTool, Name: CreateAppTool.Name}, "dest_server", "prod"))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"https://prod.example.com"}, values)
+
+		values, err = r.Complete(context.Background(), completionParams(serverpkg.CompletionRef{Type: serverpkg.CompletionRefPrompt, Name: OnboardRepositoryPrompt}, "repo_url", "infra"))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"https://github.com/org/infra"}, values)
+	})
+
+	t.Run("instances", func(t *testing.T) {
+		values, err := r.Complete(context.Background(), completionParams(appRef, InstanceArgument, ""))
+		require.NoError(t, err)
+		assert.Equal(t, []string{DefaultInstanceName}, values)
+	})
+
+	t.Run("list error", func(t *testing.T) {
+		mockClient.EXPECT().ListApplications(gomock.Any(), "").Return(nil, errors.New("permission denied"))
+		now = now.Add(completionCacheTTL)
+		_, err := r.Complete(context.Background(), completionParams(appRef, "name", ""))
+		assert.ErrorContains(t, err, "failed to list applications: permission denied")
+	})
+}
@@ -0,0 +1,250 @@
+package tools
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/metrics"
+)
+
+// ConfirmTokenArgument is the tool argument carrying the token of a confirmed plan
+const ConfirmTokenArgument = "confirm_token"
+
+// DefaultConfirmTTL is how long a confirmation token stays valid
+const DefaultConfirmTTL = 5 * time.Minute
+
+// confirmTokenDescription documents the confirm_token argument of the destructive tools
+const confirmTokenDescription = "Token returned by a previous call when the server requires confirmation of destructive actions. Without it, the call only returns a plan of what would be removed."
+
+var (
+	errConfirmTokenUnknown  = errors.New("unknown or already used confirmation token")
+	errConfirmTokenExpired  = errors.New("confirmation token has expired")
+	errConfirmTokenMismatch = errors.New("confirmation token was issued for a different action")
+)
+
+// ConfirmationPlan describes what a destructive action would do and how to confirm it
+type ConfirmationPlan struct {
+	Action       string         `json:"action"`
+	Resources    []PlanResource `json:"resources,omitempty"`
+	Applications []string       `json:"applications,omitempty"`
+	ConfirmToken string         `json:"confirmToken"`
+	ExpiresAt    string         `json:"expiresAt"`
+	Message      string         `json:"message"`
+}
+
+// PlanResource is a Kubernetes resource affected by a destructive action
+type PlanResource struct {
+	Group     string `json:"group,omitempty"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+}
+
+// pendingAction is an issued, not yet used confirmation token
+type pendingAction struct {
+	action  string
+	expires time.Time
+}
+
+// confirmations tracks the tokens issued for planned destructive actions.
+// Tokens are single use and bound to the exact action they were issued for.
+type confirmations struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	now     func() time.Time
+	pending map[string]pendingAction
+}
+
+func newConfirmations(ttl time.Duration) *confirmations {
+	if ttl <= 0 {
+		ttl = DefaultConfirmTTL
+	}
+	return &confirmations{
+		ttl:     ttl,
+		now:     time.Now,
+		pending: make(map[string]pendingAction),
+	}
+}
+
+// issue creates a token for action
+func (c *confirmations) issue(action string) (string, time.Time, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to generate confirmation token: %w", err)
+	}
+	token := hex.EncodeToString(buf)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	for t, pending := range c.pending {
+		if now.After(pending.expires) {
+			delete(c.pending, t)
+		}
+	}
+	expires := now.Add(c.ttl)
+	c.pending[token] = pendingAction{action: action, expires: expires}
+	return token, expires, nil
+}
+
+// consume validates token for action and invalidates it
+func (c *confirmations) consume(token, action string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	pending, ok := c.pending[token]
+	if !ok {
+		return errConfirmTokenUnknown
+	}
+	delete(c.pending, token)
+
+	if c.now().After(pending.expires) {
+		return errConfirmTokenExpired
+	}
+	if pending.action != action {
+		return errConfirmTokenMismatch
+	}
+	return nil
+}
+
+// confirm implements the two-phase confirmation of a destructive action. It returns
+// the result to send back when the call must stop: the plan with a new token on the
+// first call, or an error for an invalid token. A nil result lets the action proceed.
+func (r *Registry) confirm(ctx context.Context, request mcp.CallToolRequest, action string, plan func() (*ConfirmationPlan, error)) *mcp.CallToolResult {
+	if r.confirmations == nil {
+		return nil
+	}
+
+	// Bind the token to the instance as well, so that it cannot be replayed elsewhere
+	action = r.instanceName(request) + ": " + action
+
+	if token := request.GetString(ConfirmTokenArgument, ""); token != "" {
+		if err := r.confirmations.consume(token, action); err != nil {
+			return toolError(ctx, metrics.ErrorTypeConfirmation, fmt.Sprintf("Failed to confirm %s: %v. Call %s again without %s to get a new plan.", request.Params.Name, err, request.Params.Name, ConfirmTokenArgument))
+		}
+		return nil
+	}
+
+	p, err := plan()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to plan %s: %v", request.Params.Name, err))
+	}
+	token, expires, err := r.confirmations.issue(action)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error())
+	}
+	p.Action = action
+	p.ConfirmToken = token
+	p.ExpiresAt = expires.UTC().Format(time.RFC3339)
+	markPendingConfirmation(ctx)
+	p.Message = fmt.Sprintf("Nothing has been changed yet. Review the plan, then call %s again with the same arguments and %s=%q before %s to proceed.", request.Params.Name, ConfirmTokenArgument, token, p.ExpiresAt)
+
+	jsonData, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to format response: %v", err))
+	}
+	return mcp.NewToolResultText(string(jsonData))
+}
+
+// planResourceTree lists the live resources of an application, which a cascading delete removes
+func planResourceTree(ctx context.Context, argoClient client.Interface, appName string) (*ConfirmationPlan, error) {
+	tree, err := argoClient.GetApplicationResourceTree(ctx, appName, "", "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get resource tree: %w", err)
+	}
+
+	plan := &ConfirmationPlan{}
+	for _, node := range tree.Nodes {
+		plan.Resources = append(plan.Resources, PlanResource{
+			Group:     node.Group,
+			Kind:      node.Kind,
+			Namespace: node.Namespace,
+			Name:      node.Name,
+		})
+	}
+	return plan, nil
+}
+
+// planPrune lists the resources of an application that a pruning sync removes;
+// a sync of some resources only prunes those
+func planPrune(ctx context.Context, argoClient client.Interface, appName string, selected []v1alpha1.SyncOperationResource) (*ConfirmationPlan, error) {
+	app, err := argoClient.GetApplication(ctx, appName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get application: %w", err)
+	}
+
+	plan := &ConfirmationPlan{}
+	for _, resource := range app.Status.Resources {
+		if !resource.RequiresPruning || (len(selected) > 0 && !syncSelects(selected, resource)) {
+			continue
+		}
+		plan.Resources = append(plan.Resources, PlanResource{
+			Group:     resource.Group,
+			Kind:      resource.Kind,
+			Namespace: resource.Namespace,
+			Name:      resource.Name,
+		})
+	}
+	return plan, nil
+}
+
+// syncSelects reports whether a resource is one of the resources selected for a sync
+func syncSelects(selected []v1alpha1.SyncOperationResource, resource v1alpha1.ResourceStatus) bool {
+	for _, s := range selected {
+		if s.Group == resource.Group && s.Kind == resource.Kind && s.Name == resource.Name &&
+			(s.Namespace == "" || s.Namespace == resource.Namespace) {
+			return true
+		}
+	}
+	return false
+}
+
+// planApplicationSetDelete lists the applications owned by an ApplicationSet, which are deleted with it
+func planApplicationSetDelete(ctx context.Context, argoClient client.Interface, appSetName, appSetNamespace string) (*ConfirmationPlan, error) {
+	appSet, err := argoClient.GetApplicationSet(ctx, appSetName, appSetNamespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get applicationset: %w", err)
+	}
+	appList, err := argoClient.ListApplications(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list applications: %w", err)
+	}
+
+	plan := &ConfirmationPlan{}
+	for _, app := range appList.Items {
+		if ownedByApplicationSet(app, appSet) {
+			plan.Applications = append(plan.Applications, app.Name)
+		}
+	}
+	return plan, nil
+}
+
+// ownedByApplicationSet reports whether app is managed by appSet. Owners are
+// matched by UID, so that a namesake in another namespace, or a deleted and
+// recreated ApplicationSet, does not count.
+func ownedByApplicationSet(app v1alpha1.Application, appSet *v1alpha1.ApplicationSet) bool {
+	for _, owner := range app.OwnerReferences {
+		if owner.Kind != "ApplicationSet" || owner.Name != appSet.Name {
+			continue
+		}
+		if appSet.UID != "" {
+			if owner.UID == appSet.UID {
+				return true
+			}
+		} else if app.Namespace == appSet.Namespace {
+			// Owner references only refer to owners in the namespace of the object
+			return true
+		}
+	}
+	return false
+}
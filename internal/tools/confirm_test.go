@@ -0,0 +1,244 @@
+package tools
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client/mock"
+	"go.uber.org/mock/gomock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func newConfirmRegistry(argoClient client.Interface) *Registry {
+	return NewRegistry(ClientFactory(func(ctx context.Context) (client.Interface, error) {
+		return argoClient, nil
+	}), Config{ConfirmDestructive: true})
+}
+
+func callTool(t *testing.T, handler func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error), name string, args map[string]interface{}) *mcp.CallToolResult {
+	t.Helper()
+	result, err := handler(context.Background(), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Name: name, Arguments: args},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	return result
+}
+
+func resultPlan(t *testing.T, result *mcp.CallToolResult) ConfirmationPlan {
+	t.Helper()
+	require.False(t, result.IsError, "unexpected error: %v", result.Content)
+	plan := decodeResult[ConfirmationPlan](t, result)
+	require.NotEmpty(t, plan.ConfirmToken)
+	return plan
+}
+
+func TestHandleDeleteApplication_Confirm(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := mock.NewMockInterface(ctrl)
+	mockClient.EXPECT().Close().Return(nil).AnyTimes()
+	mockClient.EXPECT().GetApplicationResourceTree(gomock.Any(), "test-app", "", "").Return(&v1alpha1.ApplicationTree{
+		Nodes: []v1alpha1.ResourceNode{
+			{ResourceRef: v1alpha1.ResourceRef{Group: "apps", Kind: "Deployment", Namespace: "web", Name: "frontend"}},
+			{ResourceRef: v1alpha1.ResourceRef{Kind: "Service", Namespace: "web", Name: "frontend"}},
+		},
+	}, nil)
+
+	registry := newConfirmRegistry(mockClient)
+
+	// First call only returns the plan
+	plan := resultPlan(t, callTool(t, registry.HandleDeleteApplication, "delete_application", map[string]interface{}{"name": "test-app"}))
+	assert.Equal(t, []PlanResource{
+		{Group: "apps", Kind: "Deployment", Namespace: "web", Name: "frontend"},
+		{Kind: "Service", Namespace: "web", Name: "frontend"},
+	}, plan.Resources)
+
+	// A token does not confirm a different action
+	result := callTool(t, registry.HandleDeleteApplication, "delete_application", map[string]interface{}{
+		"name": "other-app", ConfirmTokenArgument: plan.ConfirmToken,
+	})
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "different action")
+
+	// Tokens are single use, so a new plan is needed
+	mockClient.EXPECT().GetApplicationResourceTree(gomock.Any(), "test-app", "", "").Return(&v1alpha1.ApplicationTree{}, nil)
+	plan = resultPlan(t, callTool(t, registry.HandleDeleteApplication, "delete_application", map[string]interface{}{"name": "test-app"}))
+
+	mockClient.EXPECT().DeleteApplication(gomock.Any(), "test-app", true).Return(nil)
+	result = callTool(t, registry.HandleDeleteApplication, "delete_application", map[string]interface{}{
+		"name": "test-app", ConfirmTokenArgument: plan.ConfirmToken,
+	})
+	assert.False(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "deleted successfully")
+
+	// The token cannot be replayed
+	result = callTool(t, registry.HandleDeleteApplication, "delete_application", map[string]interface{}{
+		"name": "test-app", ConfirmTokenArgument: plan.ConfirmToken,
+	})
+	assert.True(t, result.IsError)
+}
+
+func TestHandleSyncApplication_ConfirmPrune(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := mock.NewMockInterface(ctrl)
+	mockClient.EXPECT().Close().Return(nil).AnyTimes()
+	registry := newConfirmRegistry(mockClient)
+
+	// Syncs without prune run immediately
+	mockClient.EXPECT().SyncApplication(gomock.Any(), "test-app", client.SyncRequest{}).Return(&v1alpha1.Application{}, nil)
+	assert.False(t, callTool(t, registry.HandleSyncApplication, "sync_application", map[string]interface{}{"name": "test-app"}).IsError)
+
+	mockClient.EXPECT().GetApplication(gomock.Any(), "test-app").Return(&v1alpha1.Application{
+		Status: v1alpha1.ApplicationStatus{Resources: []v1alpha1.ResourceStatus{
+			{Kind: "ConfigMap", Namespace: "web", Name: "stale", RequiresPruning: true},
+			{Kind: "ConfigMap", Namespace: "web", Name: "current"},
+		}},
+	}, nil)
+	plan := resultPlan(t, callTool(t, registry.HandleSyncApplication, "sync_application", map[string]interface{}{"name": "test-app", "prune": true}))
+	assert.Equal(t, []PlanResource{{Kind: "ConfigMap", Namespace: "web", Name: "stale"}}, plan.Resources)
+
+	mockClient.EXPECT().SyncApplication(gomock.Any(), "test-app", client.SyncRequest{Prune: true}).Return(&v1alpha1.Application{}, nil)
+	assert.False(t, callTool(t, registry.HandleSyncApplication, "sync_application", map[string]interface{}{
+		"name": "test-app", "prune": true, ConfirmTokenArgument: plan.ConfirmToken,
+	}).IsError)
+
+	// A sync of some resources only prunes those
+	mockClient.EXPECT().GetApplication(gomock.Any(), "test-app").Return(&v1alpha1.Application{
+		Status: v1alpha1.ApplicationStatus{Resources: []v1alpha1.ResourceStatus{
+			{Kind: "ConfigMap", Namespace: "web", Name: "stale", RequiresPruning: true},
+			{Kind: "ConfigMap", Namespace: "web", Name: "old", RequiresPruning: true},
+		}},
+	}, nil)
+	plan = resultPlan(t, callTool(t, registry.HandleSyncApplication, "sync_application", map[string]interface{}{
+		"name": "test-app", "prune": true, "resources": ":ConfigMap:web/old",
+	}))
+	assert.Equal(t, []PlanResource{{Kind: "ConfigMap", Namespace: "web", Name: "old"}}, plan.Resources)
+
+	// Its token does not confirm a prune of the whole application, or other options
+	for _, args := range []map[string]interface{}{
+		{"name": "test-app", "prune": true},
+		{"name": "test-app", "prune": true, "resources": ":ConfigMap:web/old", "force": true},
+		{"name": "test-app", "prune": true, "resources": ":ConfigMap:web/old", "revision": "v2"},
+		{"name": "test-app", "prune": true, "resources": ":ConfigMap:web/old", "sync_options": "Replace=true"},
+	} {
+		mockClient.EXPECT().GetApplication(gomock.Any(), "test-app").Return(&v1alpha1.Application{
+			Status: v1alpha1.ApplicationStatus{Resources: []v1alpha1.ResourceStatus{
+				{Kind: "ConfigMap", Namespace: "web", Name: "old", RequiresPruning: true},
+			}},
+		}, nil)
+		selective := resultPlan(t, callTool(t, registry.HandleSyncApplication, "sync_application", map[string]interface{}{
+			"name": "test-app", "prune": true, "resources": ":ConfigMap:web/old",
+		}))
+		args[ConfirmTokenArgument] = selective.ConfirmToken
+		result := callTool(t, registry.HandleSyncApplication, "sync_application", args)
+		require.True(t, result.IsError, args)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, errConfirmTokenMismatch.Error())
+	}
+
+	// The order of the resources does not matter
+	mockClient.EXPECT().GetApplication(gomock.Any(), "test-app").Return(&v1alpha1.Application{}, nil)
+	plan = resultPlan(t, callTool(t, registry.HandleSyncApplication, "sync_application", map[string]interface{}{
+		"name": "test-app", "prune": true, "resources": ":ConfigMap:web/old,:ConfigMap:web/stale",
+	}))
+	mockClient.EXPECT().SyncApplication(gomock.Any(), "test-app", gomock.Any()).Return(&v1alpha1.Application{}, nil)
+	assert.False(t, callTool(t, registry.HandleSyncApplication, "sync_application", map[string]interface{}{
+		"name": "test-app", "prune": true, "resources": ":ConfigMap:web/stale,:ConfigMap:web/old", ConfirmTokenArgument: plan.ConfirmToken,
+	}).IsError)
+}
+
+func TestHandleDeleteApplicationSet_Confirm(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := mock.NewMockInterface(ctrl)
+	mockClient.EXPECT().Close().Return(nil).AnyTimes()
+	mockClient.EXPECT().GetApplicationSet(gomock.Any(), "test-appset", "").Return(&v1alpha1.ApplicationSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-appset", Namespace: "argocd", UID: "appset-uid"},
+	}, nil)
+	mockClient.EXPECT().ListApplications(gomock.Any(), "").Return(&v1alpha1.ApplicationList{
+		Items: []v1alpha1.Application{
+			{ObjectMeta: metav1.ObjectMeta{Name: "owned", Namespace: "argocd", OwnerReferences: []metav1.OwnerReference{{Kind: "ApplicationSet", Name: "test-appset", UID: "appset-uid"}}}},
+			// A namesake ApplicationSet in another namespace owns this one
+			{ObjectMeta: metav1.ObjectMeta{Name: "namesake", Namespace: "team-a", OwnerReferences: []metav1.OwnerReference{{Kind: "ApplicationSet", Name: "test-appset", UID: "other-uid"}}}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "unrelated"}},
+		},
+	}, nil)
+	registry := newConfirmRegistry(mockClient)
+
+	plan := resultPlan(t, callTool(t, registry.HandleDeleteApplicationSet, "delete_applicationset", map[string]interface{}{"name": "test-appset"}))
+	assert.Equal(t, []string{"owned"}, plan.Applications)
+
+	mockClient.EXPECT().DeleteApplicationSet(gomock.Any(), "test-appset", "").Return(nil)
+	assert.False(t, callTool(t, registry.HandleDeleteApplicationSet, "delete_applicationset", map[string]interface{}{
+		"name": "test-appset", ConfirmTokenArgument: plan.ConfirmToken,
+	}).IsError)
+}
+
+func TestHandleDeleteApplicationSet_ConfirmNamespaced(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := mock.NewMockInterface(ctrl)
+	mockClient.EXPECT().Close().Return(nil).AnyTimes()
+	// The plan is made for the ApplicationSet in the requested namespace, not its namesake in argocd
+	mockClient.EXPECT().GetApplicationSet(gomock.Any(), "test-appset", "team-a").Return(&v1alpha1.ApplicationSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-appset", Namespace: "team-a", UID: "team-a-uid"},
+	}, nil)
+	mockClient.EXPECT().ListApplications(gomock.Any(), "").Return(&v1alpha1.ApplicationList{
+		Items: []v1alpha1.Application{
+			{ObjectMeta: metav1.ObjectMeta{Name: "argocd-app", Namespace: "argocd", OwnerReferences: []metav1.OwnerReference{{Kind: "ApplicationSet", Name: "test-appset", UID: "argocd-uid"}}}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "team-a-app", Namespace: "team-a", OwnerReferences: []metav1.OwnerReference{{Kind: "ApplicationSet", Name: "test-appset", UID: "team-a-uid"}}}},
+		},
+	}, nil)
+	registry := newConfirmRegistry(mockClient)
+
+	plan := resultPlan(t, callTool(t, registry.HandleDeleteApplicationSet, "delete_applicationset", map[string]interface{}{
+		"name": "test-appset", "appsetNamespace": "team-a",
+	}))
+	assert.Equal(t, []string{"team-a-app"}, plan.Applications)
+
+	mockClient.EXPECT().DeleteApplicationSet(gomock.Any(), "test-appset", "team-a").Return(nil)
+	assert.False(t, callTool(t, registry.HandleDeleteApplicationSet, "delete_applicationset", map[string]interface{}{
+		"name": "test-appset", "appsetNamespace": "team-a", ConfirmTokenArgument: plan.ConfirmToken,
+	}).IsError)
+}
+
+func TestOwnedByApplicationSet(t *testing.T) {
+	owned := func(namespace string, uid types.UID) v1alpha1.Application {
+		return v1alpha1.Application{ObjectMeta: metav1.ObjectMeta{
+			Name: "app", Namespace: namespace,
+			OwnerReferences: []metav1.OwnerReference{{Kind: "ApplicationSet", Name: "test-appset", UID: uid}},
+		}}
+	}
+	appSet := &v1alpha1.ApplicationSet{ObjectMeta: metav1.ObjectMeta{Name: "test-appset", Namespace: "argocd", UID: "appset-uid"}}
+	withoutUID := &v1alpha1.ApplicationSet{ObjectMeta: metav1.ObjectMeta{Name: "test-appset", Namespace: "argocd"}}
+
+	assert.True(t, ownedByApplicationSet(owned("argocd", "appset-uid"), appSet))
+	assert.False(t, ownedByApplicationSet(owned("argocd", "recreated-uid"), appSet), "an earlier ApplicationSet of the same name")
+	assert.True(t, ownedByApplicationSet(owned("argocd", ""), withoutUID))
+	assert.False(t, ownedByApplicationSet(owned("team-a", ""), withoutUID), "a namesake in another namespace")
+}
+
+func TestConfirmations_Expiry(t *testing.T) {
+	c := newConfirmations(time.Minute)
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	token, _, err := c.issue("delete application a")
+	require.NoError(t, err)
+
+	now = now.Add(2 * time.Minute)
+	assert.ErrorIs(t, c.consume(token, "delete application a"), errConfirmTokenExpired)
+	assert.ErrorIs(t, c.consume(token, "delete application a"), errConfirmTokenUnknown)
+}
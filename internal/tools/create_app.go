@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
 
 	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -55,7 +54,7 @@ var CreateAppTool = mcp.NewTool("create_application",
 )
 
 // HandleCreateApplication processes create_application tool requests
-func HandleCreateApplication(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func (r *Registry) HandleCreateApplication(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	// Extract parameters
 	params := CreateAppParams{
 		Name:           request.GetString("name", ""),
@@ -71,17 +70,8 @@ func HandleCreateApplication(ctx context.Context, request mcp.CallToolRequest) (
 		SelfHeal:       request.GetBool("self_heal", false),
 	}
 
-	// Create gRPC client
-	config := &client.Config{
-		ServerAddr:      os.Getenv("ARGOCD_SERVER"),
-		AuthToken:       os.Getenv("ARGOCD_AUTH_TOKEN"),
-		Insecure:        os.Getenv("ARGOCD_INSECURE") == "true",
-		PlainText:       os.Getenv("ARGOCD_PLAINTEXT") == "true",
-		GRPCWeb:         os.Getenv("ARGOCD_GRPC_WEB") == "true",
-		GRPCWebRootPath: os.Getenv("ARGOCD_GRPC_WEB_ROOT_PATH"),
-	}
-
-	argoClient, err := client.New(config)
+	// Get the gRPC client for this call
+	argoClient, err := r.client(ctx, request)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to create gRPC client: %v", err)), nil
 	}
@@ -115,13 +105,13 @@ func createApplicationHandler(
 ) (*mcp.CallToolResult, error) {
 	// Validate required parameters
 	if params.Name == "" {
-		return mcp.NewToolResultError("Application name is required"), nil
+		return invalidArgument(ctx, "Application name is required"), nil
 	}
 	if params.RepoURL == "" {
-		return mcp.NewToolResultError("Repository URL is required"), nil
+		return invalidArgument(ctx, "Repository URL is required"), nil
 	}
 	if params.DestNamespace == "" {
-		return mcp.NewToolResultError("Destination namespace is required"), nil
+		return invalidArgument(ctx, "Destination namespace is required"), nil
 	}
 
 	// Build the application spec
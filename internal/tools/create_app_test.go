@@ -135,13 +135,11 @@ func TestHandleCreateApplication(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Set environment variables
-			for k, v := range tt.envVars {
-				t.Setenv(k, v)
-			}
+			// Build a registry configured from the test settings
+			registry := newSettingsRegistry(tt.envVars)
 
 			// Execute handler
-			result, err := HandleCreateApplication(context.Background(), tt.request)
+			result, err := registry.HandleCreateApplication(context.Background(), tt.request)
 
 			// Check error expectation
 			if tt.wantError {
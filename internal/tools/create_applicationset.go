@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
 
 	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -52,10 +51,10 @@ var CreateApplicationSetTool = mcp.NewTool("create_applicationset",
 )
 
 // HandleCreateApplicationSet processes the creation of an ApplicationSet in ArgoCD
-func HandleCreateApplicationSet(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func (r *Registry) HandleCreateApplicationSet(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	name := request.GetString("name", "")
 	if name == "" {
-		return mcp.NewToolResultError("name is required"), nil
+		return invalidArgument(ctx, "name is required"), nil
 	}
 
 	namespace := request.GetString("namespace", "argocd")
@@ -63,12 +62,12 @@ func HandleCreateApplicationSet(ctx context.Context, request mcp.CallToolRequest
 
 	generatorsStr := request.GetString("generators", "")
 	if generatorsStr == "" {
-		return mcp.NewToolResultError("generators is required"), nil
+		return invalidArgument(ctx, "generators is required"), nil
 	}
 
 	templateStr := request.GetString("template", "")
 	if templateStr == "" {
-		return mcp.NewToolResultError("template is required"), nil
+		return invalidArgument(ctx, "template is required"), nil
 	}
 
 	syncPolicyStr := request.GetString("sync_policy", "")
@@ -77,16 +76,8 @@ func HandleCreateApplicationSet(ctx context.Context, request mcp.CallToolRequest
 	upsert := request.GetBool("upsert", false)
 	dryRun := request.GetBool("dry_run", false)
 
-	config := &client.Config{
-		ServerAddr:      os.Getenv("ARGOCD_SERVER"),
-		AuthToken:       os.Getenv("ARGOCD_AUTH_TOKEN"),
-		Insecure:        os.Getenv("ARGOCD_INSECURE") == "true",
-		PlainText:       os.Getenv("ARGOCD_PLAINTEXT") == "true",
-		GRPCWeb:         os.Getenv("ARGOCD_GRPC_WEB") == "true",
-		GRPCWebRootPath: os.Getenv("ARGOCD_GRPC_WEB_ROOT_PATH"),
-	}
-
-	argoClient, err := client.New(config)
+	// Get the gRPC client for this call
+	argoClient, err := r.client(ctx, request)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to create gRPC client: %v", err)), nil
 	}
@@ -112,13 +103,13 @@ func createApplicationSetHandler(
 	// Parse generators JSON string
 	var appSetGenerators []v1alpha1.ApplicationSetGenerator
 	if err := json.Unmarshal([]byte(generatorsStr), &appSetGenerators); err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to parse generators: %v", err)), nil
+		return invalidArgument(ctx, fmt.Sprintf("Failed to parse generators: %v", err)), nil
 	}
 
 	// Parse template JSON string
 	var appSetTemplate v1alpha1.ApplicationSetTemplate
 	if err := json.Unmarshal([]byte(templateStr), &appSetTemplate); err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Failed to parse template: %v", err)), nil
+		return invalidArgument(ctx, fmt.Sprintf("Failed to parse template: %v", err)), nil
 	}
 
 	// Create ApplicationSet spec
@@ -132,7 +123,7 @@ func createApplicationSetHandler(
 	if syncPolicyStr != "" {
 		var appSetSyncPolicy v1alpha1.ApplicationSetSyncPolicy
 		if err := json.Unmarshal([]byte(syncPolicyStr), &appSetSyncPolicy); err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to parse sync_policy: %v", err)), nil
+			return invalidArgument(ctx, fmt.Sprintf("Failed to parse sync_policy: %v", err)), nil
 		}
 		appSetSpec.SyncPolicy = &appSetSyncPolicy
 	}
@@ -141,7 +132,7 @@ func createApplicationSetHandler(
 	if strategyStr != "" {
 		var appSetStrategy v1alpha1.ApplicationSetStrategy
 		if err := json.Unmarshal([]byte(strategyStr), &appSetStrategy); err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to parse strategy: %v", err)), nil
+			return invalidArgument(ctx, fmt.Sprintf("Failed to parse strategy: %v", err)), nil
 		}
 		appSetSpec.Strategy = &appSetStrategy
 	}
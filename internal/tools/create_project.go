@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
 	"strings"
 
 	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
@@ -49,11 +48,11 @@ var CreateProjectTool = mcp.NewTool("create_project",
 )
 
 // HandleCreateProject processes create_project tool requests
-func HandleCreateProject(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func (r *Registry) HandleCreateProject(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	// Extract required parameter
 	name := request.GetString("name", "")
 	if name == "" {
-		return mcp.NewToolResultError("Project name is required"), nil
+		return invalidArgument(ctx, "Project name is required"), nil
 	}
 
 	// Extract optional parameters
@@ -111,17 +110,8 @@ func HandleCreateProject(ctx context.Context, request mcp.CallToolRequest) (*mcp
 		project.Spec.NamespaceResourceBlacklist = parseGroupKinds(namespaceBlacklistStr)
 	}
 
-	// Create gRPC client
-	config := &client.Config{
-		ServerAddr:      os.Getenv("ARGOCD_SERVER"),
-		AuthToken:       os.Getenv("ARGOCD_AUTH_TOKEN"),
-		Insecure:        os.Getenv("ARGOCD_INSECURE") == "true",
-		PlainText:       os.Getenv("ARGOCD_PLAINTEXT") == "true",
-		GRPCWeb:         os.Getenv("ARGOCD_GRPC_WEB") == "true",
-		GRPCWebRootPath: os.Getenv("ARGOCD_GRPC_WEB_ROOT_PATH"),
-	}
-
-	argoClient, err := client.New(config)
+	// Get the gRPC client for this call
+	argoClient, err := r.client(ctx, request)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to create gRPC client: %v", err)), nil
 	}
@@ -58,13 +58,11 @@ func TestHandleCreateProject(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Set environment variables
-			for k, v := range tt.envVars {
-				t.Setenv(k, v)
-			}
+			// Build a registry configured from the test settings
+			registry := newSettingsRegistry(tt.envVars)
 
 			// Execute handler
-			result, err := HandleCreateProject(context.Background(), tt.request)
+			result, err := registry.HandleCreateProject(context.Background(), tt.request)
 
 			// Check expectations
 			if tt.wantError {
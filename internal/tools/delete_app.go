@@ -3,7 +3,6 @@ package tools
 import (
 	"context"
 	"fmt"
-	"os"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client"
@@ -20,30 +19,44 @@ var DeleteAppTool = mcp.NewTool("delete_application",
 	mcp.WithBoolean("cascade",
 		mcp.Description("Whether to perform a cascading delete to remove the application's resources from the cluster (default: true)."),
 	),
+	mcp.WithString(ConfirmTokenArgument,
+		mcp.Description(confirmTokenDescription),
+	),
 )
 
 // HandleDeleteApplication processes delete_application tool requests
-func HandleDeleteApplication(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func (r *Registry) HandleDeleteApplication(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	// Extract parameters from mcp.CallToolRequest
 	appName := request.GetString("name", "")
 	cascade := request.GetBool("cascade", true)
 
-	// Create gRPC client
-	config := &client.Config{
-		ServerAddr:      os.Getenv("ARGOCD_SERVER"),
-		AuthToken:       os.Getenv("ARGOCD_AUTH_TOKEN"),
-		Insecure:        os.Getenv("ARGOCD_INSECURE") == "true",
-		PlainText:       os.Getenv("ARGOCD_PLAINTEXT") == "true",
-		GRPCWeb:         os.Getenv("ARGOCD_GRPC_WEB") == "true",
-		GRPCWebRootPath: os.Getenv("ARGOCD_GRPC_WEB_ROOT_PATH"),
-	}
-
-	argoClient, err := client.New(config)
+	// Get the gRPC client for this call
+	argoClient, err := r.client(ctx, request)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to create gRPC client: %v", err)), nil
 	}
 	defer func() { _ = argoClient.Close() }()
 
+	if appName != "" {
+		action := fmt.Sprintf("delete application %s (cascade=%t)", appName, cascade)
+		plan := func() (*ConfirmationPlan, error) {
+			if !cascade {
+				// Only the application is removed; its resources are left in place
+				return &ConfirmationPlan{}, nil
+			}
+			return planResourceTree(ctx, argoClient, appName)
+		}
+		if result := r.confirm(ctx, request, action, plan); result != nil {
+			return result, nil
+		}
+		// Only a cascading delete removes resources from the cluster
+		if cascade {
+			if result := r.approve(ctx, request, action, plan); result != nil {
+				return result, nil
+			}
+		}
+	}
+
 	// Use the handler function with the real client
 	return deleteApplicationHandler(ctx, argoClient, appName, cascade)
 }
@@ -57,7 +70,7 @@ func deleteApplicationHandler(
 	cascade bool,
 ) (*mcp.CallToolResult, error) {
 	if appName == "" {
-		return mcp.NewToolResultError("Application name is required"), nil
+		return invalidArgument(ctx, "Application name is required"), nil
 	}
 
 	err := argoClient.DeleteApplication(ctx, appName, cascade)
@@ -3,7 +3,6 @@ package tools
 import (
 	"context"
 	"fmt"
-	"os"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client"
@@ -20,30 +19,37 @@ var DeleteApplicationSetTool = mcp.NewTool("delete_applicationset",
 	mcp.WithString("appsetNamespace",
 		mcp.Description("The namespace of the ApplicationSet. If not specified, uses the ArgoCD control plane namespace."),
 	),
+	mcp.WithString(ConfirmTokenArgument,
+		mcp.Description(confirmTokenDescription),
+	),
 )
 
 // HandleDeleteApplicationSet processes delete_applicationset tool requests
-func HandleDeleteApplicationSet(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func (r *Registry) HandleDeleteApplicationSet(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	// Extract parameters from mcp.CallToolRequest
 	appSetName := request.GetString("name", "")
 	appSetNamespace := request.GetString("appsetNamespace", "")
 
-	// Create gRPC client
-	config := &client.Config{
-		ServerAddr:      os.Getenv("ARGOCD_SERVER"),
-		AuthToken:       os.Getenv("ARGOCD_AUTH_TOKEN"),
-		Insecure:        os.Getenv("ARGOCD_INSECURE") == "true",
-		PlainText:       os.Getenv("ARGOCD_PLAINTEXT") == "true",
-		GRPCWeb:         os.Getenv("ARGOCD_GRPC_WEB") == "true",
-		GRPCWebRootPath: os.Getenv("ARGOCD_GRPC_WEB_ROOT_PATH"),
-	}
-
-	argoClient, err := client.New(config)
+	// Get the gRPC client for this call
+	argoClient, err := r.client(ctx, request)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to create gRPC client: %v", err)), nil
 	}
 	defer func() { _ = argoClient.Close() }()
 
+	if appSetName != "" {
+		action := fmt.Sprintf("delete applicationset %s/%s", appSetNamespace, appSetName)
+		plan := func() (*ConfirmationPlan, error) {
+			return planApplicationSetDelete(ctx, argoClient, appSetName, appSetNamespace)
+		}
+		if result := r.confirm(ctx, request, action, plan); result != nil {
+			return result, nil
+		}
+		if result := r.approve(ctx, request, action, plan); result != nil {
+			return result, nil
+		}
+	}
+
 	// Use the handler function with the real client
 	return deleteApplicationSetHandler(ctx, argoClient, appSetName, appSetNamespace)
 }
@@ -57,7 +63,7 @@ func deleteApplicationSetHandler(
 	appSetNamespace string,
 ) (*mcp.CallToolResult, error) {
 	if appSetName == "" {
-		return mcp.NewToolResultError("ApplicationSet name is required"), nil
+		return invalidArgument(ctx, "ApplicationSet name is required"), nil
 	}
 
 	err := argoClient.DeleteApplicationSet(ctx, appSetName, appSetNamespace)
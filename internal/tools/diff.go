@@ -0,0 +1,40 @@
+package tools
+
+import (
+	"fmt"
+
+	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/pmezard/go-difflib/difflib"
+	"sigs.k8s.io/yaml"
+)
+
+// unifiedDiff returns the unified diff between two texts, or "" when they are equal
+func unifiedDiff(from, to, fromName, toName string) string {
+	if from == to {
+		return ""
+	}
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(from),
+		B:        difflib.SplitLines(to),
+		FromFile: fromName,
+		ToFile:   toName,
+		Context:  3,
+	})
+	if err != nil {
+		return fmt.Sprintf("failed to compute diff: %v", err)
+	}
+	return diff
+}
+
+// specDiff returns the unified diff between two application specs rendered as YAML
+func specDiff(before, after v1alpha1.ApplicationSpec) (string, error) {
+	from, err := yaml.Marshal(before)
+	if err != nil {
+		return "", err
+	}
+	to, err := yaml.Marshal(after)
+	if err != nil {
+		return "", err
+	}
+	return unifiedDiff(string(from), string(to), "before", "after"), nil
+}
@@ -0,0 +1,314 @@
+package tools
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client"
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultMaxDiffLines is how many lines of each resource diff diff_application returns by default
+const DefaultMaxDiffLines = 200
+
+// Changes of a resource in diff_application
+const (
+	DiffChangeModified = "modified"
+	DiffChangeMissing  = "missing"
+	DiffChangeExtra    = "extra"
+)
+
+// DiffAppTool defines the diff_application tool schema
+var DiffAppTool = mcp.NewTool("diff_application",
+	mcp.WithDescription("Explains why an ArgoCD application is OutOfSync: for each resource whose live state differs from the desired state in Git, returns a unified diff from the normalized live state to the desired state. Fields ignored by the application's ignoreDifferences are left out. Servers that do not send normalized states leave jqPathExpressions and managedFieldsManagers rules to this tool, which cannot apply them; such rules are listed in unappliedIgnores."),
+	mcp.WithDestructiveHintAnnotation(false),
+	mcp.WithString("name",
+		mcp.Required(),
+		mcp.Description("The name of the application."),
+	),
+	mcp.WithString("kind",
+		mcp.Description("Optional. Only diff resources of this kind, e.g. 'Deployment'."),
+	),
+	mcp.WithString("resource_namespace",
+		mcp.Description("Optional. Only diff resources in this namespace."),
+	),
+	mcp.WithString("resource_name",
+		mcp.Description("Optional. Only diff resources with this name."),
+	),
+	mcp.WithNumber("max_diff_lines",
+		mcp.Description(fmt.Sprintf("Optional. Maximum number of lines of each resource diff (default: %d). Longer diffs are cut and marked as truncated.", DefaultMaxDiffLines)),
+	),
+	withPagination(),
+)
+
+// HandleDiffApplication processes diff_application tool requests
+func (r *Registry) HandleDiffApplication(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	appName := request.GetString("name", "")
+	filter := diffFilter{
+		kind:      request.GetString("kind", ""),
+		namespace: request.GetString("resource_namespace", ""),
+		name:      request.GetString("resource_name", ""),
+	}
+	maxLines := request.GetInt("max_diff_lines", DefaultMaxDiffLines)
+	if maxLines <= 0 {
+		return invalidArgument(ctx, "max_diff_lines must be positive"), nil
+	}
+
+	// Get the gRPC client for this call
+	argoClient, err := r.client(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to create gRPC client: %v", err)), nil
+	}
+	defer func() { _ = argoClient.Close() }()
+
+	return r.paginate(ctx, request, pageSpec{field: "resources"}, func() (*mcp.CallToolResult, error) {
+		return diffApplicationHandler(ctx, argoClient, appName, filter, maxLines)
+	})
+}
+
+// diffFilter selects the resources to diff; empty fields match every resource
+type diffFilter struct {
+	kind      string
+	namespace string
+	name      string
+}
+
+// ApplicationDiff lists the resources of an application that are out of sync
+type ApplicationDiff struct {
+	Application string              `json:"application"`
+	SyncStatus  string              `json:"syncStatus"`
+	Revision    string              `json:"revision,omitempty"`
+	InSync      int                 `json:"inSync"`
+	Resources   []ResourceDiffEntry `json:"resources"`
+}
+
+// ResourceDiffEntry is the difference between the live and the desired state of a resource
+type ResourceDiffEntry struct {
+	Group     string `json:"group,omitempty"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	Change    string `json:"change"`
+	DiffLines int    `json:"diffLines"`
+	Truncated bool   `json:"truncated,omitempty"`
+	Diff      string `json:"diff"`
+	// UnappliedIgnores lists the ignoreDifferences rules matching the resource that the diff does not apply
+	UnappliedIgnores []string `json:"unappliedIgnores,omitempty"`
+}
+
+// diffApplicationHandler handles the core logic for diffing an application.
+// This is separated out to enable testing with mocked clients.
+func diffApplicationHandler(
+	ctx context.Context,
+	argoClient client.Interface,
+	appName string,
+	filter diffFilter,
+	maxLines int,
+) (*mcp.CallToolResult, error) {
+	if appName == "" {
+		return invalidArgument(ctx, "Application name is required"), nil
+	}
+
+	app, err := argoClient.GetApplication(ctx, appName)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get application: %v", err)), nil
+	}
+	resources, err := argoClient.GetManagedResources(ctx, appName, filter.kind, filter.namespace, filter.name)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get managed resources: %v", err)), nil
+	}
+
+	result := ApplicationDiff{
+		Application: app.Name,
+		SyncStatus:  string(app.Status.Sync.Status),
+		Revision:    app.Status.Sync.Revision,
+		Resources:   []ResourceDiffEntry{},
+	}
+	for _, res := range resources {
+		// Hooks are not part of the desired state
+		if res.Hook {
+			continue
+		}
+		entry, err := diffResource(res, app.Spec.IgnoreDifferences, maxLines)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to diff %s/%s: %v", res.Kind, res.Name, err)), nil
+		}
+		if entry == nil {
+			result.InSync++
+			continue
+		}
+		result.Resources = append(result.Resources, *entry)
+	}
+	sort.SliceStable(result.Resources, func(i, j int) bool {
+		a, b := result.Resources[i], result.Resources[j]
+		return a.Group+"/"+a.Kind+"/"+a.Namespace+"/"+a.Name < b.Group+"/"+b.Kind+"/"+b.Namespace+"/"+b.Name
+	})
+
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to format response: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// diffResource diffs the normalized live state of a resource against its desired
+// state, returning nil when they do not differ
+func diffResource(res *v1alpha1.ResourceDiff, ignore []v1alpha1.ResourceIgnoreDifferences, maxLines int) (*ResourceDiffEntry, error) {
+	// The server normalizes the live state and predicts the desired one with the
+	// ignoreDifferences of the application; older servers only send the target
+	live, desired := "", ""
+	if !emptyManifest(res.LiveState) {
+		live = cmp.Or(res.NormalizedLiveState, res.LiveState)
+	}
+	if !emptyManifest(res.TargetState) {
+		desired = cmp.Or(res.PredictedLiveState, res.TargetState)
+	}
+	// The server already decided whether resources present on both sides differ
+	if live != "" && desired != "" && !res.Modified {
+		return nil, nil
+	}
+
+	entry := &ResourceDiffEntry{Group: res.Group, Kind: res.Kind, Namespace: res.Namespace, Name: res.Name, Change: DiffChangeModified}
+	pointers, serverOnly := ignoreRules(res, ignore)
+	if res.NormalizedLiveState == "" || res.PredictedLiveState == "" {
+		entry.UnappliedIgnores = serverOnly
+	}
+	liveYAML, err := normalizedYAML(live, pointers)
+	if err != nil {
+		return nil, fmt.Errorf("invalid live state: %w", err)
+	}
+	desiredYAML, err := normalizedYAML(desired, pointers)
+	if err != nil {
+		return nil, fmt.Errorf("invalid desired state: %w", err)
+	}
+	switch {
+	case liveYAML == "" && desiredYAML == "":
+		return nil, nil
+	case liveYAML == "":
+		entry.Change = DiffChangeMissing
+	case desiredYAML == "":
+		entry.Change = DiffChangeExtra
+	}
+
+	resource := strings.TrimPrefix(res.Group+"/"+res.Kind, "/") + "/" + res.Name
+	diff := unifiedDiff(liveYAML, desiredYAML, "live/"+resource, "desired/"+resource)
+	if diff == "" {
+		return nil, nil
+	}
+	lines := strings.SplitAfter(diff, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	entry.DiffLines = len(lines)
+	if len(lines) > maxLines {
+		lines = lines[:maxLines]
+		entry.Truncated = true
+	}
+	entry.Diff = strings.Join(lines, "")
+	return entry, nil
+}
+
+// ignoreRules returns the JSON pointers that the ignoreDifferences of an application
+// ignore for a resource, and describes the jq path expressions and managed fields
+// managers they ignore, which only the server applies
+func ignoreRules(res *v1alpha1.ResourceDiff, ignore []v1alpha1.ResourceIgnoreDifferences) (pointers []string, serverOnly []string) {
+	for _, rule := range ignore {
+		if matchesRule(rule.Group, res.Group) && matchesRule(rule.Kind, res.Kind) &&
+			(rule.Name == "" || rule.Name == res.Name) &&
+			(rule.Namespace == "" || rule.Namespace == res.Namespace) {
+			pointers = append(pointers, rule.JSONPointers...)
+			for _, expression := range rule.JQPathExpressions {
+				serverOnly = append(serverOnly, "jqPathExpressions: "+expression)
+			}
+			for _, manager := range rule.ManagedFieldsManagers {
+				serverOnly = append(serverOnly, "managedFieldsManagers: "+manager)
+			}
+		}
+	}
+	return pointers, serverOnly
+}
+
+func matchesRule(pattern, value string) bool {
+	return pattern == "*" || pattern == value
+}
+
+// normalizedYAML renders a JSON manifest as YAML without the fields the cluster
+// manages and the ignored JSON pointers; empty manifests render as ""
+func normalizedYAML(manifest string, pointers []string) (string, error) {
+	if emptyManifest(manifest) {
+		return "", nil
+	}
+	var obj map[string]any
+	if err := json.Unmarshal([]byte(manifest), &obj); err != nil {
+		return "", err
+	}
+	if obj == nil {
+		return "", nil
+	}
+
+	delete(obj, "status")
+	if metadata, ok := obj["metadata"].(map[string]any); ok {
+		for _, field := range []string{"managedFields", "resourceVersion", "uid", "generation", "creationTimestamp", "selfLink"} {
+			delete(metadata, field)
+		}
+		if annotations, ok := metadata["annotations"].(map[string]any); ok {
+			delete(annotations, "kubectl.kubernetes.io/last-applied-configuration")
+			if len(annotations) == 0 {
+				delete(metadata, "annotations")
+			}
+		}
+	}
+	for _, pointer := range pointers {
+		removePointer(obj, pointer)
+	}
+
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func emptyManifest(manifest string) bool {
+	return manifest == "" || manifest == "null"
+}
+
+// removePointer deletes the value an RFC 6901 JSON pointer refers to, if there is one
+func removePointer(obj map[string]any, pointer string) {
+	segments := strings.Split(strings.TrimPrefix(pointer, "/"), "/")
+	for i, segment := range segments {
+		segments[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(segment)
+	}
+	removePath(obj, segments)
+}
+
+// removePath deletes the value at a path of object keys and array indexes, returning the updated value
+func removePath(value any, segments []string) any {
+	switch v := value.(type) {
+	case map[string]any:
+		if len(segments) == 1 {
+			delete(v, segments[0])
+		} else if child, ok := v[segments[0]]; ok {
+			v[segments[0]] = removePath(child, segments[1:])
+		}
+		return v
+	case []any:
+		index, err := strconv.Atoi(segments[0])
+		if err != nil || index < 0 || index >= len(v) {
+			return v
+		}
+		if len(segments) == 1 {
+			return append(v[:index:index], v[index+1:]...)
+		}
+		v[index] = removePath(v[index], segments[1:])
+		return v
+	}
+	return value
+}
@@ -0,0 +1,179 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/gitops-engine/pkg/health"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client/mock"
+	"go.uber.org/mock/gomock"
+)
+
+// deploymentManifest returns a guestbook Deployment manifest
+func deploymentManifest(t *testing.T, image string, replicas int, extra map[string]any) string {
+	t.Helper()
+	obj := map[string]any{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]any{"name": "guestbook-ui", "namespace": "default"},
+		"spec": map[string]any{
+			"replicas": replicas,
+			"template": map[string]any{"spec": map[string]any{"containers": []any{
+				map[string]any{"name": "guestbook-ui", "image": image},
+			}}},
+		},
+	}
+	for key, value := range extra {
+		obj[key] = value
+	}
+	data, err := json.Marshal(obj)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestDiffApplicationHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	app := testApplication(v1alpha1.SyncStatusCodeOutOfSync, health.HealthStatusHealthy)
+	app.Status.Sync.Revision = "abc123"
+	app.Spec.IgnoreDifferences = []v1alpha1.ResourceIgnoreDifferences{
+		{Group: "apps", Kind: "Deployment", JSONPointers: []string{"/spec/replicas"}},
+		{Kind: "ConfigMap", Name: "other", JSONPointers: []string{"/data"}},
+	}
+
+	live := deploymentManifest(t, "guestbook:v1", 5, map[string]any{
+		"metadata": map[string]any{
+			"name": "guestbook-ui", "namespace": "default", "resourceVersion": "42", "uid": "1234",
+			"managedFields": []any{map[string]any{"manager": "kubectl"}},
+			"annotations":   map[string]any{"kubectl.kubernetes.io/last-applied-configuration": "{}"},
+		},
+		"status": map[string]any{"readyReplicas": 5},
+	})
+	target := deploymentManifest(t, "guestbook:v2", 1, nil)
+	configMap := `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"settings","namespace":"default"},"data":{"mode":"fast"}}`
+	secret := `{"apiVersion":"v1","kind":"Secret","metadata":{"name":"stale","namespace":"default"}}`
+	service := `{"apiVersion":"v1","kind":"Service","metadata":{"name":"guestbook-ui","namespace":"default"}}`
+
+	mockClient := mock.NewMockInterface(ctrl)
+	mockClient.EXPECT().GetApplication(gomock.Any(), "guestbook").Return(&app, nil)
+	mockClient.EXPECT().GetManagedResources(gomock.Any(), "guestbook", "", "", "").Return([]*v1alpha1.ResourceDiff{
+		{Group: "apps", Kind: "Deployment", Namespace: "default", Name: "guestbook-ui", LiveState: live, TargetState: target, NormalizedLiveState: live, PredictedLiveState: target, Modified: true},
+		{Kind: "Service", Namespace: "default", Name: "guestbook-ui", LiveState: service, TargetState: service, NormalizedLiveState: service, PredictedLiveState: service},
+		{Kind: "ConfigMap", Namespace: "default", Name: "settings", LiveState: "null", TargetState: configMap, NormalizedLiveState: "null", PredictedLiveState: configMap},
+		{Kind: "Secret", Namespace: "default", Name: "stale", LiveState: secret, TargetState: "null", NormalizedLiveState: secret, PredictedLiveState: "null"},
+		{Group: "batch", Kind: "Job", Namespace: "default", Name: "migrate", TargetState: `{"kind":"Job"}`, Hook: true},
+	}, nil)
+
+	result, err := diffApplicationHandler(context.Background(), mockClient, "guestbook", diffFilter{}, DefaultMaxDiffLines)
+	require.NoError(t, err)
+	diff := decodeResult[ApplicationDiff](t, result)
+
+	assert.Equal(t, "OutOfSync", diff.SyncStatus)
+	assert.Equal(t, "abc123", diff.Revision)
+	assert.Equal(t, 1, diff.InSync)
+	require.Len(t, diff.Resources, 3)
+
+	// Resources are sorted by group, kind, namespace and name
+	assert.Equal(t, "ConfigMap", diff.Resources[0].Kind)
+	assert.Equal(t, DiffChangeMissing, diff.Resources[0].Change)
+	assert.Contains(t, diff.Resources[0].Diff, "+  mode: fast\n")
+	assert.Equal(t, "Secret", diff.Resources[1].Kind)
+	assert.Equal(t, DiffChangeExtra, diff.Resources[1].Change)
+	assert.Contains(t, diff.Resources[1].Diff, "-kind: Secret\n")
+
+	deploy := diff.Resources[2]
+	assert.Equal(t, DiffChangeModified, deploy.Change)
+	assert.Contains(t, deploy.Diff, "--- live/apps/Deployment/guestbook-ui\n+++ desired/apps/Deployment/guestbook-ui\n")
+	assert.Contains(t, deploy.Diff, "-      - image: guestbook:v1\n+      - image: guestbook:v2\n")
+	// Ignored fields and the fields the cluster manages are not diffed
+	for _, noise := range []string{"replicas", "resourceVersion", "managedFields", "last-applied-configuration", "readyReplicas", "uid"} {
+		assert.NotContains(t, deploy.Diff, noise)
+	}
+	assert.False(t, deploy.Truncated)
+	assert.Equal(t, deploy.DiffLines, strings.Count(deploy.Diff, "\n"))
+}
+
+func TestDiffApplicationHandler_FiltersAndCap(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	app := testApplication(v1alpha1.SyncStatusCodeOutOfSync, health.HealthStatusHealthy)
+	mockClient := mock.NewMockInterface(ctrl)
+	mockClient.EXPECT().GetApplication(gomock.Any(), "guestbook").Return(&app, nil)
+	mockClient.EXPECT().GetManagedResources(gomock.Any(), "guestbook", "Deployment", "default", "guestbook-ui").Return([]*v1alpha1.ResourceDiff{
+		{Group: "apps", Kind: "Deployment", Namespace: "default", Name: "guestbook-ui",
+			LiveState: deploymentManifest(t, "guestbook:v1", 1, nil), TargetState: deploymentManifest(t, "guestbook:v2", 2, nil), Modified: true},
+	}, nil)
+
+	filter := diffFilter{kind: "Deployment", namespace: "default", name: "guestbook-ui"}
+	result, err := diffApplicationHandler(context.Background(), mockClient, "guestbook", filter, 4)
+	require.NoError(t, err)
+	diff := decodeResult[ApplicationDiff](t, result)
+
+	require.Len(t, diff.Resources, 1)
+	assert.True(t, diff.Resources[0].Truncated)
+	assert.Greater(t, diff.Resources[0].DiffLines, 4)
+	assert.Equal(t, 4, strings.Count(diff.Resources[0].Diff, "\n"))
+}
+
+func TestDiffApplicationHandler_UnappliedIgnores(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	app := testApplication(v1alpha1.SyncStatusCodeOutOfSync, health.HealthStatusHealthy)
+	app.Spec.IgnoreDifferences = []v1alpha1.ResourceIgnoreDifferences{{
+		Group: "apps", Kind: "Deployment",
+		JSONPointers:          []string{"/spec/replicas"},
+		JQPathExpressions:     []string{".spec.template.spec.containers[].image"},
+		ManagedFieldsManagers: []string{"kube-controller-manager"},
+	}}
+	live := deploymentManifest(t, "guestbook:v1", 5, nil)
+	target := deploymentManifest(t, "guestbook:v2", 1, nil)
+	normalized := strings.Replace(live, "guestbook-ui", "normalized", 1)
+
+	mockClient := mock.NewMockInterface(ctrl)
+	mockClient.EXPECT().GetApplication(gomock.Any(), "guestbook").Return(&app, nil)
+	mockClient.EXPECT().GetManagedResources(gomock.Any(), "guestbook", "", "", "").Return([]*v1alpha1.ResourceDiff{
+		// An older server sends the raw states, so the jq and managed fields rules are not applied
+		{Group: "apps", Kind: "Deployment", Namespace: "default", Name: "guestbook-ui", LiveState: live, TargetState: target, Modified: true},
+		// The server applied every rule to the states it normalized
+		{Group: "apps", Kind: "Deployment", Namespace: "default", Name: "normalized", LiveState: live, TargetState: target,
+			NormalizedLiveState: normalized, PredictedLiveState: strings.Replace(target, "guestbook-ui", "normalized", 1), Modified: true},
+	}, nil)
+
+	result, err := diffApplicationHandler(context.Background(), mockClient, "guestbook", diffFilter{}, DefaultMaxDiffLines)
+	require.NoError(t, err)
+	diff := decodeResult[ApplicationDiff](t, result)
+
+	require.Len(t, diff.Resources, 2)
+	assert.Equal(t, "guestbook-ui", diff.Resources[0].Name)
+	assert.Equal(t, []string{
+		"jqPathExpressions: .spec.template.spec.containers[].image",
+		"managedFieldsManagers: kube-controller-manager",
+	}, diff.Resources[0].UnappliedIgnores)
+	assert.NotContains(t, diff.Resources[0].Diff, "replicas")
+	assert.Equal(t, "normalized", diff.Resources[1].Name)
+	assert.Empty(t, diff.Resources[1].UnappliedIgnores)
+}
+
+func TestRemovePointer(t *testing.T) {
+	obj := map[string]any{
+		"metadata": map[string]any{"labels": map[string]any{"app.kubernetes.io/name": "x", "keep": "y"}},
+		"spec":     map[string]any{"containers": []any{map[string]any{"name": "a"}, map[string]any{"name": "b"}}},
+	}
+	removePointer(obj, "/metadata/labels/app.kubernetes.io~1name")
+	removePointer(obj, "/spec/containers/0")
+	removePointer(obj, "/spec/missing/field")
+	removePointer(obj, "/spec/containers/9")
+
+	assert.Equal(t, map[string]any{
+		"metadata": map[string]any{"labels": map[string]any{"keep": "y"}},
+		"spec":     map[string]any{"containers": []any{map[string]any{"name": "b"}}},
+	}, obj)
+}
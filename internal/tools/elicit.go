@@ -0,0 +1,151 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/metrics"
+)
+
+// ElicitationFallback decides what happens to a dangerous action when the client
+// cannot be asked for approval because it does not support elicitation
+type ElicitationFallback string
+
+const (
+	// ElicitationFallbackDeny refuses the action
+	ElicitationFallbackDeny ElicitationFallback = "deny"
+	// ElicitationFallbackAllow runs the action without approval
+	ElicitationFallbackAllow ElicitationFallback = "allow"
+)
+
+// ParseElicitationFallback validates an elicitation fallback policy name
+func ParseElicitationFallback(value string) (ElicitationFallback, error) {
+	switch fallback := ElicitationFallback(strings.ToLower(value)); fallback {
+	case ElicitationFallbackDeny, ElicitationFallbackAllow:
+		return fallback, nil
+	case "":
+		return ElicitationFallbackDeny, nil
+	default:
+		return "", fmt.Errorf("unknown elicitation fallback %q (expected deny or allow)", value)
+	}
+}
+
+// approvalField is the property of the elicitation form the user has to tick
+const approvalField = "approve"
+
+// maxSummaryItems caps the number of resources listed in an approval prompt
+const maxSummaryItems = 20
+
+// approve asks the user to approve a dangerous action through MCP elicitation. Like
+// confirm, it returns the result to send back when the action must not run, or nil
+// when it may proceed.
+func (r *Registry) approve(ctx context.Context, request mcp.CallToolRequest, action string, plan func() (*ConfirmationPlan, error)) *mcp.CallToolResult {
+	if !r.config.ElicitDestructive {
+		return nil
+	}
+
+	session, ok := elicitationSession(ctx)
+	if !ok {
+		if r.config.ElicitationFallback == ElicitationFallbackAllow {
+			return nil
+		}
+		return toolError(ctx, metrics.ErrorTypeApproval, fmt.Sprintf("%s requires approval from the user, but the client does not support elicitation", request.Params.Name))
+	}
+
+	p, err := plan()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to plan %s: %v", request.Params.Name, err))
+	}
+
+	result, err := session.RequestElicitation(ctx, mcp.ElicitationRequest{
+		Params: mcp.ElicitationParams{
+			Message: p.summary(action),
+			RequestedSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					approvalField: map[string]any{
+						"type":        "boolean",
+						"title":       "Approve",
+						"description": "Run this action now",
+					},
+				},
+				"required": []string{approvalField},
+			},
+		},
+	})
+	if err != nil {
+		return toolError(ctx, metrics.ErrorTypeApproval, fmt.Sprintf("Failed to request approval for %s: %v", request.Params.Name, err))
+	}
+
+	if result.Action != mcp.ElicitationResponseActionAccept || !approved(result.Content) {
+		return toolError(ctx, metrics.ErrorTypeApproval, fmt.Sprintf("The user did not approve %s (%s); nothing was changed", action, result.Action))
+	}
+	return nil
+}
+
+// elicitationSession returns the session of the tool call when its client supports elicitation
+func elicitationSession(ctx context.Context) (server.SessionWithElicitation, bool) {
+	session, ok := server.ClientSessionFromContext(ctx).(server.SessionWithElicitation)
+	if !ok {
+		return nil, false
+	}
+	if info, ok := session.(server.SessionWithClientInfo); ok && info.GetClientCapabilities().Elicitation == nil {
+		return nil, false
+	}
+	return session, true
+}
+
+// approved reports whether the elicitation content ticks the approval field
+func approved(content any) bool {
+	values, ok := content.(map[string]any)
+	if !ok {
+		return false
+	}
+	approve, ok := values[approvalField].(bool)
+	return ok && approve
+}
+
+// summary describes the impact of the planned action for a human
+func (p *ConfirmationPlan) summary(action string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Approve: %s?", action)
+
+	if len(p.Applications) > 0 {
+		fmt.Fprintf(&sb, "\n%d application(s) will be deleted:", len(p.Applications))
+		for i, app := range p.Applications {
+			if i == maxSummaryItems {
+				fmt.Fprintf(&sb, "\n- ... and %d more", len(p.Applications)-i)
+				break
+			}
+			fmt.Fprintf(&sb, "\n- %s", app)
+		}
+	}
+
+	if len(p.Resources) > 0 {
+		fmt.Fprintf(&sb, "\n%d resource(s) will be deleted:", len(p.Resources))
+		for i, resource := range p.Resources {
+			if i == maxSummaryItems {
+				fmt.Fprintf(&sb, "\n- ... and %d more", len(p.Resources)-i)
+				break
+			}
+			fmt.Fprintf(&sb, "\n- %s", resource)
+		}
+	}
+
+	return sb.String()
+}
+
+// String formats a resource as group/kind namespace/name
+func (r PlanResource) String() string {
+	kind := r.Kind
+	if r.Group != "" {
+		kind = r.Group + "/" + r.Kind
+	}
+	if r.Namespace == "" {
+		return kind + " " + r.Name
+	}
+	return kind + " " + r.Namespace + "/" + r.Name
+}
@@ -0,0 +1,153 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client/mock"
+	"go.uber.org/mock/gomock"
+)
+
+// fakeElicitationSession is a client session that answers elicitation requests with a fixed response
+type fakeElicitationSession struct {
+	capabilities mcp.ClientCapabilities
+	response     *mcp.ElicitationResult
+	err          error
+	requests     []mcp.ElicitationRequest
+}
+
+func (s *fakeElicitationSession) Initialize()       {}
+func (s *fakeElicitationSession) Initialized() bool { return true }
+func (s *fakeElicitationSession) NotificationChannel() chan<- mcp.JSONRPCNotification {
+	return make(chan mcp.JSONRPCNotification, 1)
+}
+func (s *fakeElicitationSession) SessionID() string                 { return "test-session" }
+func (s *fakeElicitationSession) GetClientInfo() mcp.Implementation { return mcp.Implementation{} }
+func (s *fakeElicitationSession) SetClientInfo(mcp.Implementation)  {}
+func (s *fakeElicitationSession) GetClientCapabilities() mcp.ClientCapabilities {
+	return s.capabilities
+}
+func (s *fakeElicitationSession) SetClientCapabilities(mcp.ClientCapabilities) {}
+
+func (s *fakeElicitationSession) RequestElicitation(ctx context.Context, request mcp.ElicitationRequest) (*mcp.ElicitationResult, error) {
+	s.requests = append(s.requests, request)
+	return s.response, s.err
+}
+
+func elicitationResponse(action mcp.ElicitationResponseAction, content any) *mcp.ElicitationResult {
+	return &mcp.ElicitationResult{ElicitationResponse: mcp.ElicitationResponse{Action: action, Content: content}}
+}
+
+func TestHandleDeleteApplication_Elicitation(t *testing.T) {
+	supported := mcp.ClientCapabilities{Elicitation: &struct{}{}}
+
+	tests := []struct {
+		name       string
+		session    *fakeElicitationSession
+		fallback   ElicitationFallback
+		cascade    bool
+		wantDelete bool
+		wantPrompt bool
+	}{
+		{
+			name:       "approved",
+			session:    &fakeElicitationSession{capabilities: supported, response: elicitationResponse(mcp.ElicitationResponseActionAccept, map[string]any{"approve": true})},
+			cascade:    true,
+			wantDelete: true,
+			wantPrompt: true,
+		},
+		{
+			name:       "accepted without ticking approve",
+			session:    &fakeElicitationSession{capabilities: supported, response: elicitationResponse(mcp.ElicitationResponseActionAccept, map[string]any{"approve": false})},
+			cascade:    true,
+			wantPrompt: true,
+		},
+		{
+			name:       "declined",
+			session:    &fakeElicitationSession{capabilities: supported, response: elicitationResponse(mcp.ElicitationResponseActionDecline, nil)},
+			cascade:    true,
+			wantPrompt: true,
+		},
+		{
+			name:       "elicitation fails",
+			session:    &fakeElicitationSession{capabilities: supported, err: errors.New("timeout")},
+			cascade:    true,
+			wantPrompt: true,
+		},
+		{
+			name:    "client without elicitation denies by default",
+			session: &fakeElicitationSession{},
+			cascade: true,
+		},
+		{
+			name:       "client without elicitation with allow fallback",
+			session:    &fakeElicitationSession{},
+			fallback:   ElicitationFallbackAllow,
+			cascade:    true,
+			wantDelete: true,
+		},
+		{
+			name:       "non-cascading delete is not prompted",
+			session:    &fakeElicitationSession{capabilities: supported},
+			wantDelete: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockClient := mock.NewMockInterface(ctrl)
+			mockClient.EXPECT().Close().Return(nil).AnyTimes()
+			mockClient.EXPECT().GetApplicationResourceTree(gomock.Any(), "test-app", "", "").Return(&v1alpha1.ApplicationTree{
+				Nodes: []v1alpha1.ResourceNode{{ResourceRef: v1alpha1.ResourceRef{Group: "apps", Kind: "Deployment", Namespace: "web", Name: "frontend"}}},
+			}, nil).AnyTimes()
+			if tt.wantDelete {
+				mockClient.EXPECT().DeleteApplication(gomock.Any(), "test-app", tt.cascade).Return(nil)
+			}
+
+			registry := NewRegistry(ClientFactory(func(ctx context.Context) (client.Interface, error) {
+				return mockClient, nil
+			}), Config{ElicitDestructive: true, ElicitationFallback: tt.fallback})
+
+			ctx := server.NewMCPServer("test", "1.0.0").WithContext(context.Background(), tt.session)
+			result, err := registry.HandleDeleteApplication(ctx, mcp.CallToolRequest{
+				Params: mcp.CallToolParams{
+					Name:      "delete_application",
+					Arguments: map[string]interface{}{"name": "test-app", "cascade": tt.cascade},
+				},
+			})
+			require.NoError(t, err)
+			assert.Equal(t, !tt.wantDelete, result.IsError)
+
+			if tt.wantPrompt {
+				require.Len(t, tt.session.requests, 1)
+				assert.Contains(t, tt.session.requests[0].Params.Message, "delete application test-app")
+				assert.Contains(t, tt.session.requests[0].Params.Message, "apps/Deployment web/frontend")
+			} else {
+				assert.Empty(t, tt.session.requests)
+			}
+		})
+	}
+}
+
+func TestParseElicitationFallback(t *testing.T) {
+	fallback, err := ParseElicitationFallback("")
+	require.NoError(t, err)
+	assert.Equal(t, ElicitationFallbackDeny, fallback)
+
+	fallback, err = ParseElicitationFallback("Allow")
+	require.NoError(t, err)
+	assert.Equal(t, ElicitationFallbackAllow, fallback)
+
+	_, err = ParseElicitationFallback("maybe")
+	assert.Error(t, err)
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client"
@@ -33,10 +32,11 @@ var GetAppEventsTool = mcp.NewTool("get_application_events",
 	mcp.WithString("project",
 		mcp.Description("Optional. The ArgoCD project the application belongs to."),
 	),
+	withPagination(),
 )
 
 // HandleGetApplicationEvents processes get_application_events tool requests
-func HandleGetApplicationEvents(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func (r *Registry) HandleGetApplicationEvents(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	// Extract parameters from mcp.CallToolRequest
 	appName := request.GetString("name", "")
 	resourceNamespace := request.GetString("resource_namespace", "")
@@ -45,24 +45,17 @@ func HandleGetApplicationEvents(ctx context.Context, request mcp.CallToolRequest
 	appNamespace := request.GetString("app_namespace", "")
 	project := request.GetString("project", "")
 
-	// Create gRPC client
-	config := &client.Config{
-		ServerAddr:      os.Getenv("ARGOCD_SERVER"),
-		AuthToken:       os.Getenv("ARGOCD_AUTH_TOKEN"),
-		Insecure:        os.Getenv("ARGOCD_INSECURE") == "true",
-		PlainText:       os.Getenv("ARGOCD_PLAINTEXT") == "true",
-		GRPCWeb:         os.Getenv("ARGOCD_GRPC_WEB") == "true",
-		GRPCWebRootPath: os.Getenv("ARGOCD_GRPC_WEB_ROOT_PATH"),
-	}
-
-	argoClient, err := client.New(config)
+	// Get the gRPC client for this call
+	argoClient, err := r.client(ctx, request)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to create gRPC client: %v", err)), nil
 	}
 	defer func() { _ = argoClient.Close() }()
 
 	// Use the handler function with the real client
-	return getApplicationEventsHandler(ctx, argoClient, appName, resourceNamespace, resourceName, resourceUID, appNamespace, project)
+	return r.paginate(ctx, request, pageSpec{field: "items"}, func() (*mcp.CallToolResult, error) {
+		return getApplicationEventsHandler(ctx, argoClient, appName, resourceNamespace, resourceName, resourceUID, appNamespace, project)
+	})
 }
 
 // getApplicationEventsHandler handles the core logic for getting application events.
@@ -78,7 +71,7 @@ func getApplicationEventsHandler(
 	project string,
 ) (*mcp.CallToolResult, error) {
 	if appName == "" {
-		return mcp.NewToolResultError("Application name is required"), nil
+		return invalidArgument(ctx, "Application name is required"), nil
 	}
 
 	events, err := argoClient.GetApplicationEvents(ctx, appName, resourceNamespace, resourceName, resourceUID, appNamespace, project)
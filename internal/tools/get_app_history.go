@@ -0,0 +1,184 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client"
+)
+
+// GetAppHistoryTool defines the get_application_history tool schema
+var GetAppHistoryTool = mcp.NewTool("get_application_history",
+	mcp.WithDescription("Lists the deployment history of an ArgoCD application, newest first: each deployment ID with its revisions, sources, deployment time and initiator, and the author and message of each revision. The IDs can be passed to rollback_application."),
+	mcp.WithDestructiveHintAnnotation(false),
+	mcp.WithString("name",
+		mcp.Required(),
+		mcp.Description("The name of the application."),
+	),
+	mcp.WithBoolean("revision_metadata",
+		mcp.Description("Whether to look up the author, date and message of each revision (default: true). Disable to save one API call per revision."),
+	),
+	withPagination(),
+)
+
+// HandleGetApplicationHistory processes get_application_history tool requests
+func (r *Registry) HandleGetApplicationHistory(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	appName := request.GetString("name", "")
+	withMetadata := request.GetBool("revision_metadata", true)
+
+	// Get the gRPC client for this call
+	argoClient, err := r.client(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to create gRPC client: %v", err)), nil
+	}
+	defer func() { _ = argoClient.Close() }()
+
+	return r.paginate(ctx, request, pageSpec{field: "history"}, func() (*mcp.CallToolResult, error) {
+		return getApplicationHistoryHandler(ctx, argoClient, appName, withMetadata)
+	})
+}
+
+// ApplicationHistory is the deployment history of an application
+type ApplicationHistory struct {
+	Application string         `json:"application"`
+	Current     int64          `json:"current,omitempty"`
+	History     []HistoryEntry `json:"history"`
+}
+
+// HistoryEntry is a deployment of an application
+type HistoryEntry struct {
+	ID              int64           `json:"id"`
+	DeployedAt      string          `json:"deployedAt"`
+	DeployStartedAt string          `json:"deployStartedAt,omitempty"`
+	InitiatedBy     string          `json:"initiatedBy,omitempty"`
+	Sources         []HistorySource `json:"sources"`
+}
+
+// HistorySource is a source of a deployment and the revision it was deployed from
+type HistorySource struct {
+	RepoURL       string `json:"repoURL"`
+	Path          string `json:"path,omitempty"`
+	Chart         string `json:"chart,omitempty"`
+	Revision      string `json:"revision"`
+	Author        string `json:"author,omitempty"`
+	Date          string `json:"date,omitempty"`
+	Message       string `json:"message,omitempty"`
+	MetadataError string `json:"metadataError,omitempty"`
+}
+
+// getApplicationHistoryHandler handles the core logic for listing the history of an application.
+// This is separated out to enable testing with mocked clients.
+func getApplicationHistoryHandler(
+	ctx context.Context,
+	argoClient client.Interface,
+	appName string,
+	withMetadata bool,
+) (*mcp.CallToolResult, error) {
+	if appName == "" {
+		return invalidArgument(ctx, "Application name is required"), nil
+	}
+
+	app, err := argoClient.GetApplication(ctx, appName)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get application: %v", err)), nil
+	}
+
+	history := ApplicationHistory{Application: app.Name, History: make([]HistoryEntry, 0, len(app.Status.History))}
+	if n := len(app.Status.History); n > 0 {
+		history.Current = app.Status.History[n-1].ID
+	}
+
+	// Revisions are often deployed more than once, so their metadata is only fetched once
+	type metadataKey struct {
+		index    int
+		revision string
+	}
+	metadata := make(map[metadataKey]*v1alpha1.RevisionMetadata)
+	metadataErrors := make(map[metadataKey]error)
+
+	for _, deployment := range slices.Backward(app.Status.History) {
+		entry := HistoryEntry{
+			ID:          deployment.ID,
+			DeployedAt:  deployment.DeployedAt.Format(time.RFC3339),
+			InitiatedBy: initiator(deployment.InitiatedBy),
+		}
+		if deployment.DeployStartedAt != nil {
+			entry.DeployStartedAt = deployment.DeployStartedAt.Format(time.RFC3339)
+		}
+
+		multiSource := len(deployment.Sources) > 0
+		for i, source := range historySources(deployment) {
+			if withMetadata && source.Chart == "" && source.Revision != "" {
+				// Helm chart versions have no metadata, only Git revisions do
+				key := metadataKey{revision: source.Revision}
+				var sourceIndex, versionID *int32
+				if multiSource {
+					key.index = i
+					index, id := int32(i), int32(deployment.ID)
+					sourceIndex, versionID = &index, &id
+				}
+				if _, seen := metadata[key]; !seen {
+					metadata[key], metadataErrors[key] = argoClient.GetRevisionMetadata(ctx, appName, source.Revision, sourceIndex, versionID)
+				}
+				if err := metadataErrors[key]; err != nil {
+					source.MetadataError = err.Error()
+				} else if meta := metadata[key]; meta != nil {
+					source.Author = meta.Author
+					source.Message = meta.Message
+					if !meta.Date.IsZero() {
+						source.Date = meta.Date.Format(time.RFC3339)
+					}
+				}
+			}
+			entry.Sources = append(entry.Sources, source)
+		}
+		history.History = append(history.History, entry)
+	}
+
+	jsonData, err := json.MarshalIndent(history, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to format response: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// historySources pairs the sources of a deployment with the revisions they were deployed from
+func historySources(deployment v1alpha1.RevisionHistory) []HistorySource {
+	if len(deployment.Sources) == 0 {
+		return []HistorySource{newHistorySource(deployment.Source, deployment.Revision)}
+	}
+	sources := make([]HistorySource, len(deployment.Sources))
+	for i, source := range deployment.Sources {
+		revision := ""
+		if i < len(deployment.Revisions) {
+			revision = deployment.Revisions[i]
+		}
+		sources[i] = newHistorySource(source, revision)
+	}
+	return sources
+}
+
+func newHistorySource(source v1alpha1.ApplicationSource, revision string) HistorySource {
+	return HistorySource{
+		RepoURL:  source.RepoURL,
+		Path:     source.Path,
+		Chart:    source.Chart,
+		Revision: revision,
+	}
+}
+
+// initiator describes who started an operation
+func initiator(by v1alpha1.OperationInitiator) string {
+	switch {
+	case by.Automated:
+		return "automated"
+	case by.Username != "":
+		return by.Username
+	}
+	return ""
+}
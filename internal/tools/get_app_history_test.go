@@ -0,0 +1,113 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/gitops-engine/pkg/health"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client/mock"
+	"go.uber.org/mock/gomock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// deployment returns a history entry of the guestbook repository deployed at minute
+func deployment(id int64, revision string, minute int) v1alpha1.RevisionHistory {
+	return v1alpha1.RevisionHistory{
+		ID:          id,
+		Revision:    revision,
+		DeployedAt:  metav1.NewTime(time.Date(2026, 1, 2, 3, minute, 0, 0, time.UTC)),
+		Source:      v1alpha1.ApplicationSource{RepoURL: "https://github.com/example/guestbook", Path: "guestbook"},
+		InitiatedBy: v1alpha1.OperationInitiator{Username: "alice"},
+	}
+}
+
+func TestGetApplicationHistoryHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	app := testApplication(v1alpha1.SyncStatusCodeSynced, health.HealthStatusHealthy)
+	automated := deployment(3, "aaa111", 30)
+	automated.InitiatedBy = v1alpha1.OperationInitiator{Automated: true}
+	multi := v1alpha1.RevisionHistory{
+		ID:         4,
+		DeployedAt: metav1.NewTime(time.Date(2026, 1, 2, 4, 0, 0, 0, time.UTC)),
+		Sources: v1alpha1.ApplicationSources{
+			{RepoURL: "https://github.com/example/guestbook", Path: "guestbook"},
+			{RepoURL: "https://charts.example.com", Chart: "redis"},
+		},
+		Revisions: []string{"bbb222", "18.0.1"},
+	}
+	app.Status.History = v1alpha1.RevisionHistories{deployment(1, "aaa111", 10), deployment(2, "ccc333", 20), automated, multi}
+
+	mockClient := mock.NewMockInterface(ctrl)
+	mockClient.EXPECT().GetApplication(gomock.Any(), "guestbook").Return(&app, nil)
+	// Each revision is looked up once, and Helm chart versions are not looked up
+	mockClient.EXPECT().GetRevisionMetadata(gomock.Any(), "guestbook", "aaa111", nil, nil).Return(&v1alpha1.RevisionMetadata{
+		Author:  "Alice <alice@example.com>",
+		Date:    metav1.NewTime(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)),
+		Message: "Scale the frontend",
+	}, nil)
+	mockClient.EXPECT().GetRevisionMetadata(gomock.Any(), "guestbook", "ccc333", nil, nil).Return(nil, errors.New("revision not found"))
+	mockClient.EXPECT().GetRevisionMetadata(gomock.Any(), "guestbook", "bbb222", gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, name, revision string, sourceIndex, versionID *int32) (*v1alpha1.RevisionMetadata, error) {
+			require.NotNil(t, sourceIndex)
+			require.NotNil(t, versionID)
+			assert.Equal(t, int32(0), *sourceIndex)
+			assert.Equal(t, int32(4), *versionID)
+			return &v1alpha1.RevisionMetadata{Author: "Bob", Message: "Add redis"}, nil
+		})
+
+	result, err := getApplicationHistoryHandler(context.Background(), mockClient, "guestbook", true)
+	require.NoError(t, err)
+	require.False(t, result.IsError, result.Content)
+
+	var history ApplicationHistory
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &history))
+	assert.Equal(t, int64(4), history.Current)
+	require.Len(t, history.History, 4)
+
+	ids := make([]int64, len(history.History))
+	for i, entry := range history.History {
+		ids[i] = entry.ID
+	}
+	assert.Equal(t, []int64{4, 3, 2, 1}, ids, "newest first")
+
+	assert.Equal(t, []HistorySource{
+		{RepoURL: "https://github.com/example/guestbook", Path: "guestbook", Revision: "bbb222", Author: "Bob", Message: "Add redis"},
+		{RepoURL: "https://charts.example.com", Chart: "redis", Revision: "18.0.1"},
+	}, history.History[0].Sources)
+	assert.Equal(t, "automated", history.History[1].InitiatedBy)
+	assert.Equal(t, "Scale the frontend", history.History[1].Sources[0].Message)
+	assert.Equal(t, "2026-01-01T00:00:00Z", history.History[1].Sources[0].Date)
+	assert.Contains(t, history.History[2].Sources[0].MetadataError, "revision not found")
+	assert.Equal(t, "alice", history.History[3].InitiatedBy)
+	assert.Equal(t, "2026-01-02T03:10:00Z", history.History[3].DeployedAt)
+	assert.Equal(t, "Alice <alice@example.com>", history.History[3].Sources[0].Author)
+}
+
+func TestGetApplicationHistoryHandler_WithoutMetadata(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	app := testApplication(v1alpha1.SyncStatusCodeSynced, health.HealthStatusHealthy)
+	app.Status.History = v1alpha1.RevisionHistories{deployment(1, "aaa111", 10)}
+	mockClient := mock.NewMockInterface(ctrl)
+	mockClient.EXPECT().GetApplication(gomock.Any(), "guestbook").Return(&app, nil)
+
+	result, err := getApplicationHistoryHandler(context.Background(), mockClient, "guestbook", false)
+	require.NoError(t, err)
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, `"revision": "aaa111"`)
+	assert.NotContains(t, text, "author")
+
+	result, err = getApplicationHistoryHandler(context.Background(), mockClient, "", false)
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
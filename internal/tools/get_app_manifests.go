@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client"
@@ -21,32 +20,26 @@ var GetAppManifestsTool = mcp.NewTool("get_application_manifests",
 	mcp.WithString("revision",
 		mcp.Description("The git revision to retrieve manifests for. If not specified, uses the currently deployed revision."),
 	),
+	withPagination(),
 )
 
 // HandleGetApplicationManifests processes get_application_manifests tool requests
-func HandleGetApplicationManifests(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func (r *Registry) HandleGetApplicationManifests(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	// Extract parameters from mcp.CallToolRequest
 	appName := request.GetString("name", "")
 	revision := request.GetString("revision", "")
 
-	// Create gRPC client
-	config := &client.Config{
-		ServerAddr:      os.Getenv("ARGOCD_SERVER"),
-		AuthToken:       os.Getenv("ARGOCD_AUTH_TOKEN"),
-		Insecure:        os.Getenv("ARGOCD_INSECURE") == "true",
-		PlainText:       os.Getenv("ARGOCD_PLAINTEXT") == "true",
-		GRPCWeb:         os.Getenv("ARGOCD_GRPC_WEB") == "true",
-		GRPCWebRootPath: os.Getenv("ARGOCD_GRPC_WEB_ROOT_PATH"),
-	}
-
-	argoClient, err := client.New(config)
+	// Get the gRPC client for this call
+	argoClient, err := r.client(ctx, request)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to create gRPC client: %v", err)), nil
 	}
 	defer func() { _ = argoClient.Close() }()
 
 	// Use the handler function with the real client
-	return getApplicationManifestsHandler(ctx, argoClient, appName, revision)
+	return r.paginate(ctx, request, pageSpec{field: "manifests"}, func() (*mcp.CallToolResult, error) {
+		return getApplicationManifestsHandler(ctx, argoClient, appName, revision)
+	})
 }
 
 // getApplicationManifestsHandler handles the core logic for getting application manifests.
@@ -58,7 +51,7 @@ func getApplicationManifestsHandler(
 	revision string,
 ) (*mcp.CallToolResult, error) {
 	if appName == "" {
-		return mcp.NewToolResultError("Application name is required"), nil
+		return invalidArgument(ctx, "Application name is required"), nil
 	}
 
 	manifests, err := argoClient.GetApplicationManifests(ctx, appName, revision)
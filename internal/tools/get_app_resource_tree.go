@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client"
@@ -24,37 +23,31 @@ var GetApplicationResourceTreeTool = mcp.NewTool("get_application_resource_tree"
 	mcp.WithString("project",
 		mcp.Description("Project of the application (optional, will be auto-detected if not provided)"),
 	),
+	withPagination(),
 )
 
 // HandleGetApplicationResourceTree processes get_application_resource_tree tool requests
-func HandleGetApplicationResourceTree(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func (r *Registry) HandleGetApplicationResourceTree(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	// Extract parameters
 	name := request.GetString("name", "")
 	if name == "" {
-		return mcp.NewToolResultError("name is required"), nil
+		return invalidArgument(ctx, "name is required"), nil
 	}
 
 	appNamespace := request.GetString("app_namespace", "")
 	project := request.GetString("project", "")
 
-	// Create gRPC client
-	config := &client.Config{
-		ServerAddr:      os.Getenv("ARGOCD_SERVER"),
-		AuthToken:       os.Getenv("ARGOCD_AUTH_TOKEN"),
-		Insecure:        os.Getenv("ARGOCD_INSECURE") == "true",
-		PlainText:       os.Getenv("ARGOCD_PLAINTEXT") == "true",
-		GRPCWeb:         os.Getenv("ARGOCD_GRPC_WEB") == "true",
-		GRPCWebRootPath: os.Getenv("ARGOCD_GRPC_WEB_ROOT_PATH"),
-	}
-
-	argoClient, err := client.New(config)
+	// Get the gRPC client for this call
+	argoClient, err := r.client(ctx, request)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to create gRPC client: %v", err)), nil
 	}
 	defer func() { _ = argoClient.Close() }()
 
 	// Use the handler function with the real client
-	return getApplicationResourceTreeHandler(ctx, argoClient, name, appNamespace, project)
+	return r.paginate(ctx, request, pageSpec{field: "nodes"}, func() (*mcp.CallToolResult, error) {
+		return getApplicationResourceTreeHandler(ctx, argoClient, name, appNamespace, project)
+	})
 }
 
 // getApplicationResourceTreeHandler handles the core logic for the tool.
@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"os"
 	"strings"
 
 	"github.com/mark3labs/mcp-go/mcp"
@@ -61,14 +60,15 @@ var GetApplicationLogsToolDefinition = mcp.NewTool("get_application_logs",
 	mcp.WithString("project",
 		mcp.Description("Optional. The ArgoCD project the application belongs to"),
 	),
+	withPagination(),
 )
 
 // HandleGetApplicationLogs processes get_application_logs tool requests
-func HandleGetApplicationLogs(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func (r *Registry) HandleGetApplicationLogs(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	// Extract required parameters
 	name := request.GetString("name", "")
 	if name == "" {
-		return mcp.NewToolResultError("name is required"), nil
+		return invalidArgument(ctx, "name is required"), nil
 	}
 
 	// Extract optional parameters
@@ -83,7 +83,7 @@ func HandleGetApplicationLogs(ctx context.Context, request mcp.CallToolRequest)
 	project := request.GetString("project", "")
 
 	// Extract numeric parameters with defaults
-	tailLines := int64(request.GetInt("tail_lines", 100))
+	tailLines := int64(request.GetInt("tail_lines", r.config.DefaultLogTailLines))
 
 	var sinceSeconds *int64
 	if ss := request.GetInt("since_seconds", 0); ss > 0 {
@@ -95,26 +95,19 @@ func HandleGetApplicationLogs(ctx context.Context, request mcp.CallToolRequest)
 	follow := request.GetBool("follow", false)
 	previous := request.GetBool("previous", false)
 
-	// Create gRPC client
-	config := &client.Config{
-		ServerAddr:      os.Getenv("ARGOCD_SERVER"),
-		AuthToken:       os.Getenv("ARGOCD_AUTH_TOKEN"),
-		Insecure:        os.Getenv("ARGOCD_INSECURE") == "true",
-		PlainText:       os.Getenv("ARGOCD_PLAINTEXT") == "true",
-		GRPCWeb:         os.Getenv("ARGOCD_GRPC_WEB") == "true",
-		GRPCWebRootPath: os.Getenv("ARGOCD_GRPC_WEB_ROOT_PATH"),
-	}
-
-	argoClient, err := client.New(config)
+	// Get the gRPC client for this call
+	argoClient, err := r.client(ctx, request)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to create gRPC client: %v", err)), nil
 	}
 	defer func() { _ = argoClient.Close() }()
 
 	// Use the handler function with the real client
-	return getApplicationLogsHandler(ctx, argoClient, name, podName, container, namespace,
-		resourceName, kind, group, tailLines, sinceSeconds, follow, previous, filter,
-		appNamespace, project)
+	return r.paginate(ctx, request, pageSpec{field: "logs"}, func() (*mcp.CallToolResult, error) {
+		return getApplicationLogsHandler(ctx, argoClient, name, podName, container, namespace,
+			resourceName, kind, group, tailLines, sinceSeconds, follow, previous, filter,
+			appNamespace, project)
+	})
 }
 
 // getApplicationLogsHandler handles the core logic for retrieving application logs.
@@ -157,6 +150,8 @@ func getApplicationLogsHandler(
 		PodName     string     `json:"pod_name,omitempty"`
 		Container   string     `json:"container,omitempty"`
 		TotalLines  int        `json:"total_lines"`
+		Cancelled   bool       `json:"cancelled,omitempty"`
+		Note        string     `json:"note,omitempty"`
 		Logs        []LogEntry `json:"logs"`
 	}
 
@@ -173,6 +168,12 @@ func getApplicationLogsHandler(
 		if err == io.EOF {
 			break
 		}
+		if err != nil && ctx.Err() != nil {
+			// The call was cancelled, so return what was read until then
+			response.Cancelled = true
+			response.Note = fmt.Sprintf("The log stream was cancelled (%v); the logs are incomplete.", context.Cause(ctx))
+			break
+		}
 		if err != nil {
 			// If we have some logs already, return them with a warning
 			if len(response.Logs) > 0 {
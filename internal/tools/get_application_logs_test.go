@@ -5,12 +5,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"testing"
+	"time"
 
 	applicationpkg "github.com/argoproj/argo-cd/v2/pkg/apiclient/application"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client/mock"
+	serverpkg "github.com/toyamagu-2021/argocd-mcp-server/internal/server"
 	"go.uber.org/mock/gomock"
 )
 
@@ -59,13 +61,11 @@ func TestHandleGetApplicationLogs(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Set environment variables
-			for k, v := range tt.envVars {
-				t.Setenv(k, v)
-			}
+			// Build a registry configured from the test settings
+			registry := newSettingsRegistry(tt.envVars)
 
 			// Execute handler
-			result, err := HandleGetApplicationLogs(context.Background(), tt.request)
+			result, err := registry.HandleGetApplicationLogs(context.Background(), tt.request)
 
 			// Check expectations
 			if tt.wantError {
@@ -402,3 +402,42 @@ func TestGetApplicationLogs_ParameterExtraction(t *testing.T) {
 		})
 	}
 }
+
+// followLogStream streams entries and then blocks like a followed log until ctx is done
+type followLogStream struct {
+	ctx     context.Context
+	entries []*applicationpkg.LogEntry
+}
+
+func (s *followLogStream) Recv() (*applicationpkg.LogEntry, error) {
+	if len(s.entries) > 0 {
+		entry := s.entries[0]
+		s.entries = s.entries[1:]
+		return entry, nil
+	}
+	<-s.ctx.Done()
+	return nil, s.ctx.Err()
+}
+
+func TestGetApplicationLogsHandler_Cancelled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	content := "Log line 1"
+	mockClient := mock.NewMockInterface(ctrl)
+	mockClient.EXPECT().GetApplicationLogs(gomock.Any(), "test-app", "", "", "", "", "", "", int64(100), nil, true, false, "", "", "").
+		Return(&followLogStream{ctx: ctx, entries: []*applicationpkg.LogEntry{{Content: &content}}}, nil)
+
+	// The client cancels the call while the log is followed
+	time.AfterFunc(50*time.Millisecond, func() { cancel(serverpkg.ErrCancelled) })
+	result, err := getApplicationLogsHandler(ctx, mockClient, "test-app", "", "", "", "", "", "", 100, nil, true, false, "", "", "")
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &response))
+	assert.Equal(t, true, response["cancelled"])
+	assert.Contains(t, response["note"], "request cancelled by the client")
+	assert.EqualValues(t, 1, response["total_lines"])
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client"
@@ -21,22 +20,14 @@ var GetApplicationSetTool = mcp.NewTool("get_applicationset",
 )
 
 // HandleGetApplicationSet processes get_applicationset tool requests
-func HandleGetApplicationSet(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func (r *Registry) HandleGetApplicationSet(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	name := request.GetString("name", "")
 	if name == "" {
-		return mcp.NewToolResultError("name is required"), nil
+		return invalidArgument(ctx, "name is required"), nil
 	}
 
-	config := &client.Config{
-		ServerAddr:      os.Getenv("ARGOCD_SERVER"),
-		AuthToken:       os.Getenv("ARGOCD_AUTH_TOKEN"),
-		Insecure:        os.Getenv("ARGOCD_INSECURE") == "true",
-		PlainText:       os.Getenv("ARGOCD_PLAINTEXT") == "true",
-		GRPCWeb:         os.Getenv("ARGOCD_GRPC_WEB") == "true",
-		GRPCWebRootPath: os.Getenv("ARGOCD_GRPC_WEB_ROOT_PATH"),
-	}
-
-	argoClient, err := client.New(config)
+	// Get the gRPC client for this call
+	argoClient, err := r.client(ctx, request)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to create gRPC client: %v", err)), nil
 	}
@@ -52,7 +43,7 @@ func getApplicationSetHandler(
 	argoClient client.Interface,
 	name string,
 ) (*mcp.CallToolResult, error) {
-	appSet, err := argoClient.GetApplicationSet(ctx, name)
+	appSet, err := argoClient.GetApplicationSet(ctx, name, "")
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to get ApplicationSet: %v", err)), nil
 	}
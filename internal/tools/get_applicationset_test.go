@@ -75,13 +75,11 @@ func TestHandleGetApplicationSet(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Set environment variables
-			for k, v := range tt.envVars {
-				t.Setenv(k, v)
-			}
+			// Build a registry configured from the test settings
+			registry := newSettingsRegistry(tt.envVars)
 
 			// Execute handler
-			result, err := HandleGetApplicationSet(context.Background(), tt.request)
+			result, err := registry.HandleGetApplicationSet(context.Background(), tt.request)
 
 			// Check expectations
 			if tt.wantError {
@@ -183,7 +181,7 @@ func TestGetApplicationSetHandler(t *testing.T) {
 						},
 					},
 				}
-				m.EXPECT().GetApplicationSet(gomock.Any(), "test-appset").Return(appSet, nil)
+				m.EXPECT().GetApplicationSet(gomock.Any(), "test-appset", "").Return(appSet, nil)
 			},
 			wantError:   false,
 			wantMessage: "test-appset",
@@ -192,7 +190,7 @@ func TestGetApplicationSetHandler(t *testing.T) {
 			name:       "applicationset not found",
 			appSetName: "non-existent",
 			setupMock: func(m *mock.MockInterface) {
-				m.EXPECT().GetApplicationSet(gomock.Any(), "non-existent").Return(nil, assert.AnError)
+				m.EXPECT().GetApplicationSet(gomock.Any(), "non-existent", "").Return(nil, assert.AnError)
 			},
 			wantError:   true,
 			wantMessage: "Failed to get ApplicationSet",
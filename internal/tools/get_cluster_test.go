@@ -72,11 +72,9 @@ func TestHandleGetCluster(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			for k, v := range tt.envVars {
-				t.Setenv(k, v)
-			}
+			registry := newSettingsRegistry(tt.envVars)
 
-			result, err := HandleGetCluster(context.Background(), tt.request)
+			result, err := registry.HandleGetCluster(context.Background(), tt.request)
 
 			if tt.wantError {
 				require.Nil(t, err)
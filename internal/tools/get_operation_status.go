@@ -0,0 +1,226 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	synccommon "github.com/argoproj/gitops-engine/pkg/sync/common"
+	"github.com/argoproj/gitops-engine/pkg/sync/syncwaves"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// GetOperationStatusTool defines the get_operation_status tool schema
+var GetOperationStatusTool = mcp.NewTool("get_operation_status",
+	mcp.WithDescription("Reports the current or last operation (sync, rollback) of an ArgoCD application: its phase, message, start and finish times and synced revision, and the result of each resource and hook in execution order: sorted by sync phase, then by wave, and only within each wave are failed entries listed first, so failures of later waves appear further down. failed counts the failed entries and stoppedAt names the first wave that failed. Waves are read from the application as it is now, so they may differ from those of the operation if its manifests changed since."),
+	mcp.WithDestructiveHintAnnotation(false),
+	mcp.WithString("name",
+		mcp.Required(),
+		mcp.Description("The name of the application."),
+	),
+	withPagination(),
+)
+
+// HandleGetOperationStatus processes get_operation_status tool requests
+func (r *Registry) HandleGetOperationStatus(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	appName := request.GetString("name", "")
+
+	// Get the gRPC client for this call
+	argoClient, err := r.client(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to create gRPC client: %v", err)), nil
+	}
+	defer func() { _ = argoClient.Close() }()
+
+	return r.paginate(ctx, request, pageSpec{field: "resources"}, func() (*mcp.CallToolResult, error) {
+		return getOperationStatusHandler(ctx, argoClient, appName)
+	})
+}
+
+// OperationStatus reports the operation of an application
+type OperationStatus struct {
+	Application string              `json:"application"`
+	Phase       string              `json:"phase"`
+	Message     string              `json:"message,omitempty"`
+	InitiatedBy string              `json:"initiatedBy,omitempty"`
+	StartedAt   string              `json:"startedAt,omitempty"`
+	FinishedAt  string              `json:"finishedAt,omitempty"`
+	RetryCount  int64               `json:"retryCount,omitempty"`
+	Revision    string              `json:"revision,omitempty"`
+	Revisions   []string            `json:"revisions,omitempty"`
+	Failed      int                 `json:"failed"`
+	StoppedAt   *SyncWave           `json:"stoppedAt,omitempty"`
+	Resources   []OperationResource `json:"resources"`
+}
+
+// SyncWave identifies a wave of a sync phase
+type SyncWave struct {
+	Phase string `json:"phase"`
+	Wave  int    `json:"wave"`
+}
+
+// OperationResource is the result of a resource or hook in an operation
+type OperationResource struct {
+	Phase     string `json:"phase"`
+	Wave      int    `json:"wave"`
+	Group     string `json:"group,omitempty"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	HookType  string `json:"hookType,omitempty"`
+	HookPhase string `json:"hookPhase,omitempty"`
+	Message   string `json:"message,omitempty"`
+	failed    bool
+}
+
+// syncPhases lists the phases of a sync in the order they run
+var syncPhases = []synccommon.SyncPhase{synccommon.SyncPhasePreSync, synccommon.SyncPhaseSync, synccommon.SyncPhasePostSync, synccommon.SyncPhaseSyncFail}
+
+// getOperationStatusHandler handles the core logic for reporting the operation of an application.
+// This is separated out to enable testing with mocked clients.
+func getOperationStatusHandler(
+	ctx context.Context,
+	argoClient client.Interface,
+	appName string,
+) (*mcp.CallToolResult, error) {
+	if appName == "" {
+		return invalidArgument(ctx, "Application name is required"), nil
+	}
+
+	app, err := argoClient.GetApplication(ctx, appName)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get application: %v", err)), nil
+	}
+	state := app.Status.OperationState
+	if state == nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Application %s has not run an operation", appName)), nil
+	}
+
+	status := OperationStatus{
+		Application: app.Name,
+		Phase:       string(state.Phase),
+		Message:     state.Message,
+		InitiatedBy: initiator(state.Operation.InitiatedBy),
+		StartedAt:   state.StartedAt.Format(time.RFC3339),
+		RetryCount:  state.RetryCount,
+		Resources:   []OperationResource{},
+	}
+	if state.FinishedAt != nil {
+		status.FinishedAt = state.FinishedAt.Format(time.RFC3339)
+	}
+	if sync := state.Operation.Sync; sync != nil {
+		status.Revision, status.Revisions = sync.Revision, sync.Revisions
+	}
+	if state.SyncResult == nil {
+		return operationStatusResult(status)
+	}
+	if state.SyncResult.Revision != "" || len(state.SyncResult.Revisions) > 0 {
+		status.Revision, status.Revisions = state.SyncResult.Revision, state.SyncResult.Revisions
+	}
+
+	waves, err := resourceWaves(ctx, argoClient, app, state.SyncResult.Resources)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get managed resources: %v", err)), nil
+	}
+	for _, res := range state.SyncResult.Resources {
+		phase := res.SyncPhase
+		if phase == "" {
+			phase = synccommon.SyncPhaseSync
+		}
+		// Hook manifests may leave the namespace to the destination of the application
+		wave, ok := waves[resourceKey(res.Group, res.Kind, res.Namespace, res.Name)]
+		if !ok {
+			wave = waves[resourceKey(res.Group, res.Kind, "", res.Name)]
+		}
+		entry := OperationResource{
+			Phase:     string(phase),
+			Wave:      wave,
+			Group:     res.Group,
+			Kind:      res.Kind,
+			Namespace: res.Namespace,
+			Name:      res.Name,
+			Status:    resourceResultStatus(res),
+			HookType:  string(res.HookType),
+			HookPhase: string(res.HookPhase),
+			Message:   res.Message,
+			failed:    resourceResultFailed(res),
+		}
+		if entry.failed {
+			status.Failed++
+		}
+		status.Resources = append(status.Resources, entry)
+	}
+
+	// Waves run in phase order, and failures lead each wave
+	slices.SortStableFunc(status.Resources, func(a, b OperationResource) int {
+		pa, pb := slices.Index(syncPhases, synccommon.SyncPhase(a.Phase)), slices.Index(syncPhases, synccommon.SyncPhase(b.Phase))
+		switch {
+		case pa != pb:
+			return pa - pb
+		case a.Wave != b.Wave:
+			return a.Wave - b.Wave
+		case a.failed != b.failed:
+			if a.failed {
+				return -1
+			}
+			return 1
+		}
+		return 0
+	})
+	for _, res := range status.Resources {
+		if res.failed {
+			status.StoppedAt = &SyncWave{Phase: res.Phase, Wave: res.Wave}
+			break
+		}
+	}
+	return operationStatusResult(status)
+}
+
+func operationStatusResult(status OperationStatus) (*mcp.CallToolResult, error) {
+	jsonData, err := json.MarshalIndent(status, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to format response: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// resourceWaves returns the sync waves of the resources of a sync result by
+// resourceKey. Resources take theirs from the application status; hooks are not
+// part of it, so theirs are read from their manifests. Both reflect the current
+// desired state, not the one the operation synced, so a wave changed in Git
+// after the sync is reported with its new value.
+func resourceWaves(ctx context.Context, argoClient client.Interface, app *v1alpha1.Application, results v1alpha1.ResourceResults) (map[string]int, error) {
+	waves := make(map[string]int)
+	for _, res := range app.Status.Resources {
+		waves[resourceKey(res.Group, res.Kind, res.Namespace, res.Name)] = int(res.SyncWave)
+	}
+	if !slices.ContainsFunc(results, func(res *v1alpha1.ResourceResult) bool { return res.HookType != "" }) {
+		return waves, nil
+	}
+
+	resources, err := argoClient.GetManagedResources(ctx, app.Name, "", "", "")
+	if err != nil {
+		return nil, err
+	}
+	for _, res := range resources {
+		if !res.Hook || emptyManifest(res.TargetState) {
+			continue
+		}
+		var obj unstructured.Unstructured
+		if err := obj.UnmarshalJSON([]byte(res.TargetState)); err != nil {
+			continue
+		}
+		waves[resourceKey(res.Group, res.Kind, res.Namespace, res.Name)] = syncwaves.Wave(&obj)
+	}
+	return waves, nil
+}
+
+func resourceKey(group, kind, namespace, name string) string {
+	return group + "/" + kind + "/" + namespace + "/" + name
+}
@@ -0,0 +1,142 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/gitops-engine/pkg/health"
+	synccommon "github.com/argoproj/gitops-engine/pkg/sync/common"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client/mock"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetOperationStatusHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	app := operatingApplication(synccommon.OperationFailed, v1alpha1.SyncStatusCodeOutOfSync, health.HealthStatusDegraded,
+		&v1alpha1.ResourceResult{Kind: "Service", Namespace: "default", Name: "guestbook-ui", Status: synccommon.ResultCodeSynced, SyncPhase: synccommon.SyncPhaseSync},
+		&v1alpha1.ResourceResult{Group: "apps", Kind: "Deployment", Namespace: "default", Name: "guestbook-ui", Status: synccommon.ResultCodeSyncFailed, Message: "image pull failed", SyncPhase: synccommon.SyncPhaseSync},
+		&v1alpha1.ResourceResult{Kind: "ConfigMap", Namespace: "default", Name: "settings", Status: synccommon.ResultCodeSynced, SyncPhase: synccommon.SyncPhaseSync},
+		&v1alpha1.ResourceResult{Group: "batch", Kind: "Job", Namespace: "default", Name: "migrate", HookType: synccommon.HookTypePreSync, HookPhase: synccommon.OperationSucceeded, SyncPhase: synccommon.SyncPhasePreSync},
+	)
+	app.Status.OperationState.Message = "one or more objects failed to apply"
+	app.Status.OperationState.Operation.InitiatedBy = v1alpha1.OperationInitiator{Username: "alice"}
+	app.Status.Resources = []v1alpha1.ResourceStatus{
+		{Kind: "ConfigMap", Namespace: "default", Name: "settings", SyncWave: -1},
+		{Kind: "Service", Namespace: "default", Name: "guestbook-ui", SyncWave: 1},
+		{Group: "apps", Kind: "Deployment", Namespace: "default", Name: "guestbook-ui", SyncWave: 1},
+	}
+
+	mockClient := mock.NewMockInterface(ctrl)
+	mockClient.EXPECT().GetApplication(gomock.Any(), "guestbook").Return(&app, nil)
+	// Hooks are not in the application status, so their waves come from their manifests
+	mockClient.EXPECT().GetManagedResources(gomock.Any(), "guestbook", "", "", "").Return([]*v1alpha1.ResourceDiff{
+		{Group: "batch", Kind: "Job", Name: "migrate", Hook: true,
+			TargetState: `{"apiVersion":"batch/v1","kind":"Job","metadata":{"name":"migrate","annotations":{"argocd.argoproj.io/hook":"PreSync","argocd.argoproj.io/sync-wave":"-5"}}}`},
+	}, nil)
+
+	result, err := getOperationStatusHandler(context.Background(), mockClient, "guestbook")
+	require.NoError(t, err)
+	status := decodeResult[OperationStatus](t, result)
+
+	assert.Equal(t, "Failed", status.Phase)
+	assert.Equal(t, "one or more objects failed to apply", status.Message)
+	assert.Equal(t, "alice", status.InitiatedBy)
+	assert.Equal(t, "2026-01-02T03:04:05Z", status.StartedAt)
+	assert.Equal(t, "2026-01-02T03:05:05Z", status.FinishedAt)
+	assert.Equal(t, "abc123", status.Revision)
+	assert.Equal(t, 1, status.Failed)
+	assert.Equal(t, &SyncWave{Phase: "Sync", Wave: 1}, status.StoppedAt)
+
+	// Resources run by phase and wave, and failures lead their wave
+	type row struct {
+		Phase  string
+		Wave   int
+		Kind   string
+		Status string
+	}
+	rows := make([]row, len(status.Resources))
+	for i, res := range status.Resources {
+		rows[i] = row{res.Phase, res.Wave, res.Kind, res.Status}
+	}
+	assert.Equal(t, []row{
+		{"PreSync", -5, "Job", "Succeeded"},
+		{"Sync", -1, "ConfigMap", "Synced"},
+		{"Sync", 1, "Deployment", "SyncFailed"},
+		{"Sync", 1, "Service", "Synced"},
+	}, rows)
+	assert.Equal(t, "PreSync", status.Resources[0].HookType)
+	assert.Equal(t, "image pull failed", status.Resources[2].Message)
+}
+
+func TestGetOperationStatusHandler_Running(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Without hooks the managed resources are not looked up
+	app := operatingApplication(synccommon.OperationRunning, v1alpha1.SyncStatusCodeOutOfSync, health.HealthStatusProgressing,
+		&v1alpha1.ResourceResult{Kind: "Service", Namespace: "default", Name: "guestbook-ui", Status: synccommon.ResultCodeSynced})
+	mockClient := mock.NewMockInterface(ctrl)
+	mockClient.EXPECT().GetApplication(gomock.Any(), "guestbook").Return(&app, nil)
+
+	result, err := getOperationStatusHandler(context.Background(), mockClient, "guestbook")
+	require.NoError(t, err)
+	status := decodeResult[OperationStatus](t, result)
+	assert.Equal(t, "Running", status.Phase)
+	assert.Empty(t, status.FinishedAt)
+	assert.Nil(t, status.StoppedAt)
+	require.Len(t, status.Resources, 1)
+	assert.Equal(t, OperationResource{Phase: "Sync", Kind: "Service", Namespace: "default", Name: "guestbook-ui", Status: "Synced"}, status.Resources[0])
+}
+
+func TestGetOperationStatusHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name          string
+		appName       string
+		setup         func(*mock.MockInterface)
+		errorContains string
+	}{
+		{
+			name:          "missing name",
+			setup:         func(*mock.MockInterface) {},
+			errorContains: "Application name is required",
+		},
+		{
+			name:    "no operation",
+			appName: "guestbook",
+			setup: func(m *mock.MockInterface) {
+				app := testApplication(v1alpha1.SyncStatusCodeSynced, health.HealthStatusHealthy)
+				m.EXPECT().GetApplication(gomock.Any(), "guestbook").Return(&app, nil)
+			},
+			errorContains: "Application guestbook has not run an operation",
+		},
+		{
+			name:    "get application fails",
+			appName: "guestbook",
+			setup: func(m *mock.MockInterface) {
+				m.EXPECT().GetApplication(gomock.Any(), "guestbook").Return(nil, errors.New("not found"))
+			},
+			errorContains: "Failed to get application: not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockClient := mock.NewMockInterface(ctrl)
+			tt.setup(mockClient)
+			result, err := getOperationStatusHandler(context.Background(), mockClient, tt.appName)
+			require.NoError(t, err)
+			assert.True(t, result.IsError)
+			assert.Contains(t, result.Content[0].(mcp.TextContent).Text, tt.errorContains)
+		})
+	}
+}
@@ -41,13 +41,11 @@ func TestHandleGetUserInfo(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Set environment variables
-			for k, v := range tt.envVars {
-				t.Setenv(k, v)
-			}
+			// Build a registry configured from the test settings
+			registry := newSettingsRegistry(tt.envVars)
 
 			// Execute handler
-			result, err := HandleGetUserInfo(context.Background(), tt.request)
+			result, err := registry.HandleGetUserInfo(context.Background(), tt.request)
 
 			// Check expectations
 			if tt.wantError {
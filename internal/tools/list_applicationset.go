@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
 	"strings"
 
 	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
@@ -22,29 +21,24 @@ var ListApplicationSetTool = mcp.NewTool("list_applicationset",
 	mcp.WithString("selector",
 		mcp.Description("Filter ApplicationSets by a label selector (e.g., 'key=value')."),
 	),
+	withPagination(),
 )
 
 // HandleListApplicationSets processes list_applicationset tool requests
-func HandleListApplicationSets(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func (r *Registry) HandleListApplicationSets(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	project := request.GetString("project", "")
 	selector := request.GetString("selector", "")
 
-	config := &client.Config{
-		ServerAddr:      os.Getenv("ARGOCD_SERVER"),
-		AuthToken:       os.Getenv("ARGOCD_AUTH_TOKEN"),
-		Insecure:        os.Getenv("ARGOCD_INSECURE") == "true",
-		PlainText:       os.Getenv("ARGOCD_PLAINTEXT") == "true",
-		GRPCWeb:         os.Getenv("ARGOCD_GRPC_WEB") == "true",
-		GRPCWebRootPath: os.Getenv("ARGOCD_GRPC_WEB_ROOT_PATH"),
-	}
-
-	argoClient, err := client.New(config)
+	// Get the gRPC client for this call
+	argoClient, err := r.client(ctx, request)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to create gRPC client: %v", err)), nil
 	}
 	defer func() { _ = argoClient.Close() }()
 
-	return listApplicationSetsHandler(ctx, argoClient, project, selector)
+	return r.paginate(ctx, request, pageSpec{}, func() (*mcp.CallToolResult, error) {
+		return listApplicationSetsHandler(ctx, argoClient, project, selector)
+	})
 }
 
 // listApplicationSetsHandler handles the core logic for listing ApplicationSets.
@@ -66,7 +60,7 @@ func listApplicationSetsHandler(
 	if selector != "" {
 		parsedSelector, err := parseSelector(selector)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Invalid selector: %v", err)), nil
+			return invalidArgument(ctx, fmt.Sprintf("Invalid selector: %v", err)), nil
 		}
 		for _, appSet := range appSets {
 			if matchesSelector(appSet.Labels, parsedSelector) {
@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
 	"strings"
 
 	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
@@ -35,15 +34,20 @@ var ListAppsTool = mcp.NewTool("list_application",
 		mcp.Description("If true, returns only application names. Takes precedence over 'detailed' option. Useful for getting a quick list of application names."),
 	),
 	mcp.WithString("output_format",
-		mcp.Description("Output format for the response. Options: 'tsv' (default), 'json'. TSV format reduces response size by ~50% for large datasets."),
+		mcp.Description("Output format for the response. Options: 'tsv' (default), 'json', 'json_compact', 'yaml', 'markdown'. TSV format reduces response size by ~50% for large datasets."),
+		mcp.Enum(outputFormats...),
 	),
 	mcp.WithString("optional_fields",
-		mcp.Description("Comma-separated additional fields to include in TSV output. Available options: 'namespace', 'source' (includes repoURL, path, targetRevision, chart), 'destination' (includes server, namespace), 'operation' (includes phase, message, startedAt), or individual fields like 'source-repo', 'dest-namespace'. Defaults to minimal output (name, project, syncStatus, healthStatus)."),
+		mcp.Description("Deprecated: use 'fields'. Ignored when 'fields' is given, which selects from the JSON summary instead. Comma-separated additional fields to include in TSV output. Available options: 'namespace', 'source' (includes repoURL, path, targetRevision, chart), 'destination' (includes server, namespace), 'operation' (includes phase, message, startedAt), or individual fields like 'source-repo', 'dest-namespace'. Defaults to minimal output (name, project, syncStatus, healthStatus)."),
 	),
+	mcp.WithBoolean("all_instances",
+		mcp.Description("If true, lists applications across all configured ArgoCD instances and labels each row with its instance. Instances that fail are reported after the results."),
+	),
+	withPagination(),
 )
 
 // HandleListApplications processes list_application tool requests
-func HandleListApplications(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func (r *Registry) HandleListApplications(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	// Safely extract optional parameters from mcp.CallToolRequest
 	project := request.GetString("project", "")
 	cluster := request.GetString("cluster", "")
@@ -62,28 +66,52 @@ func HandleListApplications(ctx context.Context, request mcp.CallToolRequest) (*
 		}
 	}
 
-	// Create gRPC client and list applications
-	config := &client.Config{
-		ServerAddr:      os.Getenv("ARGOCD_SERVER"),
-		AuthToken:       os.Getenv("ARGOCD_AUTH_TOKEN"),
-		Insecure:        os.Getenv("ARGOCD_INSECURE") == "true",
-		PlainText:       os.Getenv("ARGOCD_PLAINTEXT") == "true",
-		GRPCWeb:         os.Getenv("ARGOCD_GRPC_WEB") == "true",
-		GRPCWebRootPath: os.Getenv("ARGOCD_GRPC_WEB_ROOT_PATH"),
+	// Summary TSV output starts with a header line
+	spec := pageSpec{field: "names"}
+	if outputFormat == "tsv" && !detailed && !nameOnly {
+		spec.header = 1
+	}
+	// TSV and JSON without selected fields keep their own rendering; the other
+	// formats are rendered from the JSON output like those of every other tool
+	if (outputFormat == OutputFormatTSV || outputFormat == OutputFormatJSON) && request.GetString("fields", "") == "" {
+		spec.raw = true
+	} else {
+		outputFormat = OutputFormatJSON
+		spec.header = 0
+	}
+
+	if request.GetBool("all_instances", false) {
+		if request.GetString(InstanceArgument, "") != "" {
+			return invalidArgument(ctx, "all_instances and instance cannot be used together"), nil
+		}
+		results := forEachInstance(ctx, r, r.clients.Instances(), func(ctx context.Context, argoClient client.Interface) ([]v1alpha1.Application, error) {
+			appList, err := argoClient.ListApplications(ctx, selector)
+			if err != nil {
+				return nil, err
+			}
+			return filterApplications(appList.Items, project, cluster, namespace), nil
+		})
+		return r.paginate(ctx, request, spec, func() (*mcp.CallToolResult, error) {
+			return listApplicationsAcrossInstancesHandler(results, detailed, nameOnly, outputFormat, optionalFields)
+		})
 	}
 
-	argoClient, err := client.New(config)
+	// Get the gRPC client for this call
+	argoClient, err := r.client(ctx, request)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to create gRPC client: %v", err)), nil
 	}
 	defer func() { _ = argoClient.Close() }()
 
 	// Use the handler function with the real client
-	return listApplicationsHandler(ctx, argoClient, project, cluster, namespace, selector, detailed, nameOnly, outputFormat, optionalFields)
+	return r.paginate(ctx, request, spec, func() (*mcp.CallToolResult, error) {
+		return listApplicationsHandler(ctx, argoClient, project, cluster, namespace, selector, detailed, nameOnly, outputFormat, optionalFields)
+	})
 }
 
 // ApplicationSummary represents a simplified view of an application
 type ApplicationSummary struct {
+	Instance        string                 `json:"instance,omitempty"`
 	Name            string                 `json:"name"`
 	Namespace       string                 `json:"namespace"`
 	Project         string                 `json:"project"`
@@ -137,22 +165,7 @@ func listApplicationsHandler(
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to list applications: %v", err)), nil
 	}
 
-	apps := appList.Items
-
-	// Filter by project, cluster, namespace if specified
-	var filteredApps []v1alpha1.Application
-	for _, app := range apps {
-		if project != "" && app.Spec.Project != project {
-			continue
-		}
-		if cluster != "" && app.Spec.Destination.Server != cluster {
-			continue
-		}
-		if namespace != "" && app.Spec.Destination.Namespace != namespace {
-			continue
-		}
-		filteredApps = append(filteredApps, app)
-	}
+	filteredApps := filterApplications(appList.Items, project, cluster, namespace)
 
 	if len(filteredApps) == 0 {
 		return mcp.NewToolResultText("No applications found matching the criteria."), nil
@@ -196,57 +209,184 @@ func listApplicationsHandler(
 		// Return summarized application information
 		summaries := make([]ApplicationSummary, 0, len(filteredApps))
 		for _, app := range filteredApps {
-			summary := ApplicationSummary{
-				Name:      app.Name,
-				Namespace: app.Namespace,
-				Project:   app.Spec.Project,
-				Destination: ApplicationDestination{
-					Server:    app.Spec.Destination.Server,
-					Namespace: app.Spec.Destination.Namespace,
-				},
-			}
+			summaries = append(summaries, newApplicationSummary(app))
+		}
 
-			// Add source information if available
-			if app.Spec.Source != nil {
-				summary.Source = ApplicationSourceBrief{
-					RepoURL:        app.Spec.Source.RepoURL,
-					Path:           app.Spec.Source.Path,
-					TargetRevision: app.Spec.Source.TargetRevision,
-					Chart:          app.Spec.Source.Chart,
-				}
-			}
+		jsonData, err = json.MarshalIndent(summaries, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to format response: %v", err)), nil
+		}
+	}
 
-			// Add sync status if available
-			if app.Status.Sync.Status != "" {
-				summary.SyncStatus = string(app.Status.Sync.Status)
-			}
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
 
-			// Add health status if available
-			if app.Status.Health.Status != "" {
-				summary.HealthStatus = string(app.Status.Health.Status)
-			}
+// filterApplications keeps the applications matching the project, cluster and namespace filters
+func filterApplications(apps []v1alpha1.Application, project, cluster, namespace string) []v1alpha1.Application {
+	var filteredApps []v1alpha1.Application
+	for _, app := range apps {
+		if project != "" && app.Spec.Project != project {
+			continue
+		}
+		if cluster != "" && app.Spec.Destination.Server != cluster {
+			continue
+		}
+		if namespace != "" && app.Spec.Destination.Namespace != namespace {
+			continue
+		}
+		filteredApps = append(filteredApps, app)
+	}
+	return filteredApps
+}
+
+// newApplicationSummary builds the summarized view of an application
+func newApplicationSummary(app v1alpha1.Application) ApplicationSummary {
+	summary := ApplicationSummary{
+		Name:      app.Name,
+		Namespace: app.Namespace,
+		Project:   app.Spec.Project,
+		Destination: ApplicationDestination{
+			Server:    app.Spec.Destination.Server,
+			Namespace: app.Spec.Destination.Namespace,
+		},
+	}
+
+	// Add source information if available
+	if app.Spec.Source != nil {
+		summary.Source = ApplicationSourceBrief{
+			RepoURL:        app.Spec.Source.RepoURL,
+			Path:           app.Spec.Source.Path,
+			TargetRevision: app.Spec.Source.TargetRevision,
+			Chart:          app.Spec.Source.Chart,
+		}
+	}
+
+	// Add sync status if available
+	if app.Status.Sync.Status != "" {
+		summary.SyncStatus = string(app.Status.Sync.Status)
+	}
+
+	// Add health status if available
+	if app.Status.Health.Status != "" {
+		summary.HealthStatus = string(app.Status.Health.Status)
+	}
+
+	// Add operation status if an operation is in progress
+	if app.Status.OperationState != nil {
+		summary.OperationStatus = &ApplicationOperation{
+			Phase:   string(app.Status.OperationState.Phase),
+			Message: app.Status.OperationState.Message,
+		}
+		if !app.Status.OperationState.StartedAt.IsZero() {
+			summary.OperationStatus.StartedAt = app.Status.OperationState.StartedAt.String()
+		}
+	}
+
+	return summary
+}
+
+// InstanceApplication is a full application labeled with the instance it belongs to
+type InstanceApplication struct {
+	Instance string `json:"instance"`
+	v1alpha1.Application
+}
+
+// listApplicationsAcrossInstancesHandler renders the applications listed on several
+// instances, labeling each entry with its instance and reporting failed instances last
+func listApplicationsAcrossInstancesHandler(
+	results []instanceResult[[]v1alpha1.Application],
+	detailed bool,
+	nameOnly bool,
+	outputFormat string,
+	optionalFields []string,
+) (*mcp.CallToolResult, error) {
+	var failures []string
+	count := 0
+	for _, result := range results {
+		if result.err != nil {
+			failures = append(failures, fmt.Sprintf("Failed to list applications on instance %q: %v", result.instance, result.err))
+			continue
+		}
+		count += len(result.value)
+	}
+
+	if len(failures) == len(results) && len(results) > 0 {
+		return mcp.NewToolResultError(strings.Join(failures, "\n")), nil
+	}
 
-			// Add operation status if an operation is in progress
-			if app.Status.OperationState != nil {
-				summary.OperationStatus = &ApplicationOperation{
-					Phase:   string(app.Status.OperationState.Phase),
-					Message: app.Status.OperationState.Message,
+	var output string
+	switch {
+	case count == 0:
+		output = "No applications found matching the criteria."
+	case outputFormat == "tsv" && nameOnly:
+		var lines []string
+		for _, result := range results {
+			for _, app := range result.value {
+				lines = append(lines, escapeField(result.instance)+"\t"+escapeField(app.Name))
+			}
+		}
+		output = strings.Join(lines, "\n")
+	case outputFormat == "tsv" && !detailed:
+		var sb strings.Builder
+		fieldConfig := buildFieldConfig(optionalFields)
+		headers := append([]string{"instance"}, buildHeaders(fieldConfig)...)
+		sb.WriteString(strings.Join(headers, "\t") + "\n")
+		for _, result := range results {
+			for _, app := range result.value {
+				fields := append([]string{escapeField(result.instance)}, buildFieldValues(app, fieldConfig)...)
+				sb.WriteString(strings.Join(fields, "\t") + "\n")
+			}
+		}
+		output = sb.String()
+	default:
+		var value any
+		if nameOnly {
+			names := make([]string, 0, count)
+			for _, result := range results {
+				for _, app := range result.value {
+					names = append(names, result.instance+"/"+app.Name)
 				}
-				if !app.Status.OperationState.StartedAt.IsZero() {
-					summary.OperationStatus.StartedAt = app.Status.OperationState.StartedAt.String()
+			}
+			value = ApplicationNameList{Names: names, Count: len(names)}
+		} else if detailed {
+			apps := make([]InstanceApplication, 0, count)
+			for _, result := range results {
+				for _, app := range result.value {
+					apps = append(apps, InstanceApplication{Instance: result.instance, Application: app})
 				}
 			}
-
-			summaries = append(summaries, summary)
+			value = apps
+		} else {
+			summaries := make([]ApplicationSummary, 0, count)
+			for _, result := range results {
+				for _, app := range result.value {
+					summary := newApplicationSummary(app)
+					summary.Instance = result.instance
+					summaries = append(summaries, summary)
+				}
+			}
+			value = summaries
 		}
 
-		jsonData, err = json.MarshalIndent(summaries, "", "  ")
+		var jsonData []byte
+		var err error
+		if outputFormat == "tsv" {
+			// Detailed TSV output uses compact JSON, as in the single instance case
+			jsonData, err = json.Marshal(value)
+		} else {
+			jsonData, err = json.MarshalIndent(value, "", "  ")
+		}
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("Failed to format response: %v", err)), nil
 		}
+		output = string(jsonData)
 	}
 
-	return mcp.NewToolResultText(string(jsonData)), nil
+	result := mcp.NewToolResultText(output)
+	if len(failures) > 0 {
+		result.Content = append(result.Content, mcp.NewTextContent(strings.Join(failures, "\n")))
+	}
+	return result, nil
 }
 
 // FieldConfig represents which fields to include in TSV output
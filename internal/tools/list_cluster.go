@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
 
 	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -21,6 +20,7 @@ var ListClusterTool = mcp.NewTool("list_cluster",
 	mcp.WithBoolean("name_only",
 		mcp.Description("If true, returns only cluster names/servers. Takes precedence over 'detailed' option. Useful for getting a quick list of cluster identifiers."),
 	),
+	withPagination(),
 )
 
 // ClusterSummary represents a simplified view of a cluster
@@ -32,27 +32,26 @@ type ClusterSummary struct {
 }
 
 // HandleListCluster handles MCP tool requests for listing ArgoCD clusters
-func HandleListCluster(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func (r *Registry) HandleListCluster(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	// Extract the detailed and name_only parameters
 	detailed := request.GetBool("detailed", false)
 	nameOnly := request.GetBool("name_only", false)
 
-	config := &client.Config{
-		ServerAddr:      os.Getenv("ARGOCD_SERVER"),
-		AuthToken:       os.Getenv("ARGOCD_AUTH_TOKEN"),
-		Insecure:        os.Getenv("ARGOCD_INSECURE") == "true",
-		PlainText:       os.Getenv("ARGOCD_PLAINTEXT") == "true",
-		GRPCWeb:         os.Getenv("ARGOCD_GRPC_WEB") == "true",
-		GRPCWebRootPath: os.Getenv("ARGOCD_GRPC_WEB_ROOT_PATH"),
-	}
-
-	argoClient, err := client.New(config)
+	// Get the gRPC client for this call
+	argoClient, err := r.client(ctx, request)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to create gRPC client: %v", err)), nil
 	}
 	defer func() { _ = argoClient.Close() }()
 
-	return listClusterHandler(ctx, argoClient, detailed, nameOnly)
+	// Detailed output is a ClusterList and name-only output a ClusterNameList
+	spec := pageSpec{field: "items"}
+	if nameOnly {
+		spec.field = "clusters"
+	}
+	return r.paginate(ctx, request, spec, func() (*mcp.CallToolResult, error) {
+		return listClusterHandler(ctx, argoClient, detailed, nameOnly)
+	})
 }
 
 // ClusterNameList represents a list of cluster identifiers
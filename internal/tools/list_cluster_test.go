@@ -68,11 +68,9 @@ func TestHandleListCluster(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			for k, v := range tt.envVars {
-				t.Setenv(k, v)
-			}
+			registry := newSettingsRegistry(tt.envVars)
 
-			result, err := HandleListCluster(context.Background(), tt.request)
+			result, err := registry.HandleListCluster(context.Background(), tt.request)
 
 			if tt.wantError {
 				require.Nil(t, err)
@@ -0,0 +1,143 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client"
+)
+
+// InstanceArgument is the tool argument that selects the ArgoCD instance a call is sent to
+const InstanceArgument = "instance"
+
+// instanceProbeTimeout bounds the reachability check of a single instance
+const instanceProbeTimeout = 5 * time.Second
+
+// ListInstancesTool defines the list_instances tool schema
+var ListInstancesTool = mcp.NewTool("list_instances",
+	mcp.WithDescription("Lists the configured ArgoCD instances with their server address, whether they are the default, whether they are reachable, and their ArgoCD version. Pass an instance name as the 'instance' argument of other tools to target it."),
+	mcp.WithDestructiveHintAnnotation(false),
+)
+
+// InstanceStatus describes a configured ArgoCD instance and its reachability
+type InstanceStatus struct {
+	Name      string `json:"name"`
+	Server    string `json:"server,omitempty"`
+	Default   bool   `json:"default"`
+	Reachable bool   `json:"reachable"`
+	Version   string `json:"version,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// HandleListInstances processes list_instances tool requests
+func (r *Registry) HandleListInstances(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	instances := r.clients.Instances()
+	results := forEachInstance(ctx, r, instances, func(ctx context.Context, argoClient client.Interface) (string, error) {
+		ctx, cancel := context.WithTimeout(ctx, instanceProbeTimeout)
+		defer cancel()
+
+		version, err := argoClient.GetVersion(ctx)
+		if err != nil {
+			return "", err
+		}
+		return version.Version, nil
+	})
+
+	statuses := make([]InstanceStatus, 0, len(instances))
+	for i, instance := range instances {
+		status := InstanceStatus{
+			Name:    instance.Name,
+			Server:  instance.Server,
+			Default: instance.Default,
+		}
+		if results[i].err != nil {
+			status.Error = results[i].err.Error()
+		} else {
+			status.Reachable = true
+			status.Version = results[i].value
+		}
+		statuses = append(statuses, status)
+	}
+
+	jsonData, err := json.MarshalIndent(statuses, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to format response: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// instanceName returns the name of the instance a tool call targets
+func (r *Registry) instanceName(request mcp.CallToolRequest) string {
+	if name := request.GetString(InstanceArgument, ""); name != "" {
+		return name
+	}
+	for _, instance := range r.clients.Instances() {
+		if instance.Default {
+			return instance.Name
+		}
+	}
+	return ""
+}
+
+// withInstanceArgument returns a copy of tool that accepts the instance argument
+func (r *Registry) withInstanceArgument(tool mcp.Tool) mcp.Tool {
+	instances := r.clients.Instances()
+	names := make([]string, 0, len(instances))
+	defaultName := ""
+	for _, instance := range instances {
+		names = append(names, instance.Name)
+		if instance.Default {
+			defaultName = instance.Name
+		}
+	}
+
+	properties := make(map[string]any, len(tool.InputSchema.Properties)+1)
+	maps.Copy(properties, tool.InputSchema.Properties)
+	properties[InstanceArgument] = map[string]any{
+		"type":        "string",
+		"description": fmt.Sprintf("Name of the ArgoCD instance to use (default: %s). Use list_instances to see the configured instances.", defaultName),
+		"enum":        names,
+	}
+	tool.InputSchema.Properties = properties
+	return tool
+}
+
+// instanceResult is the outcome of a call made against one instance
+type instanceResult[T any] struct {
+	instance string
+	value    T
+	err      error
+}
+
+// forEachInstance calls fn concurrently with the client of every given instance.
+// Results are returned in the order of instances.
+func forEachInstance[T any](ctx context.Context, r *Registry, instances []client.Instance, fn func(context.Context, client.Interface) (T, error)) []instanceResult[T] {
+	results := make([]instanceResult[T], len(instances))
+
+	var wg sync.WaitGroup
+	for i, instance := range instances {
+		results[i].instance = instance.Name
+		wg.Add(1)
+		go func(result *instanceResult[T]) {
+			defer wg.Done()
+
+			argoClient, err := r.clients.Client(ctx, result.instance)
+			if err != nil {
+				result.err = err
+				return
+			}
+			defer func() { _ = argoClient.Close() }()
+
+			result.value, result.err = fn(ctx, argoClient)
+		}(&results[i])
+	}
+	wg.Wait()
+
+	return results
+}
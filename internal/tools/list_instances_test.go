@@ -0,0 +1,207 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	versionpkg "github.com/argoproj/argo-cd/v2/pkg/apiclient/version"
+	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client/mock"
+	"go.uber.org/mock/gomock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fakeProvider is a ClientProvider with a fixed set of named instances
+type fakeProvider struct {
+	instances []client.Instance
+	clients   map[string]client.Interface
+	errs      map[string]error
+}
+
+func (p *fakeProvider) Client(ctx context.Context, instance string) (client.Interface, error) {
+	if instance == "" {
+		instance = p.instances[0].Name
+	}
+	if err := p.errs[instance]; err != nil {
+		return nil, err
+	}
+	c, ok := p.clients[instance]
+	if !ok {
+		return nil, client.ErrUnknownInstance
+	}
+	return c, nil
+}
+
+func (p *fakeProvider) Instances() []client.Instance {
+	return p.instances
+}
+
+func newFakeProvider(clients map[string]client.Interface, errs map[string]error) *fakeProvider {
+	return &fakeProvider{
+		instances: []client.Instance{
+			{Name: "prod", Server: "argocd.prod:443", Default: true},
+			{Name: "staging", Server: "argocd.staging:443"},
+		},
+		clients: clients,
+		errs:    errs,
+	}
+}
+
+func TestHandleListInstances(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	prod := mock.NewMockInterface(ctrl)
+	prod.EXPECT().GetVersion(gomock.Any()).Return(&versionpkg.VersionMessage{Version: "v2.14.15"}, nil)
+	prod.EXPECT().Close().Return(nil)
+
+	registry := NewRegistry(newFakeProvider(
+		map[string]client.Interface{"prod": prod},
+		map[string]error{"staging": errors.New("connection refused")},
+	), Config{})
+
+	result, err := registry.HandleListInstances(context.Background(), mcp.CallToolRequest{})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	var statuses []InstanceStatus
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &statuses))
+	assert.Equal(t, []InstanceStatus{
+		{Name: "prod", Server: "argocd.prod:443", Default: true, Reachable: true, Version: "v2.14.15"},
+		{Name: "staging", Server: "argocd.staging:443", Error: "connection refused"},
+	}, statuses)
+}
+
+func TestRegistry_InstanceArgument(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	staging := mock.NewMockInterface(ctrl)
+	staging.EXPECT().GetApplication(gomock.Any(), "test-app").Return(&v1alpha1.Application{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-app"},
+	}, nil)
+	staging.EXPECT().Close().Return(nil)
+
+	registry := NewRegistry(newFakeProvider(map[string]client.Interface{"staging": staging}, nil), Config{})
+
+	for _, tool := range registry.Tools() {
+		property, ok := tool.Tool.InputSchema.Properties[InstanceArgument].(map[string]any)
+		if tool.Tool.Name == ListInstancesTool.Name {
+			assert.False(t, ok)
+			continue
+		}
+		require.True(t, ok, "tool %s has no instance argument", tool.Tool.Name)
+		assert.Equal(t, []string{"prod", "staging"}, property["enum"])
+	}
+	// The shared tool definitions are left untouched
+	assert.NotContains(t, GetAppTool.InputSchema.Properties, InstanceArgument)
+
+	result, err := registry.HandleGetApplication(context.Background(), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name:      "get_application",
+			Arguments: map[string]interface{}{"name": "test-app", "instance": "staging"},
+		},
+	})
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+
+	// A single instance registry does not advertise the argument
+	for _, tool := range newMockRegistry(nil).Tools() {
+		assert.NotContains(t, tool.Tool.InputSchema.Properties, InstanceArgument)
+	}
+}
+
+func TestHandleListApplications_AllInstances(t *testing.T) {
+	newApp := func(name string) v1alpha1.Application {
+		return v1alpha1.Application{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       v1alpha1.ApplicationSpec{Project: "default"},
+			Status: v1alpha1.ApplicationStatus{
+				Sync:   v1alpha1.SyncStatus{Status: v1alpha1.SyncStatusCodeSynced},
+				Health: v1alpha1.HealthStatus{Status: "Healthy"},
+			},
+		}
+	}
+
+	tests := []struct {
+		name         string
+		arguments    map[string]interface{}
+		stagingErr   error
+		wantError    bool
+		wantContains []string
+	}{
+		{
+			name:      "summary tsv",
+			arguments: map[string]interface{}{},
+			wantContains: []string{
+				"instance\tname\tproject\tsyncStatus\thealthStatus",
+				"prod\tprod-app\tdefault\tSynced\tHealthy",
+				"staging\tstaging-app\tdefault\tSynced\tHealthy",
+			},
+		},
+		{
+			name:         "name only json",
+			arguments:    map[string]interface{}{"name_only": true, "output_format": "json"},
+			wantContains: []string{`"prod/prod-app"`, `"staging/staging-app"`},
+		},
+		{
+			name:         "summary json",
+			arguments:    map[string]interface{}{"output_format": "json"},
+			wantContains: []string{`"instance": "staging"`},
+		},
+		{
+			name:         "one instance fails",
+			arguments:    map[string]interface{}{},
+			stagingErr:   errors.New("connection refused"),
+			wantContains: []string{"prod\tprod-app", `Failed to list applications on instance "staging": connection refused`},
+		},
+		{
+			name:      "conflicting instance argument",
+			arguments: map[string]interface{}{"instance": "prod"},
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			clients := map[string]client.Interface{}
+			for _, name := range []string{"prod", "staging"} {
+				m := mock.NewMockInterface(ctrl)
+				m.EXPECT().ListApplications(gomock.Any(), "").Return(&v1alpha1.ApplicationList{
+					Items: []v1alpha1.Application{newApp(name + "-app")},
+				}, nil).AnyTimes()
+				m.EXPECT().Close().Return(nil).AnyTimes()
+				clients[name] = m
+			}
+			registry := NewRegistry(newFakeProvider(clients, map[string]error{"staging": tt.stagingErr}), Config{})
+
+			arguments := map[string]interface{}{"all_instances": true}
+			for k, v := range tt.arguments {
+				arguments[k] = v
+			}
+			result, err := registry.HandleListApplications(context.Background(), mcp.CallToolRequest{
+				Params: mcp.CallToolParams{Name: "list_application", Arguments: arguments},
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantError, result.IsError)
+
+			var text strings.Builder
+			for _, content := range result.Content {
+				text.WriteString(content.(mcp.TextContent).Text)
+			}
+			for _, want := range tt.wantContains {
+				assert.Contains(t, text.String(), want)
+			}
+		})
+	}
+}
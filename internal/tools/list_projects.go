@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client"
@@ -17,31 +16,25 @@ var ListProjectsTool = mcp.NewTool("list_project",
 	mcp.WithBoolean("name_only",
 		mcp.Description("If true, returns only project names. Useful for getting a quick list of project names."),
 	),
+	withPagination(),
 )
 
 // HandleListProjects processes list_project tool requests
-func HandleListProjects(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func (r *Registry) HandleListProjects(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	// Safely extract optional parameters from mcp.CallToolRequest
 	nameOnly := request.GetBool("name_only", false)
 
-	// Create gRPC client and list projects
-	config := &client.Config{
-		ServerAddr:      os.Getenv("ARGOCD_SERVER"),
-		AuthToken:       os.Getenv("ARGOCD_AUTH_TOKEN"),
-		Insecure:        os.Getenv("ARGOCD_INSECURE") == "true",
-		PlainText:       os.Getenv("ARGOCD_PLAINTEXT") == "true",
-		GRPCWeb:         os.Getenv("ARGOCD_GRPC_WEB") == "true",
-		GRPCWebRootPath: os.Getenv("ARGOCD_GRPC_WEB_ROOT_PATH"),
-	}
-
-	argoClient, err := client.New(config)
+	// Get the gRPC client for this call
+	argoClient, err := r.client(ctx, request)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to create gRPC client: %v", err)), nil
 	}
 	defer func() { _ = argoClient.Close() }()
 
 	// Use the handler function with the real client
-	return listProjectsHandler(ctx, argoClient, nameOnly)
+	return r.paginate(ctx, request, pageSpec{field: "names"}, func() (*mcp.CallToolResult, error) {
+		return listProjectsHandler(ctx, argoClient, nameOnly)
+	})
 }
 
 // ProjectNameList represents a list of project names
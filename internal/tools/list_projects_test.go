@@ -43,13 +43,11 @@ func TestHandleListProjects(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Set environment variables
-			for k, v := range tt.envVars {
-				t.Setenv(k, v)
-			}
+			// Build a registry configured from the test settings
+			registry := newSettingsRegistry(tt.envVars)
 
 			// Execute handler
-			result, err := HandleListProjects(context.Background(), mcp.CallToolRequest{
+			result, err := registry.HandleListProjects(context.Background(), mcp.CallToolRequest{
 				Params: mcp.CallToolParams{
 					Name:      "list_project",
 					Arguments: map[string]interface{}{},
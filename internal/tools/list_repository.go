@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client"
@@ -14,28 +13,22 @@ import (
 var ListRepositoryTool = mcp.NewTool("list_repository",
 	mcp.WithDescription("Lists all configured Git repositories in ArgoCD."),
 	mcp.WithDestructiveHintAnnotation(false),
+	withPagination(),
 )
 
 // HandleListRepository processes list_repository tool requests
-func HandleListRepository(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	// Create gRPC client and list repositories
-	config := &client.Config{
-		ServerAddr:      os.Getenv("ARGOCD_SERVER"),
-		AuthToken:       os.Getenv("ARGOCD_AUTH_TOKEN"),
-		Insecure:        os.Getenv("ARGOCD_INSECURE") == "true",
-		PlainText:       os.Getenv("ARGOCD_PLAINTEXT") == "true",
-		GRPCWeb:         os.Getenv("ARGOCD_GRPC_WEB") == "true",
-		GRPCWebRootPath: os.Getenv("ARGOCD_GRPC_WEB_ROOT_PATH"),
-	}
-
-	argoClient, err := client.New(config)
+func (r *Registry) HandleListRepository(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Get the gRPC client for this call
+	argoClient, err := r.client(ctx, request)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to create gRPC client: %v", err)), nil
 	}
 	defer func() { _ = argoClient.Close() }()
 
 	// Use the handler function with the real client
-	return listRepositoryHandler(ctx, argoClient)
+	return r.paginate(ctx, request, pageSpec{}, func() (*mcp.CallToolResult, error) {
+		return listRepositoryHandler(ctx, argoClient)
+	})
 }
 
 // listRepositoryHandler handles the core logic for listing repositories.
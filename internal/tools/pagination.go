@@ -0,0 +1,361 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// DefaultMaxResponseBytes is the size budget of list and large-output tools when max_bytes is not given
+const DefaultMaxResponseBytes = 100_000
+
+// cursorPrefix marks the offset encoded in a cursor
+const cursorPrefix = "offset:"
+
+// withPagination adds the limit, cursor and max_bytes arguments to a list or large-output tool
+func withPagination() mcp.ToolOption {
+	return func(t *mcp.Tool) {
+		mcp.WithNumber("limit",
+			mcp.Description("Optional. Maximum number of items to return. Defaults to as many as fit in max_bytes."),
+		)(t)
+		mcp.WithString("cursor",
+			mcp.Description("Optional. The next_cursor returned by a previous call, to continue where it stopped."),
+		)(t)
+		mcp.WithNumber("max_bytes",
+			mcp.Description(fmt.Sprintf("Optional. Maximum size of the output in bytes (default: %d). Items that do not fit are left for the next page.", DefaultMaxResponseBytes)),
+		)(t)
+	}
+}
+
+// pageSpec describes where the items of a tool's output are
+type pageSpec struct {
+	// field is the field holding the items when the output is a JSON object
+	field string
+	// header is the number of leading lines repeated on every page when the output is text lines
+	header int
+	// raw reports that the handler already rendered the requested output format
+	raw bool
+}
+
+// pageArgs holds the pagination arguments of a call
+type pageArgs struct {
+	offset   int
+	limit    int
+	maxBytes int
+}
+
+// PageInfo tells what a paginated output left out and how to get it
+type PageInfo struct {
+	NextCursor string `json:"next_cursor,omitempty"`
+	Returned   int    `json:"returned"`
+	Total      int    `json:"total"`
+	Omitted    int    `json:"omitted"`
+	Truncated  bool   `json:"truncated,omitempty"`
+	Note       string `json:"note"`
+}
+
+// paginate runs a handler and pages the items of its output according to the
+// limit, cursor and max_bytes arguments of the request. Items are taken in the
+// order the handler rendered them, so the same input always gives the same pages.
+// The JSON array, the JSON object field named by spec or the text lines after
+// the header are the items; other output is only truncated to max_bytes.
+func (r *Registry) paginate(ctx context.Context, request mcp.CallToolRequest, spec pageSpec, handle func() (*mcp.CallToolResult, error)) (*mcp.CallToolResult, error) {
+	args, err := r.pageArgs(request)
+	if err != nil {
+		return invalidArgument(ctx, err.Error()), nil
+	}
+	out, err := parseOutputArgs(request)
+	if err != nil {
+		return invalidArgument(ctx, err.Error()), nil
+	}
+	format := func(page string) string { return out.render(page, spec) }
+	if spec.raw {
+		format = func(page string) string { return page }
+	}
+
+	result, err := handle()
+	if err != nil || result == nil || result.IsError || len(result.Content) == 0 {
+		return result, err
+	}
+	text, ok := result.Content[0].(mcp.TextContent)
+	if !ok {
+		return result, nil
+	}
+
+	// Pages are rendered before they are measured against max_bytes
+	output, info := args.apply(text.Text, spec, format)
+	result.Content[0] = mcp.NewTextContent(output)
+	if info != nil {
+		jsonData, err := json.Marshal(info)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to format response: %v", err)), nil
+		}
+		result.Content = append(result.Content, mcp.NewTextContent(string(jsonData)))
+	}
+	return result, nil
+}
+
+// pageArgs reads the pagination arguments of a request
+func (r *Registry) pageArgs(request mcp.CallToolRequest) (pageArgs, error) {
+	args := pageArgs{
+		limit:    request.GetInt("limit", 0),
+		maxBytes: request.GetInt("max_bytes", r.config.MaxResponseBytes),
+	}
+	if args.limit < 0 {
+		return args, fmt.Errorf("limit must not be negative")
+	}
+	if args.maxBytes <= 0 {
+		return args, fmt.Errorf("max_bytes must be positive")
+	}
+	if cursor := request.GetString("cursor", ""); cursor != "" {
+		offset, err := decodeCursor(cursor)
+		if err != nil {
+			return args, err
+		}
+		args.offset = offset
+	}
+	return args, nil
+}
+
+func encodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(cursorPrefix + strconv.Itoa(offset)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err == nil {
+		if offset, ok := strings.CutPrefix(string(decoded), cursorPrefix); ok {
+			if n, err := strconv.Atoi(offset); err == nil && n >= 0 {
+				return n, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("invalid cursor %q: pass the next_cursor of a previous call", cursor)
+}
+
+// pageItems is the output of a tool split into the items to page
+type pageItems struct {
+	items  []string
+	render func(items []string) string
+}
+
+// apply pages output and renders the page with format, returning the page and
+// what it left out, if anything
+func (a pageArgs) apply(output string, spec pageSpec, format func(string) string) (string, *PageInfo) {
+	split, ok := splitItems(output, spec)
+	if !ok {
+		// Output without items can only be truncated
+		output = format(output)
+		if len(output) <= a.maxBytes {
+			return output, nil
+		}
+		return truncateBytes(output, a.maxBytes), &PageInfo{
+			Returned:  1,
+			Total:     1,
+			Truncated: true,
+			Note:      fmt.Sprintf("The output was truncated to %d of %d bytes; narrow the query or raise max_bytes to see the rest.", a.maxBytes, len(output)),
+		}
+	}
+
+	render := split.render
+	split.render = func(items []string) string { return format(render(items)) }
+
+	total := len(split.items)
+	start := min(a.offset, total)
+	end := total
+	if a.limit > 0 {
+		end = min(start+a.limit, total)
+	}
+
+	// Keep as many items as fit in the budget, but always at least one
+	page := split.render(split.items[start:end])
+	truncated := false
+	if len(page) > a.maxBytes && end > start {
+		low, high := start+1, end
+		for low < high {
+			mid := (low + high + 1) / 2
+			if len(split.render(split.items[start:mid])) <= a.maxBytes {
+				low = mid
+			} else {
+				high = mid - 1
+			}
+		}
+		end = low
+		page = split.render(split.items[start:end])
+		if len(page) > a.maxBytes {
+			page = truncateBytes(page, a.maxBytes)
+			truncated = true
+		}
+	}
+
+	if start == 0 && end == total && !truncated {
+		return page, nil
+	}
+	info := &PageInfo{
+		Returned:  end - start,
+		Total:     total,
+		Omitted:   total - (end - start),
+		Truncated: truncated,
+	}
+	switch {
+	case end == start:
+		info.Note = fmt.Sprintf("No items left: the cursor is past the last of %d items.", total)
+	default:
+		info.Note = fmt.Sprintf("Showing items %d-%d of %d.", start+1, end, total)
+	}
+	if truncated {
+		info.Note += fmt.Sprintf(" Item %d alone exceeds max_bytes and was truncated to %d bytes.", start+1, a.maxBytes)
+	}
+	if end < total {
+		info.NextCursor = encodeCursor(end)
+		info.Note += fmt.Sprintf(" %d more items were omitted; call again with cursor %q to continue.", total-end, info.NextCursor)
+	}
+	return page, info
+}
+
+// splitItems splits output into its items; it reports false for output without items
+func splitItems(output string, spec pageSpec) (pageItems, bool) {
+	trimmed := strings.TrimSpace(output)
+	indent := strings.Contains(trimmed, "\n")
+	switch {
+	case strings.HasPrefix(trimmed, "["):
+		var items []json.RawMessage
+		if err := json.Unmarshal([]byte(trimmed), &items); err != nil {
+			return pageItems{}, false
+		}
+		return pageItems{
+			items: rawStrings(items),
+			render: func(items []string) string {
+				return renderJSON("["+strings.Join(items, ",")+"]", indent)
+			},
+		}, true
+	case strings.HasPrefix(trimmed, "{"):
+		if spec.field == "" {
+			return pageItems{}, false
+		}
+		fields, ok := objectFields(trimmed)
+		if !ok {
+			return pageItems{}, false
+		}
+		index := -1
+		var items []json.RawMessage
+		for i, field := range fields {
+			if field.key == spec.field && json.Unmarshal(field.value, &items) == nil {
+				index = i
+			}
+		}
+		if index < 0 {
+			return pageItems{}, false
+		}
+		return pageItems{
+			items: rawStrings(items),
+			render: func(items []string) string {
+				var sb strings.Builder
+				sb.WriteString("{")
+				for i, field := range fields {
+					if i > 0 {
+						sb.WriteString(",")
+					}
+					key, _ := json.Marshal(field.key)
+					sb.Write(key)
+					sb.WriteString(":")
+					if i == index {
+						sb.WriteString("[" + strings.Join(items, ",") + "]")
+					} else {
+						sb.Write(field.value)
+					}
+				}
+				sb.WriteString("}")
+				return renderJSON(sb.String(), indent)
+			},
+		}, true
+	case trimmed == "":
+		return pageItems{}, false
+	}
+
+	newline := strings.HasSuffix(output, "\n")
+	lines := strings.Split(strings.TrimSuffix(output, "\n"), "\n")
+	header := min(spec.header, len(lines))
+	return pageItems{
+		items: lines[header:],
+		render: func(items []string) string {
+			rendered := strings.Join(append(append([]string{}, lines[:header]...), items...), "\n")
+			if newline {
+				rendered += "\n"
+			}
+			return rendered
+		},
+	}, true
+}
+
+// objectField is a field of a JSON object, kept in its original order
+type objectField struct {
+	key   string
+	value json.RawMessage
+}
+
+// objectFields decodes the fields of a JSON object in order
+func objectFields(data string) ([]objectField, bool) {
+	decoder := json.NewDecoder(strings.NewReader(data))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return nil, false
+	}
+	var fields []objectField
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, false
+		}
+		key, _ := token.(string)
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return nil, false
+		}
+		fields = append(fields, objectField{key: key, value: value})
+	}
+	return fields, true
+}
+
+// rawStrings compacts JSON values so that they can be joined and indented again
+func rawStrings(values []json.RawMessage) []string {
+	result := make([]string, len(values))
+	for i, value := range values {
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, value); err != nil {
+			result[i] = string(value)
+			continue
+		}
+		result[i] = buf.String()
+	}
+	return result
+}
+
+// renderJSON indents compact JSON the way json.MarshalIndent does when indent is set
+func renderJSON(compact string, indent bool) string {
+	if !indent {
+		return compact
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, []byte(compact), "", "  "); err != nil {
+		return compact
+	}
+	return buf.String()
+}
+
+// truncateBytes cuts s to at most n bytes without splitting a UTF-8 character
+func truncateBytes(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
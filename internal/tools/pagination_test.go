@@ -0,0 +1,190 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client/mock"
+	"go.uber.org/mock/gomock"
+)
+
+func paginationRequest(args map[string]any) mcp.CallToolRequest {
+	return mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: args}}
+}
+
+// pageOf runs paginate over a handler returning output
+func pageOf(t *testing.T, r *Registry, args map[string]any, spec pageSpec, output string) (string, *PageInfo) {
+	t.Helper()
+	result, err := r.paginate(context.Background(), paginationRequest(args), spec, func() (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText(output), nil
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError, "%v", result.Content)
+	page := result.Content[0].(mcp.TextContent).Text
+	if len(result.Content) == 1 {
+		return page, nil
+	}
+	var info PageInfo
+	require.NoError(t, json.Unmarshal([]byte(result.Content[1].(mcp.TextContent).Text), &info))
+	return page, &info
+}
+
+func TestRegistry_Paginate(t *testing.T) {
+	r := newMockRegistry(nil)
+	items := []map[string]any{{"name": "a"}, {"name": "b"}, {"name": "c"}, {"name": "d"}, {"name": "e"}}
+	array, err := json.MarshalIndent(items, "", "  ")
+	require.NoError(t, err)
+
+	t.Run("everything fits", func(t *testing.T) {
+		page, info := pageOf(t, r, nil, pageSpec{}, string(array))
+		assert.Equal(t, string(array), page)
+		assert.Nil(t, info)
+	})
+
+	t.Run("limit and cursor walk a JSON array", func(t *testing.T) {
+		var names []string
+		args := map[string]any{"limit": 2}
+		for range 3 {
+			page, info := pageOf(t, r, args, pageSpec{}, string(array))
+			var got []map[string]string
+			require.NoError(t, json.Unmarshal([]byte(page), &got))
+			for _, item := range got {
+				names = append(names, item["name"])
+			}
+			require.NotNil(t, info)
+			assert.Equal(t, 5, info.Total)
+			if info.NextCursor == "" {
+				assert.Contains(t, info.Note, "Showing items 5-5 of 5.")
+				break
+			}
+			assert.Contains(t, info.Note, info.NextCursor)
+			args = map[string]any{"limit": 2, "cursor": info.NextCursor}
+		}
+		assert.Equal(t, []string{"a", "b", "c", "d", "e"}, names)
+	})
+
+	t.Run("pages keep the indentation", func(t *testing.T) {
+		page, _ := pageOf(t, r, map[string]any{"limit": 1}, pageSpec{}, string(array))
+		assert.Equal(t, "[\n  {\n    \"name\": \"a\"\n  }\n]", page)
+
+		compact, _ := json.Marshal(items)
+		page, _ = pageOf(t, r, map[string]any{"limit": 1}, pageSpec{}, string(compact))
+		assert.Equal(t, `[{"name":"a"}]`, page)
+	})
+
+	t.Run("JSON object field", func(t *testing.T) {
+		output := `{"names":["a","b","c"],"count":3}`
+		page, info := pageOf(t, r, map[string]any{"limit": 2}, pageSpec{field: "names"}, output)
+		assert.Equal(t, `{"names":["a","b"],"count":3}`, page)
+		require.NotNil(t, info)
+		assert.Equal(t, 1, info.Omitted)
+		assert.Equal(t, encodeCursor(2), info.NextCursor)
+	})
+
+	t.Run("text lines keep their header", func(t *testing.T) {
+		output := "name\tproject\na\tdefault\nb\tdefault\nc\tdefault\n"
+		page, info := pageOf(t, r, map[string]any{"limit": 2, "cursor": encodeCursor(1)}, pageSpec{header: 1}, output)
+		assert.Equal(t, "name\tproject\nb\tdefault\nc\tdefault\n", page)
+		require.NotNil(t, info)
+		assert.Equal(t, "", info.NextCursor)
+		assert.Equal(t, 2, info.Returned)
+	})
+
+	t.Run("max_bytes keeps the items that fit", func(t *testing.T) {
+		limit := len(`[{"name":"a"},{"name":"b"}]`)
+		compact, _ := json.Marshal(items)
+		page, info := pageOf(t, r, map[string]any{"max_bytes": limit}, pageSpec{}, string(compact))
+		assert.Equal(t, `[{"name":"a"},{"name":"b"}]`, page)
+		require.NotNil(t, info)
+		assert.Equal(t, 2, info.Returned)
+		assert.Equal(t, 3, info.Omitted)
+		assert.False(t, info.Truncated)
+		assert.Equal(t, encodeCursor(2), info.NextCursor)
+	})
+
+	t.Run("an item larger than max_bytes is truncated", func(t *testing.T) {
+		output := "header\n" + strings.Repeat("x", 50) + "\nshort\n"
+		page, info := pageOf(t, r, map[string]any{"max_bytes": 20}, pageSpec{header: 1}, output)
+		assert.Len(t, page, 20)
+		require.NotNil(t, info)
+		assert.True(t, info.Truncated)
+		assert.Equal(t, 1, info.Returned)
+		assert.Equal(t, encodeCursor(1), info.NextCursor)
+	})
+
+	t.Run("output without items is truncated", func(t *testing.T) {
+		output := `{"metadata":{},"spec":"` + strings.Repeat("é", 20) + `"}`
+		page, info := pageOf(t, r, map[string]any{"max_bytes": 30}, pageSpec{}, output)
+		assert.LessOrEqual(t, len(page), 30)
+		assert.True(t, strings.HasPrefix(output, page))
+		require.NotNil(t, info)
+		assert.True(t, info.Truncated)
+		assert.Empty(t, info.NextCursor)
+	})
+
+	t.Run("cursor past the end", func(t *testing.T) {
+		page, info := pageOf(t, r, map[string]any{"cursor": encodeCursor(10)}, pageSpec{}, string(array))
+		assert.Equal(t, "[]", page)
+		require.NotNil(t, info)
+		assert.Equal(t, 0, info.Returned)
+		assert.Contains(t, info.Note, "past the last of 5 items")
+	})
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		for want, args := range map[string]map[string]any{
+			"invalid cursor":             {"cursor": "not-a-cursor"},
+			"limit must not be negative": {"limit": -1},
+			"max_bytes must be positive": {"max_bytes": -5},
+		} {
+			called := false
+			result, err := r.paginate(context.Background(), paginationRequest(args), pageSpec{}, func() (*mcp.CallToolResult, error) {
+				called = true
+				return mcp.NewToolResultText("[]"), nil
+			})
+			require.NoError(t, err)
+			assert.True(t, result.IsError)
+			assert.Contains(t, result.Content[0].(mcp.TextContent).Text, want)
+			assert.False(t, called, "the handler ran despite %s", want)
+		}
+	})
+
+	t.Run("errors pass through", func(t *testing.T) {
+		result, err := r.paginate(context.Background(), paginationRequest(map[string]any{"limit": 1}), pageSpec{}, func() (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultError("[boom]"), nil
+		})
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+		assert.Len(t, result.Content, 1)
+	})
+}
+
+func TestRegistry_PaginateDefaultBudget(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repos := make(v1alpha1.Repositories, 50)
+	for i := range repos {
+		repos[i] = &v1alpha1.Repository{Repo: fmt.Sprintf("https://github.com/org/repo-%02d", i)}
+	}
+	mockClient := mock.NewMockInterface(ctrl)
+	mockClient.EXPECT().Close().Return(nil).AnyTimes()
+	mockClient.EXPECT().ListRepositories(gomock.Any()).Return(&v1alpha1.RepositoryList{Items: repos}, nil)
+
+	// The configured budget applies when a call does not pass max_bytes
+	r := NewRegistry(ClientFactory(func(ctx context.Context) (client.Interface, error) {
+		return mockClient, nil
+	}), Config{MaxResponseBytes: 1000})
+	result, err := r.HandleListRepository(context.Background(), paginationRequest(nil))
+	require.NoError(t, err)
+	require.Len(t, result.Content, 2)
+	assert.LessOrEqual(t, len(result.Content[0].(mcp.TextContent).Text), 1000)
+	assert.Contains(t, result.Content[1].(mcp.TextContent).Text, `"next_cursor"`)
+}
@@ -0,0 +1,148 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client"
+	"sigs.k8s.io/yaml"
+)
+
+// Patch types of patch_application
+const (
+	PatchTypeMerge = "merge"
+	PatchTypeJSON  = "json"
+)
+
+// PatchAppTool defines the patch_application tool schema
+var PatchAppTool = mcp.NewTool("patch_application",
+	mcp.WithDescription("Applies a JSON merge patch (RFC 7386) or a JSON patch (RFC 6902) to the spec of an ArgoCD application and shows a before/after diff. The patch is applied to the spec as read, and the server refuses the result when the application changed in the meantime."),
+	mcp.WithDestructiveHintAnnotation(true),
+	mcp.WithString("name",
+		mcp.Required(),
+		mcp.Description("The name of the application to patch."),
+	),
+	mcp.WithString("patch",
+		mcp.Required(),
+		mcp.Description(`The patch, against the application spec rather than the whole resource. A merge patch is an object such as {"source":{"targetRevision":"v1.2.0"}}; a JSON patch is a list of operations such as [{"op":"replace","path":"/source/targetRevision","value":"v1.2.0"}].`),
+	),
+	mcp.WithString("patch_type",
+		mcp.Description("The type of the patch: 'merge' (default) or 'json'."),
+		mcp.Enum(PatchTypeMerge, PatchTypeJSON),
+	),
+	mcp.WithString("resource_version",
+		mcp.Description("Optional. Refuse the patch when the metadata.resourceVersion of the application is no longer this one."),
+	),
+	mcp.WithBoolean("dry_run",
+		mcp.Description("If true, only shows the diff without updating the application (default: false)."),
+	),
+)
+
+// HandlePatchApplication processes patch_application tool requests
+func (r *Registry) HandlePatchApplication(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	name := request.GetString("name", "")
+	patch := request.GetString("patch", "")
+	patchType := request.GetString("patch_type", PatchTypeMerge)
+	resourceVersion := request.GetString("resource_version", "")
+	dryRun := request.GetBool("dry_run", false)
+
+	// Get the gRPC client for this call
+	argoClient, err := r.client(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to create gRPC client: %v", err)), nil
+	}
+	defer func() { _ = argoClient.Close() }()
+
+	return patchApplicationHandler(ctx, argoClient, name, patch, patchType, resourceVersion, dryRun)
+}
+
+// patchApplicationHandler handles the core logic for patching an application.
+// This is separated out to enable testing with mocked clients.
+func patchApplicationHandler(
+	ctx context.Context,
+	argoClient client.Interface,
+	name string,
+	patch string,
+	patchType string,
+	resourceVersion string,
+	dryRun bool,
+) (*mcp.CallToolResult, error) {
+	if name == "" {
+		return invalidArgument(ctx, "Application name is required"), nil
+	}
+	if patch == "" {
+		return invalidArgument(ctx, "patch is required"), nil
+	}
+	if patchType != PatchTypeMerge && patchType != PatchTypeJSON {
+		return invalidArgument(ctx, fmt.Sprintf("Invalid patch_type %q: expected %q or %q", patchType, PatchTypeMerge, PatchTypeJSON)), nil
+	}
+	// Decode the patch before fetching anything, so that a malformed patch fails fast
+	apply, err := decodeSpecPatch(patch, patchType)
+	if err != nil {
+		return invalidArgument(ctx, fmt.Sprintf("Failed to parse patch: %v", err)), nil
+	}
+
+	app, err := argoClient.GetApplication(ctx, name)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get application: %v", err)), nil
+	}
+	if resourceVersion != "" && app.ResourceVersion != resourceVersion {
+		return modifiedApplicationError(name, resourceVersion, app.ResourceVersion), nil
+	}
+
+	spec, err := patchApplicationSpec(app.Spec, apply)
+	if err != nil {
+		return invalidArgument(ctx, fmt.Sprintf("Failed to apply patch: %v", err)), nil
+	}
+
+	return applyApplicationSpec(ctx, argoClient, app, spec, dryRun)
+}
+
+// specPatch applies a decoded patch to a JSON encoded application spec
+type specPatch func(spec []byte) ([]byte, error)
+
+// decodeSpecPatch decodes a JSON or YAML patch of the given type
+func decodeSpecPatch(patch, patchType string) (specPatch, error) {
+	patchData, err := yaml.YAMLToJSON([]byte(patch))
+	if err != nil {
+		return nil, err
+	}
+
+	switch patchType {
+	case PatchTypeMerge:
+		var object map[string]any
+		if err := json.Unmarshal(patchData, &object); err != nil {
+			return nil, fmt.Errorf("a merge patch must be a JSON object: %w", err)
+		}
+		return func(spec []byte) ([]byte, error) { return jsonpatch.MergePatch(spec, patchData) }, nil
+	case PatchTypeJSON:
+		operations, err := jsonpatch.DecodePatch(patchData)
+		if err != nil {
+			return nil, err
+		}
+		return operations.Apply, nil
+	default:
+		return nil, fmt.Errorf("unknown patch type %q", patchType)
+	}
+}
+
+// patchApplicationSpec returns spec with apply applied to it
+func patchApplicationSpec(spec v1alpha1.ApplicationSpec, apply specPatch) (v1alpha1.ApplicationSpec, error) {
+	specData, err := json.Marshal(spec)
+	if err != nil {
+		return spec, fmt.Errorf("failed to encode spec: %w", err)
+	}
+	patched, err := apply(specData)
+	if err != nil {
+		return spec, err
+	}
+	result, err := decodeApplicationSpec(patched)
+	if err != nil {
+		return spec, fmt.Errorf("the patched spec is invalid: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,196 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client/mock"
+	"go.uber.org/mock/gomock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func patchableApplication() *v1alpha1.Application {
+	return &v1alpha1.Application{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-app", Namespace: "argocd", ResourceVersion: "100"},
+		Spec: v1alpha1.ApplicationSpec{
+			Project: "default",
+			Source: &v1alpha1.ApplicationSource{
+				RepoURL:        "https://github.com/example/repo",
+				Path:           "manifests",
+				TargetRevision: "main",
+			},
+			Destination: v1alpha1.ApplicationDestination{Server: "https://kubernetes.default.svc", Namespace: "default"},
+		},
+	}
+}
+
+// serverPatch applies a JSON patch to stored the way the ArgoCD server does,
+// storing the result as resourceVersion 101
+func serverPatch(t *testing.T, stored *v1alpha1.Application) func(context.Context, string, string, string) (*v1alpha1.Application, error) {
+	return func(_ context.Context, name string, patch string, patchType string) (*v1alpha1.Application, error) {
+		assert.Equal(t, stored.Name, name)
+		assert.Equal(t, PatchTypeJSON, patchType)
+		operations, err := jsonpatch.DecodePatch([]byte(patch))
+		require.NoError(t, err)
+		data, err := json.Marshal(stored)
+		require.NoError(t, err)
+		patched, err := operations.Apply(data)
+		if err != nil {
+			return nil, fmt.Errorf("error applying patch: %w", err)
+		}
+		var updated v1alpha1.Application
+		require.NoError(t, json.Unmarshal(patched, &updated))
+		updated.ResourceVersion = "101"
+		return &updated, nil
+	}
+}
+
+func TestPatchApplicationHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		patch      string
+		patchType  string
+		dryRun     bool
+		wantUpdate bool
+		wantDiff   []string
+	}{
+		{
+			name:       "merge patch",
+			patch:      `{"source":{"targetRevision":"v1.2.0"}}`,
+			patchType:  PatchTypeMerge,
+			wantUpdate: true,
+			wantDiff:   []string{"--- before", "+++ after", "-  targetRevision: main", "+  targetRevision: v1.2.0"},
+		},
+		{
+			name:       "yaml merge patch",
+			patch:      "destination:\n  namespace: prod\n",
+			patchType:  PatchTypeMerge,
+			wantUpdate: true,
+			wantDiff:   []string{"-  namespace: default", "+  namespace: prod"},
+		},
+		{
+			name:       "json patch",
+			patch:      `[{"op":"replace","path":"/source/path","value":"overlays/prod"},{"op":"add","path":"/revisionHistoryLimit","value":3}]`,
+			patchType:  PatchTypeJSON,
+			wantUpdate: true,
+			wantDiff:   []string{"-  path: manifests", "+  path: overlays/prod", "+revisionHistoryLimit: 3"},
+		},
+		{
+			name:      "dry run",
+			patch:     `{"source":{"targetRevision":"v1.2.0"}}`,
+			patchType: PatchTypeMerge,
+			dryRun:    true,
+			wantDiff:  []string{"+  targetRevision: v1.2.0"},
+		},
+		{
+			name:      "no change",
+			patch:     `{"project":"default"}`,
+			patchType: PatchTypeMerge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockClient := mock.NewMockInterface(ctrl)
+			mockClient.EXPECT().GetApplication(gomock.Any(), "test-app").Return(patchableApplication(), nil)
+			if tt.wantUpdate {
+				mockClient.EXPECT().PatchApplication(gomock.Any(), "test-app", gomock.Any(), PatchTypeJSON).DoAndReturn(serverPatch(t, patchableApplication()))
+			}
+
+			result, err := patchApplicationHandler(context.Background(), mockClient, "test-app", tt.patch, tt.patchType, "", tt.dryRun)
+			require.NoError(t, err)
+			change := decodeResult[ApplicationChange](t, result)
+
+			assert.Equal(t, "test-app", change.Name)
+			assert.Equal(t, len(tt.wantDiff) > 0, change.Changed)
+			assert.Equal(t, tt.dryRun, change.DryRun)
+			for _, want := range tt.wantDiff {
+				assert.Contains(t, change.Diff, want)
+			}
+			if tt.wantUpdate {
+				assert.Equal(t, "101", change.ResourceVersion)
+			} else {
+				assert.Equal(t, "100", change.ResourceVersion)
+			}
+		})
+	}
+}
+
+func TestPatchApplicationHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name            string
+		patch           string
+		patchType       string
+		resourceVersion string
+		fetch           bool
+		errorContains   string
+	}{
+		{
+			name:          "invalid patch type",
+			patch:         `{}`,
+			patchType:     "strategic",
+			errorContains: `Invalid patch_type "strategic"`,
+		},
+		{
+			name:          "merge patch is not an object",
+			patch:         `[{"op":"remove","path":"/project"}]`,
+			patchType:     PatchTypeMerge,
+			errorContains: "a merge patch must be a JSON object",
+		},
+		{
+			name:          "malformed json patch",
+			patch:         `{"op":"remove"}`,
+			patchType:     PatchTypeJSON,
+			errorContains: "Failed to parse patch",
+		},
+		{
+			name:            "stale resource version",
+			patch:           `{"project":"other"}`,
+			patchType:       PatchTypeMerge,
+			resourceVersion: "99",
+			fetch:           true,
+			errorContains:   "was modified since resourceVersion 99 (now 100)",
+		},
+		{
+			name:          "json patch does not apply",
+			patch:         `[{"op":"test","path":"/project","value":"other"}]`,
+			patchType:     PatchTypeJSON,
+			fetch:         true,
+			errorContains: "Failed to apply patch",
+		},
+		{
+			name:          "unknown field",
+			patch:         `{"sources":null,"sorce":{"path":"x"}}`,
+			patchType:     PatchTypeMerge,
+			fetch:         true,
+			errorContains: `unknown field "sorce"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockClient := mock.NewMockInterface(ctrl)
+			if tt.fetch {
+				mockClient.EXPECT().GetApplication(gomock.Any(), "test-app").Return(patchableApplication(), nil)
+			}
+
+			result, err := patchApplicationHandler(context.Background(), mockClient, "test-app", tt.patch, tt.patchType, tt.resourceVersion, false)
+			require.NoError(t, err)
+			assert.True(t, result.IsError)
+			assert.Contains(t, result.Content[0].(mcp.TextContent).Text, tt.errorContains)
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/metrics"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/policy"
+)
+
+// WithPolicy evaluates the rules of engine before every destructive tool call
+func WithPolicy(engine *policy.Engine) Option {
+	return func(r *Registry) {
+		r.policy = engine
+	}
+}
+
+// enforcePolicy wraps the handler of a destructive tool so that it only runs when the policy allows the call
+func (r *Registry) enforcePolicy(set string, tool mcp.Tool, next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	if r.policy == nil || !isDestructive(tool) {
+		return next
+	}
+
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		input, err := r.policyInput(ctx, set, tool, request)
+		if err != nil {
+			return toolError(ctx, metrics.ErrorTypePolicy, fmt.Sprintf("Failed to evaluate policy: %v", err)), nil
+		}
+
+		if err := r.policy.Evaluate(input); err != nil {
+			var denied *policy.DeniedError
+			if errors.As(err, &denied) {
+				return toolError(ctx, metrics.ErrorTypePolicy, fmt.Sprintf("Policy rule %q denied %s: %s", denied.Rule, tool.Name, denied.Message)), nil
+			}
+			return toolError(ctx, metrics.ErrorTypePolicy, fmt.Sprintf("Failed to evaluate policy: %v", err)), nil
+		}
+
+		return next(ctx, request)
+	}
+}
+
+// policyInput fetches the objects a tool call acts on so that rules can inspect them
+func (r *Registry) policyInput(ctx context.Context, set string, tool mcp.Tool, request mcp.CallToolRequest) (policy.Input, error) {
+	input := policy.Input{
+		Tool:      tool.Name,
+		Instance:  r.instanceName(request),
+		Arguments: request.GetArguments(),
+	}
+
+	name := request.GetString("name", "")
+	projectName := ""
+
+	argoClient, err := r.client(ctx, request)
+	if err != nil {
+		return input, fmt.Errorf("failed to create gRPC client: %w", err)
+	}
+	defer func() { _ = argoClient.Close() }()
+
+	switch {
+	case set == ToolsetApplications && tool.Name == CreateAppTool.Name:
+		// The application does not exist yet; rules see the project it is created in
+		projectName = request.GetString("project", "default")
+	case set == ToolsetApplications && name != "":
+		input.Application, err = argoClient.GetApplication(ctx, name)
+		if err != nil {
+			return input, fmt.Errorf("failed to get application %s: %w", name, err)
+		}
+		projectName = input.Application.Spec.Project
+		input.Proposed, err = proposedApplication(tool.Name, input.Application, request)
+		if err != nil {
+			return input, err
+		}
+	case set == ToolsetApplicationSets && tool.Name != CreateApplicationSetTool.Name && name != "":
+		input.ApplicationSet, err = argoClient.GetApplicationSet(ctx, name, request.GetString("appsetNamespace", ""))
+		if err != nil {
+			return input, fmt.Errorf("failed to get applicationset %s: %w", name, err)
+		}
+	}
+
+	if projectName != "" {
+		input.Project, err = argoClient.GetProject(ctx, projectName)
+		if err != nil {
+			return input, fmt.Errorf("failed to get project %s: %w", projectName, err)
+		}
+	}
+
+	return input, nil
+}
+
+// proposedApplication returns app as update_application or patch_application would
+// leave it, so that rules can check the result of the change. Other tools propose nothing.
+func proposedApplication(toolName string, app *v1alpha1.Application, request mcp.CallToolRequest) (*v1alpha1.Application, error) {
+	var spec v1alpha1.ApplicationSpec
+	var err error
+	switch toolName {
+	case UpdateAppTool.Name:
+		spec, err = decodeApplicationSpec([]byte(request.GetString("spec", "")))
+	case PatchAppTool.Name:
+		var apply specPatch
+		if apply, err = decodeSpecPatch(request.GetString("patch", ""), request.GetString("patch_type", PatchTypeMerge)); err == nil {
+			spec, err = patchApplicationSpec(app.Spec, apply)
+		}
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute the proposed application: %w", err)
+	}
+
+	proposed := app.DeepCopy()
+	proposed.Spec = spec
+	return proposed, nil
+}
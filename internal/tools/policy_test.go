@@ -0,0 +1,190 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client/mock"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/policy"
+	"go.uber.org/mock/gomock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func findTool(t *testing.T, registry *Registry, name string) server.ServerTool {
+	t.Helper()
+	for _, tool := range registry.Tools() {
+		if tool.Tool.Name == name {
+			return tool
+		}
+	}
+	t.Fatalf("tool %s is not registered", name)
+	return server.ServerTool{}
+}
+
+func TestRegistry_WithPolicy(t *testing.T) {
+	engine, err := policy.New([]policy.Rule{{
+		Name:    "sync-team-a-only",
+		Tools:   []string{"sync_application"},
+		Deny:    `app.spec.project != "team-a" || project.metadata.name != "team-a"`,
+		Message: "only team-a applications may be synced",
+	}})
+	require.NoError(t, err)
+
+	newApp := func(project string) *v1alpha1.Application {
+		return &v1alpha1.Application{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-app"},
+			Spec:       v1alpha1.ApplicationSpec{Project: project},
+		}
+	}
+	newProject := func(name string) *v1alpha1.AppProject {
+		return &v1alpha1.AppProject{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	}
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name:      "sync_application",
+			Arguments: map[string]interface{}{"name": "test-app"},
+		},
+	}
+
+	t.Run("denied call names the rule", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockClient := mock.NewMockInterface(ctrl)
+		mockClient.EXPECT().GetApplication(gomock.Any(), "test-app").Return(newApp("team-b"), nil)
+		mockClient.EXPECT().GetProject(gomock.Any(), "team-b").Return(newProject("team-b"), nil)
+		mockClient.EXPECT().Close().Return(nil)
+		// SyncApplication must not be called
+
+		tool := findTool(t, newMockRegistry(mockClient, WithPolicy(engine)), "sync_application")
+		result, err := tool.Handler(context.Background(), request)
+		require.NoError(t, err)
+		require.True(t, result.IsError)
+		text := result.Content[0].(mcp.TextContent).Text
+		assert.Contains(t, text, `"sync-team-a-only"`)
+		assert.Contains(t, text, "only team-a applications may be synced")
+	})
+
+	t.Run("allowed call runs", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockClient := mock.NewMockInterface(ctrl)
+		mockClient.EXPECT().GetApplication(gomock.Any(), "test-app").Return(newApp("team-a"), nil)
+		mockClient.EXPECT().GetProject(gomock.Any(), "team-a").Return(newProject("team-a"), nil)
+		mockClient.EXPECT().SyncApplication(gomock.Any(), "test-app", client.SyncRequest{}).Return(newApp("team-a"), nil)
+		mockClient.EXPECT().Close().Return(nil).Times(2)
+
+		tool := findTool(t, newMockRegistry(mockClient, WithPolicy(engine)), "sync_application")
+		result, err := tool.Handler(context.Background(), request)
+		require.NoError(t, err)
+		assert.False(t, result.IsError)
+	})
+
+	t.Run("read-only tools are not evaluated", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockClient := mock.NewMockInterface(ctrl)
+		mockClient.EXPECT().GetApplication(gomock.Any(), "test-app").Return(newApp("team-b"), nil)
+		mockClient.EXPECT().Close().Return(nil)
+
+		tool := findTool(t, newMockRegistry(mockClient, WithPolicy(engine)), "get_application")
+		result, err := tool.Handler(context.Background(), mcp.CallToolRequest{
+			Params: mcp.CallToolParams{Name: "get_application", Arguments: map[string]interface{}{"name": "test-app"}},
+		})
+		require.NoError(t, err)
+		assert.False(t, result.IsError)
+	})
+	t.Run("applicationset rules see the requested namespace", func(t *testing.T) {
+		engine, err := policy.New([]policy.Rule{{
+			Name:  "keep-team-a-appsets",
+			Tools: []string{"delete_applicationset"},
+			Deny:  `appset.metadata.namespace == "team-a"`,
+		}})
+		require.NoError(t, err)
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockClient := mock.NewMockInterface(ctrl)
+		mockClient.EXPECT().GetApplicationSet(gomock.Any(), "test-appset", "team-a").Return(&v1alpha1.ApplicationSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-appset", Namespace: "team-a"},
+		}, nil)
+		mockClient.EXPECT().Close().Return(nil)
+		// DeleteApplicationSet must not be called
+
+		tool := findTool(t, newMockRegistry(mockClient, WithPolicy(engine)), "delete_applicationset")
+		result, err := tool.Handler(context.Background(), mcp.CallToolRequest{
+			Params: mcp.CallToolParams{
+				Name:      "delete_applicationset",
+				Arguments: map[string]interface{}{"name": "test-appset", "appsetNamespace": "team-a"},
+			},
+		})
+		require.NoError(t, err)
+		require.True(t, result.IsError)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, `"keep-team-a-appsets"`)
+	})
+	t.Run("rules see the proposed application", func(t *testing.T) {
+		engine, err := policy.New([]policy.Rule{{
+			Name:  "no-kube-system",
+			Tools: []string{"update_application", "patch_application"},
+			Deny:  `proposed.spec.destination.namespace == "kube-system"`,
+		}})
+		require.NoError(t, err)
+
+		app := &v1alpha1.Application{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-app", ResourceVersion: "100"},
+			Spec: v1alpha1.ApplicationSpec{
+				Project:     "default",
+				Destination: v1alpha1.ApplicationDestination{Server: "https://kubernetes.default.svc", Namespace: "web"},
+			},
+		}
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockClient := mock.NewMockInterface(ctrl)
+		mockClient.EXPECT().GetApplication(gomock.Any(), "test-app").Return(app, nil)
+		mockClient.EXPECT().GetProject(gomock.Any(), "default").Return(newProject("default"), nil)
+		mockClient.EXPECT().Close().Return(nil)
+		// PatchApplication must not be called
+
+		tool := findTool(t, newMockRegistry(mockClient, WithPolicy(engine)), "patch_application")
+		result, err := tool.Handler(context.Background(), mcp.CallToolRequest{
+			Params: mcp.CallToolParams{
+				Name: "patch_application",
+				Arguments: map[string]interface{}{
+					"name":  "test-app",
+					"patch": `{"destination":{"namespace":"kube-system"}}`,
+				},
+			},
+		})
+		require.NoError(t, err)
+		require.True(t, result.IsError)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, `"no-kube-system"`)
+
+		proposed, err := proposedApplication("patch_application", app, mcp.CallToolRequest{
+			Params: mcp.CallToolParams{Arguments: map[string]interface{}{
+				"patch": `[{"op":"replace","path":"/destination/namespace","value":"api"}]`, "patch_type": "json",
+			}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "api", proposed.Spec.Destination.Namespace)
+		assert.Equal(t, "web", app.Spec.Destination.Namespace, "the current application is left alone")
+
+		proposed, err = proposedApplication("update_application", app, mcp.CallToolRequest{
+			Params: mcp.CallToolParams{Arguments: map[string]interface{}{
+				"spec": "project: default\ndestination:\n  namespace: kube-system\n",
+			}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "kube-system", proposed.Spec.Destination.Namespace)
+	})
+}
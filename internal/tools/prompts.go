@@ -0,0 +1,244 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Names of the runbook prompts
+const (
+	TroubleshootApplicationPrompt = "troubleshoot_application"
+	ReviewSyncPrunePrompt         = "review_sync_prune"
+	OnboardRepositoryPrompt       = "onboard_repository"
+	ExplainOutOfSyncPrompt        = "explain_out_of_sync"
+)
+
+// promptLogTailLines is the number of log lines pre-fetched by the troubleshooting prompt
+const promptLogTailLines = 50
+
+// runbook is a prompt guiding an agent through a standard procedure. Its
+// messages start with the results of read-only tool calls, so that the agent
+// works from the current state of ArgoCD.
+type runbook struct {
+	prompt mcp.Prompt
+	// set and tool gate the prompt: it is exposed when this tool is enabled
+	set  string
+	tool mcp.Tool
+	// calls returns the tool calls whose results are included in the prompt
+	calls func(args map[string]string) []promptCall
+	// instructions returns the procedure the agent should follow
+	instructions func(args map[string]string) string
+}
+
+// promptCall is a tool call pre-fetching context for a prompt
+type promptCall struct {
+	tool mcp.Tool
+	args map[string]any
+}
+
+func (r *Registry) runbooks() []runbook {
+	appArgs := func(args map[string]string) map[string]any {
+		return map[string]any{"name": args["name"], "project": args["project"], "app_namespace": args["app_namespace"]}
+	}
+	appArguments := []mcp.PromptOption{
+		mcp.WithArgument("name", mcp.RequiredArgument(), mcp.ArgumentDescription("The name of the application")),
+		mcp.WithArgument("project", mcp.ArgumentDescription("Optional. The ArgoCD project the application belongs to")),
+		mcp.WithArgument("app_namespace", mcp.ArgumentDescription("Optional. The namespace of the Application resource (for multi-tenant setups)")),
+	}
+
+	return []runbook{
+		{
+			prompt: mcp.NewPrompt(TroubleshootApplicationPrompt, append([]mcp.PromptOption{
+				mcp.WithPromptDescription("Troubleshoot a Degraded or Progressing application from its resource tree, events and logs"),
+			}, appArguments...)...),
+			set: ToolsetApplications, tool: GetAppTool,
+			calls: func(args map[string]string) []promptCall {
+				logArgs := appArgs(args)
+				logArgs["tail_lines"] = promptLogTailLines
+				return []promptCall{
+					{tool: GetAppTool, args: appArgs(args)},
+					{tool: GetApplicationResourceTreeTool, args: appArgs(args)},
+					{tool: GetAppEventsTool, args: appArgs(args)},
+					{tool: GetApplicationLogsToolDefinition, args: logArgs},
+				}
+			},
+			instructions: func(args map[string]string) string {
+				return fmt.Sprintf(`Troubleshoot the ArgoCD application %q, which is not healthy.
+
+1. From the application status, note the health and sync status and any operation or condition messages.
+2. In the resource tree, find the resources that are not Healthy, starting from the leaves (Pods, Jobs) up to their owners.
+3. Correlate them with the Warning events and the log lines below. Look for image pull errors, crash loops, failed probes, missing Secrets or ConfigMaps, quota and scheduling problems.
+4. If the logs below come from an unrelated pod, fetch the logs of the failing pod with get_application_logs.
+5. Report the root cause, the evidence supporting it and the fix, distinguishing changes to Git from actions in ArgoCD.
+
+Do not sync, delete or modify anything without the user's explicit approval.`, args["name"])
+			},
+		},
+		{
+			prompt: mcp.NewPrompt(ReviewSyncPrunePrompt, append([]mcp.PromptOption{
+				mcp.WithPromptDescription("Review what a pruning sync would delete before running it"),
+			}, appArguments...)...),
+			set: ToolsetApplications, tool: GetAppTool,
+			calls: func(args map[string]string) []promptCall {
+				return []promptCall{
+					{tool: GetAppTool, args: appArgs(args)},
+					{tool: GetApplicationResourceTreeTool, args: appArgs(args)},
+				}
+			},
+			instructions: func(args map[string]string) string {
+				return fmt.Sprintf(`Review a sync with prune of the ArgoCD application %q before it runs.
+
+1. List the resources in the application status marked requiresPruning: these are deleted by a pruning sync.
+2. Flag the resources whose deletion loses data or breaks other workloads: PersistentVolumeClaims, Namespaces, CustomResourceDefinitions, Secrets, and anything shared with other applications.
+3. Check the sync policy and sync options (Prune=false, PruneLast, Delete=false annotations) that change what is pruned.
+4. Run sync_application with prune=true and dry_run=true and compare its result with your list.
+5. Summarize the resources that will be deleted, the risks, and whether to proceed.
+
+Only run the actual sync after the user has reviewed the summary and explicitly approved it.`, args["name"])
+			},
+		},
+		{
+			prompt: mcp.NewPrompt(OnboardRepositoryPrompt,
+				mcp.WithPromptDescription("Onboard a Git repository and the project its applications will belong to"),
+				mcp.WithArgument("repo_url", mcp.RequiredArgument(), mcp.ArgumentDescription("The URL of the Git repository")),
+				mcp.WithArgument("project", mcp.RequiredArgument(), mcp.ArgumentDescription("The ArgoCD project the repository's applications will belong to")),
+			),
+			set: ToolsetProjects, tool: GetProjectTool,
+			calls: func(args map[string]string) []promptCall {
+				return []promptCall{
+					{tool: GetRepositoryTool, args: map[string]any{"repo": args["repo_url"]}},
+					{tool: GetProjectTool, args: map[string]any{"name": args["project"]}},
+				}
+			},
+			instructions: func(args map[string]string) string {
+				return fmt.Sprintf(`Onboard the repository %s into the ArgoCD project %q.
+
+1. Check whether the repository is registered and its connection state is Successful. Registering repositories and their credentials is not available through these tools; if it is missing or failing, tell the user to add it with the ArgoCD CLI or UI.
+2. Check whether the project exists. If it does not, propose a create_project call with the repository in source_repos and the intended destinations, following least privilege.
+3. If the project exists, check that its source repositories include the repository and that its destinations and resource whitelists allow what will be deployed; propose the changes needed.
+4. Propose the create_application calls for the first applications, with the path, target revision and destination of each.
+
+Ask the user to confirm the proposed project and applications before creating anything.`, args["repo_url"], args["project"])
+			},
+		},
+		{
+			prompt: mcp.NewPrompt(ExplainOutOfSyncPrompt, append([]mcp.PromptOption{
+				mcp.WithPromptDescription("Explain why an application is OutOfSync with Git"),
+			}, appArguments...)...),
+			set: ToolsetApplications, tool: GetAppTool,
+			calls: func(args map[string]string) []promptCall {
+				return []promptCall{
+					{tool: GetAppTool, args: appArgs(args)},
+					{tool: GetApplicationResourceTreeTool, args: appArgs(args)},
+					{tool: GetAppManifestsTool, args: appArgs(args)},
+				}
+			},
+			instructions: func(args map[string]string) string {
+				return fmt.Sprintf(`Explain why the ArgoCD application %q is OutOfSync.
+
+1. From the application status, list the resources whose status is OutOfSync and the revision the application is compared to.
+2. For each, compare the desired manifest below with the live resource and name the fields that differ.
+3. Classify each difference: a change in Git not yet synced, a manual change in the cluster, a field set by a controller or webhook (defaulted fields, replicas managed by an autoscaler), or a resource missing from or extra to Git.
+4. For controller-managed fields, suggest an ignoreDifferences entry; for drift, suggest syncing or enabling self-heal; for Git changes, suggest syncing.
+
+Explain the findings in plain language before proposing any action.`, args["name"])
+			},
+		},
+	}
+}
+
+// Prompts returns the enabled runbook prompts paired with their handlers
+func (r *Registry) Prompts() []server.ServerPrompt {
+	handlers := make(map[string]server.ServerTool)
+	for _, tool := range r.Tools() {
+		handlers[tool.Tool.Name] = tool
+	}
+	multiInstance := len(r.clients.Instances()) > 1
+
+	var prompts []server.ServerPrompt
+	for _, rb := range r.runbooks() {
+		if !r.config.enabled(rb.set, rb.tool) {
+			continue
+		}
+		if multiInstance {
+			rb.prompt.Arguments = append(rb.prompt.Arguments, mcp.PromptArgument{
+				Name:        InstanceArgument,
+				Description: "Optional. Name of the ArgoCD instance to use. Use list_instances to see the configured instances.",
+			})
+		}
+		prompts = append(prompts, server.ServerPrompt{Prompt: rb.prompt, Handler: r.getPrompt(rb, handlers)})
+	}
+	return prompts
+}
+
+// getPrompt returns the handler rendering a runbook with its pre-fetched context
+func (r *Registry) getPrompt(rb runbook, handlers map[string]server.ServerTool) server.PromptHandlerFunc {
+	return func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		args := request.Params.Arguments
+		for _, arg := range rb.prompt.Arguments {
+			if arg.Required && args[arg.Name] == "" {
+				return nil, fmt.Errorf("%s is required", arg.Name)
+			}
+		}
+
+		var text strings.Builder
+		text.WriteString(rb.instructions(args))
+		text.WriteString("\n\nCurrent state, fetched from ArgoCD:\n")
+		for _, call := range rb.calls(args) {
+			fmt.Fprintf(&text, "\n## %s\n\n", call.tool.Name)
+			text.WriteString(r.prefetch(ctx, handlers, call, args[InstanceArgument]))
+		}
+
+		return mcp.NewGetPromptResult(rb.prompt.Description, []mcp.PromptMessage{
+			mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(text.String())),
+		}), nil
+	}
+}
+
+// prefetch runs a tool call through the registered handler, so that it is subject
+// to the same policy as calls made by the agent, and renders its result
+func (r *Registry) prefetch(ctx context.Context, handlers map[string]server.ServerTool, call promptCall, instance string) string {
+	tool, ok := handlers[call.tool.Name]
+	if !ok {
+		return fmt.Sprintf("%s is not enabled on this server.\n", call.tool.Name)
+	}
+
+	// Only pass the arguments the tool declares, leaving out empty optional ones
+	arguments := make(map[string]any)
+	if instance != "" {
+		call.args[InstanceArgument] = instance
+	}
+	for name, value := range call.args {
+		if _, ok := tool.Tool.InputSchema.Properties[name]; !ok || value == "" {
+			continue
+		}
+		arguments[name] = value
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = tool.Tool.Name
+	request.Params.Arguments = arguments
+	result, err := tool.Handler(ctx, request)
+	if err != nil {
+		return fmt.Sprintf("Error: %v\n", err)
+	}
+
+	var text strings.Builder
+	if result.IsError {
+		text.WriteString("Error: ")
+	}
+	for _, content := range result.Content {
+		if textContent, ok := mcp.AsTextContent(content); ok {
+			text.WriteString(textContent.Text)
+		}
+	}
+	if result.IsError {
+		text.WriteString("\n")
+		return text.String()
+	}
+	return "```\n" + strings.TrimRight(text.String(), "\n") + "\n```\n"
+}
@@ -0,0 +1,125 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client/mock"
+	"go.uber.org/mock/gomock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// getPrompt renders a prompt through the MCP server and returns its text
+func getPrompt(t *testing.T, s *server.MCPServer, name string, args map[string]string) (string, *mcp.JSONRPCError) {
+	t.Helper()
+	params, err := json.Marshal(map[string]any{"name": name, "arguments": args})
+	require.NoError(t, err)
+	message := fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"prompts/get","params":%s}`, params)
+	response := s.HandleMessage(context.Background(), []byte(message))
+	if rpcErr, ok := response.(mcp.JSONRPCError); ok {
+		return "", &rpcErr
+	}
+	rpcResponse, ok := response.(mcp.JSONRPCResponse)
+	require.True(t, ok, "unexpected response %T", response)
+	result, ok := rpcResponse.Result.(mcp.GetPromptResult)
+	require.True(t, ok, "unexpected result %T", rpcResponse.Result)
+	require.Len(t, result.Messages, 1)
+	assert.Equal(t, mcp.RoleUser, result.Messages[0].Role)
+	text, ok := mcp.AsTextContent(result.Messages[0].Content)
+	require.True(t, ok, "unexpected content %T", result.Messages[0].Content)
+	return text.Text, nil
+}
+
+func TestRegistry_Prompts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := mock.NewMockInterface(ctrl)
+	mockClient.EXPECT().Close().Return(nil).AnyTimes()
+
+	s := server.NewMCPServer("test", "1.0.0")
+	newMockRegistry(mockClient).Register(s)
+
+	t.Run("troubleshoot pre-fetches the application state", func(t *testing.T) {
+		mockClient.EXPECT().GetApplication(gomock.Any(), "guestbook").Return(&v1alpha1.Application{
+			ObjectMeta: metav1.ObjectMeta{Name: "guestbook"},
+			Status:     v1alpha1.ApplicationStatus{Health: v1alpha1.HealthStatus{Status: "Degraded"}},
+		}, nil)
+		mockClient.EXPECT().GetApplicationResourceTree(gomock.Any(), "guestbook", "", "team-a").Return(&v1alpha1.ApplicationTree{
+			Nodes: []v1alpha1.ResourceNode{{ResourceRef: v1alpha1.ResourceRef{Kind: "Pod", Name: "web-7d9f"}}},
+		}, nil)
+		mockClient.EXPECT().GetApplicationEvents(gomock.Any(), "guestbook", "", "", "", "", "team-a").Return(map[string]any{
+			"items": []map[string]string{{"reason": "BackOff"}},
+		}, nil)
+		mockClient.EXPECT().GetApplicationLogs(gomock.Any(), "guestbook", "", "", "", "", "", "", int64(promptLogTailLines), nil, false, false, "", "", "team-a").
+			Return(nil, errors.New("no pods"))
+
+		text, rpcErr := getPrompt(t, s, TroubleshootApplicationPrompt, map[string]string{"name": "guestbook", "project": "team-a"})
+		require.Nil(t, rpcErr)
+		assert.Contains(t, text, `Troubleshoot the ArgoCD application "guestbook"`)
+		assert.Contains(t, text, "## get_application\n")
+		assert.Contains(t, text, `"status": "Degraded"`)
+		assert.Contains(t, text, "web-7d9f")
+		assert.Contains(t, text, "BackOff")
+		assert.Contains(t, text, "## get_application_logs\n\nError: ")
+		assert.Contains(t, text, "no pods")
+	})
+
+	t.Run("onboard reports missing objects", func(t *testing.T) {
+		mockClient.EXPECT().GetRepository(gomock.Any(), "https://github.com/org/repo").Return(nil, errors.New("repository not found"))
+		mockClient.EXPECT().GetProject(gomock.Any(), "team-a").Return(&v1alpha1.AppProject{
+			ObjectMeta: metav1.ObjectMeta{Name: "team-a"},
+		}, nil)
+
+		text, rpcErr := getPrompt(t, s, OnboardRepositoryPrompt, map[string]string{"repo_url": "https://github.com/org/repo", "project": "team-a"})
+		require.Nil(t, rpcErr)
+		assert.Contains(t, text, "repository not found")
+		assert.Contains(t, text, `"name": "team-a"`)
+	})
+
+	t.Run("missing required argument", func(t *testing.T) {
+		_, rpcErr := getPrompt(t, s, ExplainOutOfSyncPrompt, map[string]string{"project": "team-a"})
+		require.NotNil(t, rpcErr)
+		assert.Contains(t, rpcErr.Error.Message, "name is required")
+	})
+}
+
+func TestRegistry_PromptsFollowEnabledTools(t *testing.T) {
+	names := func(prompts []server.ServerPrompt) []string {
+		var names []string
+		for _, prompt := range prompts {
+			names = append(names, prompt.Prompt.Name)
+		}
+		return names
+	}
+
+	all := NewRegistry(ClientFactory(nil), Config{ReadOnly: true})
+	assert.Equal(t, []string{TroubleshootApplicationPrompt, ReviewSyncPrunePrompt, OnboardRepositoryPrompt, ExplainOutOfSyncPrompt}, names(all.Prompts()))
+
+	projectsOnly := NewRegistry(ClientFactory(nil), Config{DenyTools: []string{ToolsetApplications}})
+	assert.Equal(t, []string{OnboardRepositoryPrompt}, names(projectsOnly.Prompts()))
+
+	// Context from disabled tools is left out
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockClient := mock.NewMockInterface(ctrl)
+	mockClient.EXPECT().Close().Return(nil).AnyTimes()
+	mockClient.EXPECT().GetProject(gomock.Any(), "team-a").Return(&v1alpha1.AppProject{}, nil)
+
+	s := server.NewMCPServer("test", "1.0.0")
+	NewRegistry(ClientFactory(func(ctx context.Context) (client.Interface, error) {
+		return mockClient, nil
+	}), Config{DenyTools: []string{ToolsetRepositories}}).Register(s)
+	text, rpcErr := getPrompt(t, s, OnboardRepositoryPrompt, map[string]string{"repo_url": "https://github.com/org/repo", "project": "team-a"})
+	require.Nil(t, rpcErr)
+	assert.Contains(t, text, "get_repository is not enabled on this server")
+}
@@ -0,0 +1,362 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"sigs.k8s.io/yaml"
+)
+
+// Output formats shared by every tool
+const (
+	OutputFormatTSV         = "tsv"
+	OutputFormatJSON        = "json"
+	OutputFormatCompactJSON = "json_compact"
+	OutputFormatYAML        = "yaml"
+	OutputFormatMarkdown    = "markdown"
+)
+
+// outputFormats lists the output formats in the order they are documented
+var outputFormats = []string{OutputFormatTSV, OutputFormatJSON, OutputFormatCompactJSON, OutputFormatYAML, OutputFormatMarkdown}
+
+// withOutputArguments adds the output_format and fields arguments to a read tool that does not declare them
+func withOutputArguments(tool mcp.Tool) mcp.Tool {
+	properties := make(map[string]any, len(tool.InputSchema.Properties)+2)
+	for name, property := range tool.InputSchema.Properties {
+		properties[name] = property
+	}
+	tool.InputSchema.Properties = properties
+	if _, ok := properties["output_format"]; !ok {
+		mcp.WithString("output_format",
+			mcp.Description("Optional. Output format: 'json' (default), 'json_compact', 'yaml', 'tsv' or 'markdown' (a table). Tables have a row per item and a column per field."),
+			mcp.Enum(outputFormats...),
+		)(&tool)
+	}
+	if _, ok := properties["fields"]; !ok {
+		mcp.WithString("fields",
+			mcp.Description("Optional. Comma-separated fields to keep in each item, as dotted paths such as 'metadata.name,status.sync.status'. Array elements are selected by index, e.g. 'spec.sources.0.repoURL'."),
+		)(&tool)
+	}
+	return tool
+}
+
+// outputArgs holds the output arguments of a call
+type outputArgs struct {
+	format string
+	fields []string
+}
+
+// parseOutputArgs reads the output arguments of a request
+func parseOutputArgs(request mcp.CallToolRequest) (outputArgs, error) {
+	args := outputArgs{format: request.GetString("output_format", OutputFormatJSON)}
+	if !slices.Contains(outputFormats, args.format) {
+		return args, fmt.Errorf("unknown output_format %q (expected %s)", args.format, strings.Join(outputFormats, ", "))
+	}
+	for _, field := range strings.Split(request.GetString("fields", ""), ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			args.fields = append(args.fields, field)
+		}
+	}
+	return args, nil
+}
+
+// formatted renders the JSON output of tools without pagination in the requested format
+func formatted(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := parseOutputArgs(request)
+		if err != nil {
+			return invalidArgument(ctx, err.Error()), nil
+		}
+		result, err := next(ctx, request)
+		if err != nil || result == nil || result.IsError || len(result.Content) == 0 {
+			return result, err
+		}
+		if text, ok := result.Content[0].(mcp.TextContent); ok {
+			result.Content[0] = mcp.NewTextContent(args.render(text.Text, pageSpec{}))
+		}
+		return result, nil
+	}
+}
+
+// render converts the JSON output of a tool to the requested format, keeping
+// only the selected fields. The items are the elements of a JSON array, those
+// of the object field named by spec, or the object itself. Output that is not
+// JSON, and JSON output without fields, is returned unchanged.
+func (a outputArgs) render(output string, spec pageSpec) string {
+	if a.format == OutputFormatJSON && len(a.fields) == 0 {
+		return output
+	}
+	trimmed := strings.TrimSpace(output)
+	if !strings.HasPrefix(trimmed, "[") && !strings.HasPrefix(trimmed, "{") {
+		return output
+	}
+
+	// Find the items, and the object holding them if any
+	var rows []json.RawMessage
+	var container []objectField
+	index := -1
+	single := false
+	if strings.HasPrefix(trimmed, "[") {
+		if err := json.Unmarshal([]byte(trimmed), &rows); err != nil {
+			return output
+		}
+	} else {
+		fields, ok := objectFields(trimmed)
+		if !ok {
+			return output
+		}
+		for i, field := range fields {
+			if spec.field != "" && field.key == spec.field && json.Unmarshal(field.value, &rows) == nil {
+				container, index = fields, i
+			}
+		}
+		if index < 0 {
+			rows, single = []json.RawMessage{json.RawMessage(trimmed)}, true
+		}
+	}
+
+	switch a.format {
+	case OutputFormatTSV, OutputFormatMarkdown:
+		return a.table(rows)
+	}
+
+	if len(a.fields) > 0 {
+		for i, row := range rows {
+			rows[i] = selectFields(row, a.fields)
+		}
+	}
+	var document json.RawMessage
+	switch {
+	case single:
+		document = rows[0]
+	case container != nil:
+		var buf bytes.Buffer
+		buf.WriteString("{")
+		for i, field := range container {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			key, _ := json.Marshal(field.key)
+			buf.Write(key)
+			buf.WriteString(":")
+			if i == index {
+				items, _ := json.Marshal(rows)
+				buf.Write(items)
+			} else {
+				buf.Write(field.value)
+			}
+		}
+		buf.WriteString("}")
+		document = buf.Bytes()
+	default:
+		document, _ = json.Marshal(rows)
+	}
+
+	switch a.format {
+	case OutputFormatCompactJSON:
+		return rawStrings([]json.RawMessage{document})[0]
+	case OutputFormatYAML:
+		data, err := yaml.JSONToYAML(document)
+		if err != nil {
+			return output
+		}
+		return string(data)
+	}
+	return renderJSON(rawStrings([]json.RawMessage{document})[0], true)
+}
+
+// table renders rows as TSV or as a markdown table. The columns are the selected
+// fields, or else the top-level fields of the rows in the order they first appear.
+func (a outputArgs) table(rows []json.RawMessage) string {
+	columns := a.fields
+	values := make([]map[string]any, len(rows))
+	for i, row := range rows {
+		decoder := json.NewDecoder(bytes.NewReader(row))
+		decoder.UseNumber()
+		var value any
+		if err := decoder.Decode(&value); err != nil {
+			continue
+		}
+		object, ok := value.(map[string]any)
+		if !ok {
+			// Rows that are not objects, such as names, fill a single column
+			object = map[string]any{"value": value}
+		}
+		values[i] = object
+		if len(a.fields) > 0 {
+			continue
+		}
+		if fields, ok := objectFields(string(row)); ok {
+			for _, field := range fields {
+				if !slices.Contains(columns, field.key) {
+					columns = append(columns, field.key)
+				}
+			}
+		} else if !slices.Contains(columns, "value") {
+			columns = append(columns, "value")
+		}
+	}
+
+	cell := escapeField
+	if a.format == OutputFormatMarkdown {
+		cell = escapeMarkdownCell
+	}
+	var sb strings.Builder
+	writeRow := func(cells []string) {
+		if a.format == OutputFormatMarkdown {
+			sb.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+		} else {
+			sb.WriteString(strings.Join(cells, "\t") + "\n")
+		}
+	}
+
+	headers := make([]string, len(columns))
+	for i, column := range columns {
+		headers[i] = cell(column)
+	}
+	writeRow(headers)
+	if a.format == OutputFormatMarkdown {
+		separators := make([]string, len(columns))
+		for i := range separators {
+			separators[i] = "---"
+		}
+		writeRow(separators)
+	}
+	for _, value := range values {
+		cells := make([]string, len(columns))
+		for i, column := range columns {
+			field, _ := lookupField(value, column)
+			cells[i] = cell(cellText(field))
+		}
+		writeRow(cells)
+	}
+	return sb.String()
+}
+
+// selectFields keeps the fields at the given paths of a JSON value, nesting them as in the value
+func selectFields(row json.RawMessage, paths []string) json.RawMessage {
+	decoder := json.NewDecoder(bytes.NewReader(row))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return row
+	}
+
+	selected := &orderedObject{}
+	for _, path := range paths {
+		field, ok := lookupField(value, path)
+		if !ok {
+			continue
+		}
+		selected.set(strings.Split(path, "."), field)
+	}
+	data, err := json.Marshal(selected)
+	if err != nil {
+		return row
+	}
+	return data
+}
+
+// lookupField returns the value at a dotted path; numeric segments index arrays
+func lookupField(value any, path string) (any, bool) {
+	for _, segment := range strings.Split(path, ".") {
+		switch v := value.(type) {
+		case map[string]any:
+			field, ok := v[segment]
+			if !ok {
+				return nil, false
+			}
+			value = field
+		case []any:
+			i, err := strconv.Atoi(segment)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, false
+			}
+			value = v[i]
+		default:
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// cellText renders a value in a table cell; objects and arrays become compact JSON
+func cellText(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+// escapeMarkdownCell escapes pipes and line breaks in markdown table cells
+func escapeMarkdownCell(field string) string {
+	field = strings.ReplaceAll(field, "|", "\\|")
+	field = strings.ReplaceAll(field, "\r", "")
+	return strings.ReplaceAll(field, "\n", "<br>")
+}
+
+// orderedObject is a JSON object that keeps its keys in insertion order
+type orderedObject struct {
+	keys   []string
+	values map[string]any
+}
+
+// set stores value under a path of keys, creating the nested objects on the way
+func (o *orderedObject) set(path []string, value any) {
+	if o.values == nil {
+		o.values = make(map[string]any)
+	}
+	key := path[0]
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	if len(path) == 1 {
+		o.values[key] = value
+		return
+	}
+	child, ok := o.values[key].(*orderedObject)
+	if !ok {
+		child = &orderedObject{}
+		o.values[key] = child
+	}
+	child.set(path[1:], value)
+}
+
+// MarshalJSON encodes the object with its keys in insertion order
+func (o *orderedObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("{")
+	for i, key := range o.keys {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		name, _ := json.Marshal(key)
+		buf.Write(name)
+		buf.WriteString(":")
+		value, err := json.Marshal(o.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(value)
+	}
+	buf.WriteString("}")
+	return buf.Bytes(), nil
+}
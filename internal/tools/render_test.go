@@ -0,0 +1,187 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client/mock"
+	"go.uber.org/mock/gomock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestOutputArgs_Render(t *testing.T) {
+	items := `[
+  {"metadata": {"name": "a", "labels": {"team": "x|y"}}, "status": {"sync": "Synced"}},
+  {"metadata": {"name": "b"}, "status": {"sync": "OutOfSync"}, "spec": {"sources": [{"repoURL": "https://r"}]}}
+]`
+
+	tests := []struct {
+		name   string
+		args   outputArgs
+		spec   pageSpec
+		output string
+		want   string
+	}{
+		{
+			name:   "json without fields is unchanged",
+			args:   outputArgs{format: OutputFormatJSON},
+			output: items,
+			want:   items,
+		},
+		{
+			name:   "json fields",
+			args:   outputArgs{format: OutputFormatJSON, fields: []string{"metadata.name", "status.sync"}},
+			output: items,
+			want:   "[\n  {\n    \"metadata\": {\n      \"name\": \"a\"\n    },\n    \"status\": {\n      \"sync\": \"Synced\"\n    }\n  },\n  {\n    \"metadata\": {\n      \"name\": \"b\"\n    },\n    \"status\": {\n      \"sync\": \"OutOfSync\"\n    }\n  }\n]",
+		},
+		{
+			name:   "compact json with an array index",
+			args:   outputArgs{format: OutputFormatCompactJSON, fields: []string{"metadata.name", "spec.sources.0.repoURL"}},
+			output: items,
+			want:   `[{"metadata":{"name":"a"}},{"metadata":{"name":"b"},"spec":{"sources":{"0":{"repoURL":"https://r"}}}}]`,
+		},
+		{
+			name:   "yaml",
+			args:   outputArgs{format: OutputFormatYAML, fields: []string{"metadata.name"}},
+			output: items,
+			want:   "- metadata:\n    name: a\n- metadata:\n    name: b\n",
+		},
+		{
+			name:   "tsv columns are the fields",
+			args:   outputArgs{format: OutputFormatTSV, fields: []string{"metadata.name", "status.sync", "spec.sources.0.repoURL"}},
+			output: items,
+			want:   "metadata.name\tstatus.sync\tspec.sources.0.repoURL\na\tSynced\t\nb\tOutOfSync\thttps://r\n",
+		},
+		{
+			name:   "markdown escapes cells",
+			args:   outputArgs{format: OutputFormatMarkdown, fields: []string{"metadata.name", "metadata.labels"}},
+			output: items,
+			want:   "| metadata.name | metadata.labels |\n| --- | --- |\n| a | {\"team\":\"x\\|y\"} |\n| b |  |\n",
+		},
+		{
+			name:   "tsv columns default to the top-level fields",
+			args:   outputArgs{format: OutputFormatTSV},
+			output: `[{"name":"a","count":1},{"name":"b","extra":true}]`,
+			want:   "name\tcount\textra\na\t1\t\nb\t\ttrue\n",
+		},
+		{
+			name:   "items of an object field",
+			args:   outputArgs{format: OutputFormatCompactJSON, fields: []string{"name"}},
+			spec:   pageSpec{field: "items"},
+			output: `{"items":[{"name":"a","kind":"Pod"}],"count":1}`,
+			want:   `{"items":[{"name":"a"}],"count":1}`,
+		},
+		{
+			name:   "strings fill a value column",
+			args:   outputArgs{format: OutputFormatMarkdown},
+			spec:   pageSpec{field: "names"},
+			output: `{"names":["a","b"],"count":2}`,
+			want:   "| value |\n| --- |\n| a |\n| b |\n",
+		},
+		{
+			name:   "a single object is one row",
+			args:   outputArgs{format: OutputFormatTSV, fields: []string{"metadata.name"}},
+			output: `{"metadata":{"name":"a\tb"}}`,
+			want:   "metadata.name\na\\tb\n",
+		},
+		{
+			name:   "text output is unchanged",
+			args:   outputArgs{format: OutputFormatYAML},
+			output: "No applications found matching the criteria.",
+			want:   "No applications found matching the criteria.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.args.render(tt.output, tt.spec))
+		})
+	}
+}
+
+func TestParseOutputArgs(t *testing.T) {
+	args, err := parseOutputArgs(paginationRequest(nil))
+	require.NoError(t, err)
+	assert.Equal(t, outputArgs{format: OutputFormatJSON}, args)
+
+	args, err = parseOutputArgs(paginationRequest(map[string]any{"output_format": "yaml", "fields": " metadata.name, ,status "}))
+	require.NoError(t, err)
+	assert.Equal(t, outputArgs{format: OutputFormatYAML, fields: []string{"metadata.name", "status"}}, args)
+
+	_, err = parseOutputArgs(paginationRequest(map[string]any{"output_format": "xml"}))
+	assert.ErrorContains(t, err, `unknown output_format "xml"`)
+}
+
+func TestRegistry_OutputFormats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	app := &v1alpha1.Application{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-app", Namespace: "argocd"},
+		Spec:       v1alpha1.ApplicationSpec{Project: "default"},
+	}
+	mockClient := mock.NewMockInterface(ctrl)
+	mockClient.EXPECT().Close().Return(nil).AnyTimes()
+	mockClient.EXPECT().GetApplication(gomock.Any(), "test-app").Return(app, nil).AnyTimes()
+	mockClient.EXPECT().ListApplications(gomock.Any(), "").Return(&v1alpha1.ApplicationList{Items: []v1alpha1.Application{*app}}, nil).AnyTimes()
+
+	registry := newMockRegistry(mockClient)
+	handlers := make(map[string]func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error))
+	for _, tool := range registry.Tools() {
+		// Only read tools take output arguments
+		if isDestructive(tool.Tool) {
+			assert.NotContains(t, tool.Tool.InputSchema.Properties, "output_format", "tool %s", tool.Tool.Name)
+			assert.NotContains(t, tool.Tool.InputSchema.Properties, "fields", "tool %s", tool.Tool.Name)
+			continue
+		}
+		assert.Contains(t, tool.Tool.InputSchema.Properties, "output_format", "tool %s", tool.Tool.Name)
+		assert.Contains(t, tool.Tool.InputSchema.Properties, "fields", "tool %s", tool.Tool.Name)
+		handlers[tool.Tool.Name] = tool.Handler
+	}
+	// The shared tool definitions are left untouched
+	assert.NotContains(t, GetAppTool.InputSchema.Properties, "output_format")
+
+	call := func(name string, args map[string]any) string {
+		t.Helper()
+		result, err := handlers[name](context.Background(), mcp.CallToolRequest{
+			Params: mcp.CallToolParams{Name: name, Arguments: args},
+		})
+		require.NoError(t, err)
+		require.NotEmpty(t, result.Content)
+		text := result.Content[0].(mcp.TextContent).Text
+		require.False(t, result.IsError, text)
+		return text
+	}
+
+	t.Run("tool without pagination", func(t *testing.T) {
+		got := call(GetAppTool.Name, map[string]any{"name": "test-app", "output_format": "yaml", "fields": "metadata.name,spec.project"})
+		assert.Equal(t, "metadata:\n  name: test-app\nspec:\n  project: default\n", got)
+	})
+
+	t.Run("list_application keeps its tsv output", func(t *testing.T) {
+		got := call(ListAppsTool.Name, map[string]any{})
+		assert.Contains(t, got, "name\tproject\tsyncStatus\thealthStatus\n")
+	})
+
+	t.Run("list_application renders other formats from json", func(t *testing.T) {
+		got := call(ListAppsTool.Name, map[string]any{"output_format": "markdown", "fields": "name,project"})
+		assert.Equal(t, "| name | project |\n| --- | --- |\n| test-app | default |\n", got)
+	})
+
+	t.Run("list_application fields win over optional_fields", func(t *testing.T) {
+		got := call(ListAppsTool.Name, map[string]any{"fields": "name", "optional_fields": "source,destination"})
+		assert.JSONEq(t, `[{"name":"test-app"}]`, got)
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		result, err := handlers[GetAppTool.Name](context.Background(), mcp.CallToolRequest{
+			Params: mcp.CallToolParams{Name: GetAppTool.Name, Arguments: map[string]any{"name": "test-app", "output_format": "xml"}},
+		})
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+	})
+}
@@ -0,0 +1,202 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	repoapiclient "github.com/argoproj/argo-cd/v2/reposerver/apiclient"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client"
+	"sigs.k8s.io/yaml"
+)
+
+// URIs of the resource templates; {server} is the percent-encoded cluster URL
+const (
+	ApplicationResourceURI          = "argocd://applications/{name}"
+	ApplicationManifestsResourceURI = "argocd://applications/{name}/manifests"
+	ApplicationTreeResourceURI      = "argocd://applications/{name}/tree"
+	ProjectResourceURI              = "argocd://projects/{name}"
+	ClusterResourceURI              = "argocd://clusters/{server}"
+)
+
+// Formats and MIME types of resource contents
+const (
+	ResourceFormatJSON = "json"
+	ResourceFormatYAML = "yaml"
+
+	MIMETypeJSON = "application/json"
+	MIMETypeYAML = "application/yaml"
+)
+
+// resourceFormatArgument selects JSON or YAML contents through the resource URI query
+const resourceFormatArgument = "format"
+
+// resourceTemplate is a resource template backed by a client getter. It is
+// exposed when the tool returning the same object is enabled.
+type resourceTemplate struct {
+	set         string
+	tool        mcp.Tool
+	uri         string
+	name        string
+	description string
+	// arg is the URI template variable identifying the object
+	arg  string
+	read func(ctx context.Context, argoClient client.Interface, id string) (any, error)
+}
+
+func (r *Registry) resourceTemplates() []resourceTemplate {
+	return []resourceTemplate{
+		{
+			set: ToolsetApplications, tool: GetAppTool, uri: ApplicationResourceURI, arg: "name",
+			name:        "ArgoCD application",
+			description: "Spec and status of an ArgoCD application",
+			read: func(ctx context.Context, argoClient client.Interface, name string) (any, error) {
+				return argoClient.GetApplication(ctx, name)
+			},
+		},
+		{
+			set: ToolsetApplications, tool: GetAppManifestsTool, uri: ApplicationManifestsResourceURI, arg: "name",
+			name:        "ArgoCD application manifests",
+			description: "Rendered Kubernetes manifests of an ArgoCD application at its target revision",
+			read: func(ctx context.Context, argoClient client.Interface, name string) (any, error) {
+				return argoClient.GetApplicationManifests(ctx, name, "")
+			},
+		},
+		{
+			set: ToolsetApplications, tool: GetApplicationResourceTreeTool, uri: ApplicationTreeResourceURI, arg: "name",
+			name:        "ArgoCD application resource tree",
+			description: "Kubernetes resources managed by an ArgoCD application and their health",
+			read: func(ctx context.Context, argoClient client.Interface, name string) (any, error) {
+				return argoClient.GetApplicationResourceTree(ctx, name, "", "")
+			},
+		},
+		{
+			set: ToolsetProjects, tool: GetProjectTool, uri: ProjectResourceURI, arg: "name",
+			name:        "ArgoCD project",
+			description: "Spec of an ArgoCD project",
+			read: func(ctx context.Context, argoClient client.Interface, name string) (any, error) {
+				return argoClient.GetProject(ctx, name)
+			},
+		},
+		{
+			set: ToolsetClusters, tool: GetClusterTool, uri: ClusterResourceURI, arg: "server",
+			name:        "ArgoCD cluster",
+			description: "Connection details and state of a cluster managed by ArgoCD; the server URL must be percent-encoded",
+			read: func(ctx context.Context, argoClient client.Interface, server string) (any, error) {
+				return argoClient.GetCluster(ctx, server)
+			},
+		},
+	}
+}
+
+// ResourceTemplates returns the enabled resource templates paired with their handlers
+func (r *Registry) ResourceTemplates() []server.ServerResourceTemplate {
+	query := "{?" + resourceFormatArgument + "}"
+	if len(r.clients.Instances()) > 1 {
+		query = "{?" + resourceFormatArgument + "," + InstanceArgument + "}"
+	}
+
+	var templates []server.ServerResourceTemplate
+	for _, t := range r.resourceTemplates() {
+		if !r.config.enabled(t.set, t.tool) {
+			continue
+		}
+		templates = append(templates, server.ServerResourceTemplate{
+			Template: mcp.NewResourceTemplate(t.uri+query, t.name,
+				mcp.WithTemplateDescription(t.description+". Append ?format=yaml for YAML."),
+				mcp.WithTemplateMIMEType(MIMETypeJSON),
+			),
+			Handler: r.readResource(t),
+		})
+	}
+	return templates
+}
+
+// readResource returns the handler reading the object identified by a resource URI
+func (r *Registry) readResource(t resourceTemplate) server.ResourceTemplateHandlerFunc {
+	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		id := resourceArgument(request, t.arg)
+		if id == "" {
+			return nil, fmt.Errorf("%s is required in %s", t.arg, request.Params.URI)
+		}
+		format := strings.ToLower(resourceArgument(request, resourceFormatArgument))
+		if format != "" && format != ResourceFormatJSON && format != ResourceFormatYAML {
+			return nil, fmt.Errorf("unknown format %q (expected json or yaml)", format)
+		}
+
+		argoClient, err := r.clients.Client(ctx, resourceArgument(request, InstanceArgument))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create gRPC client: %w", err)
+		}
+		defer func() { _ = argoClient.Close() }()
+
+		value, err := t.read(ctx, argoClient, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", request.Params.URI, err)
+		}
+		return resourceContents(request.Params.URI, format, value)
+	}
+}
+
+// resourceArgument returns a variable matched from the resource URI
+func resourceArgument(request mcp.ReadResourceRequest, name string) string {
+	switch value := request.Params.Arguments[name].(type) {
+	case string:
+		return value
+	case []string:
+		if len(value) > 0 {
+			return value[0]
+		}
+	}
+	return ""
+}
+
+// resourceContents encodes value as YAML text contents when format is yaml, and as JSON otherwise
+func resourceContents(uri, format string, value any) ([]mcp.ResourceContents, error) {
+	// Manifests are JSON strings; decode them so that they render as documents
+	if manifests, ok := value.(*repoapiclient.ManifestResponse); ok {
+		return manifestContents(uri, format, manifests)
+	}
+
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s: %w", uri, err)
+	}
+
+	if format != ResourceFormatYAML {
+		return []mcp.ResourceContents{mcp.TextResourceContents{URI: uri, MIMEType: MIMETypeJSON, Text: string(data)}}, nil
+	}
+	data, err = yaml.JSONToYAML(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s: %w", uri, err)
+	}
+	return []mcp.ResourceContents{mcp.TextResourceContents{URI: uri, MIMEType: MIMETypeYAML, Text: string(data)}}, nil
+}
+
+// manifestContents renders manifests as a JSON array or as a multi-document YAML stream
+func manifestContents(uri, format string, manifests *repoapiclient.ManifestResponse) ([]mcp.ResourceContents, error) {
+	if format != ResourceFormatYAML {
+		documents := make([]json.RawMessage, 0, len(manifests.Manifests))
+		for _, manifest := range manifests.Manifests {
+			documents = append(documents, json.RawMessage(manifest))
+		}
+		data, err := json.MarshalIndent(documents, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s: %w", uri, err)
+		}
+		return []mcp.ResourceContents{mcp.TextResourceContents{URI: uri, MIMEType: MIMETypeJSON, Text: string(data)}}, nil
+	}
+
+	documents := make([]string, 0, len(manifests.Manifests))
+	for _, manifest := range manifests.Manifests {
+		document, err := yaml.JSONToYAML([]byte(manifest))
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s: %w", uri, err)
+		}
+		documents = append(documents, string(document))
+	}
+	return []mcp.ResourceContents{mcp.TextResourceContents{URI: uri, MIMEType: MIMETypeYAML, Text: strings.Join(documents, "---\n")}}, nil
+}
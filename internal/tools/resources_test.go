@@ -0,0 +1,152 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	repoapiclient "github.com/argoproj/argo-cd/v2/reposerver/apiclient"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client/mock"
+	"go.uber.org/mock/gomock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// readResource reads uri through the MCP server so that URI template matching is exercised
+func readResource(t *testing.T, s *server.MCPServer, uri string) (mcp.TextResourceContents, *mcp.JSONRPCError) {
+	t.Helper()
+	message := fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"resources/read","params":{"uri":%q}}`, uri)
+	response := s.HandleMessage(context.Background(), []byte(message))
+	if rpcErr, ok := response.(mcp.JSONRPCError); ok {
+		return mcp.TextResourceContents{}, &rpcErr
+	}
+	rpcResponse, ok := response.(mcp.JSONRPCResponse)
+	require.True(t, ok, "unexpected response %T", response)
+	result, ok := rpcResponse.Result.(mcp.ReadResourceResult)
+	require.True(t, ok, "unexpected result %T", rpcResponse.Result)
+	require.Len(t, result.Contents, 1)
+	contents, ok := result.Contents[0].(mcp.TextResourceContents)
+	require.True(t, ok, "unexpected contents %T", result.Contents[0])
+	return contents, nil
+}
+
+func TestRegistry_Resources(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := mock.NewMockInterface(ctrl)
+	mockClient.EXPECT().Close().Return(nil).AnyTimes()
+	mockClient.EXPECT().GetApplication(gomock.Any(), "guestbook").Return(&v1alpha1.Application{
+		ObjectMeta: metav1.ObjectMeta{Name: "guestbook"},
+		Spec:       v1alpha1.ApplicationSpec{Project: "default"},
+	}, nil).Times(2)
+	mockClient.EXPECT().GetApplication(gomock.Any(), "missing").Return(nil, errors.New("not found"))
+	mockClient.EXPECT().GetApplicationManifests(gomock.Any(), "guestbook", "").Return(&repoapiclient.ManifestResponse{
+		Manifests: []string{`{"apiVersion":"v1","kind":"Service","metadata":{"name":"a"}}`, `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"b"}}`},
+	}, nil).Times(2)
+	mockClient.EXPECT().GetApplicationResourceTree(gomock.Any(), "guestbook", "", "").Return(&v1alpha1.ApplicationTree{
+		Nodes: []v1alpha1.ResourceNode{{ResourceRef: v1alpha1.ResourceRef{Kind: "Pod", Name: "web"}}},
+	}, nil)
+	mockClient.EXPECT().GetProject(gomock.Any(), "default").Return(&v1alpha1.AppProject{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+	}, nil)
+	mockClient.EXPECT().GetCluster(gomock.Any(), "https://kubernetes.default.svc").Return(&v1alpha1.Cluster{
+		Server: "https://kubernetes.default.svc", Name: "in-cluster",
+	}, nil)
+
+	s := server.NewMCPServer("test", "1.0.0")
+	newMockRegistry(mockClient).Register(s)
+
+	t.Run("application as JSON", func(t *testing.T) {
+		contents, rpcErr := readResource(t, s, "argocd://applications/guestbook")
+		require.Nil(t, rpcErr)
+		assert.Equal(t, MIMETypeJSON, contents.MIMEType)
+		assert.Equal(t, "argocd://applications/guestbook", contents.URI)
+		var app v1alpha1.Application
+		require.NoError(t, json.Unmarshal([]byte(contents.Text), &app))
+		assert.Equal(t, "guestbook", app.Name)
+	})
+
+	t.Run("application as YAML", func(t *testing.T) {
+		contents, rpcErr := readResource(t, s, "argocd://applications/guestbook?format=yaml")
+		require.Nil(t, rpcErr)
+		assert.Equal(t, MIMETypeYAML, contents.MIMEType)
+		assert.Contains(t, contents.Text, "name: guestbook")
+		assert.Contains(t, contents.Text, "project: default")
+	})
+
+	t.Run("manifests", func(t *testing.T) {
+		contents, rpcErr := readResource(t, s, "argocd://applications/guestbook/manifests")
+		require.Nil(t, rpcErr)
+		var documents []map[string]any
+		require.NoError(t, json.Unmarshal([]byte(contents.Text), &documents))
+		assert.Len(t, documents, 2)
+
+		contents, rpcErr = readResource(t, s, "argocd://applications/guestbook/manifests?format=yaml")
+		require.Nil(t, rpcErr)
+		assert.Equal(t, "apiVersion: v1\nkind: Service\nmetadata:\n  name: a\n---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: b\n", contents.Text)
+	})
+
+	t.Run("tree", func(t *testing.T) {
+		contents, rpcErr := readResource(t, s, "argocd://applications/guestbook/tree")
+		require.Nil(t, rpcErr)
+		assert.Contains(t, contents.Text, `"name": "web"`)
+	})
+
+	t.Run("project", func(t *testing.T) {
+		contents, rpcErr := readResource(t, s, "argocd://projects/default")
+		require.Nil(t, rpcErr)
+		assert.Contains(t, contents.Text, `"name": "default"`)
+	})
+
+	t.Run("cluster with encoded server", func(t *testing.T) {
+		contents, rpcErr := readResource(t, s, "argocd://clusters/https%3A%2F%2Fkubernetes.default.svc")
+		require.Nil(t, rpcErr)
+		assert.Contains(t, contents.Text, `"name": "in-cluster"`)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, rpcErr := readResource(t, s, "argocd://applications/missing")
+		require.NotNil(t, rpcErr)
+		assert.Contains(t, rpcErr.Error.Message, "not found")
+
+		_, rpcErr = readResource(t, s, "argocd://applications/guestbook?format=xml")
+		require.NotNil(t, rpcErr)
+		assert.Contains(t, rpcErr.Error.Message, "unknown format")
+	})
+}
+
+func TestRegistry_ResourceTemplates(t *testing.T) {
+	uris := func(registry *Registry) []string {
+		var uris []string
+		for _, template := range registry.ResourceTemplates() {
+			uris = append(uris, template.Template.URITemplate.Raw())
+		}
+		return uris
+	}
+
+	assert.Equal(t, []string{
+		"argocd://applications/{name}{?format}",
+		"argocd://applications/{name}/manifests{?format}",
+		"argocd://applications/{name}/tree{?format}",
+		"argocd://projects/{name}{?format}",
+		"argocd://clusters/{server}{?format}",
+	}, uris(newMockRegistry(nil)))
+
+	// Resources follow the tool selection of the tools returning the same objects
+	denied := NewRegistry(ClientFactory(nil), Config{DenyTools: []string{ToolsetClusters, GetAppManifestsTool.Name}})
+	assert.Equal(t, []string{
+		"argocd://applications/{name}{?format}",
+		"argocd://applications/{name}/tree{?format}",
+		"argocd://projects/{name}{?format}",
+	}, uris(denied))
+
+	multi := NewRegistry(newFakeProvider(nil, nil), Config{})
+	assert.Contains(t, uris(multi), "argocd://projects/{name}{?format,instance}")
+}
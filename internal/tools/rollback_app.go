@@ -0,0 +1,193 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client"
+)
+
+// RollbackPrevious selects the deployment before the current one
+const RollbackPrevious = "previous"
+
+// RollbackAppTool defines the rollback_application tool schema
+var RollbackAppTool = mcp.NewTool("rollback_application",
+	mcp.WithDescription("Rolls an ArgoCD application back to a deployment of its history (see get_application_history). Argo CD cannot roll back applications with automated sync, so the call is refused for them unless disable_auto_sync is set."),
+	mcp.WithDestructiveHintAnnotation(true),
+	mcp.WithString("name",
+		mcp.Required(),
+		mcp.Description("The name of the application to roll back."),
+	),
+	mcp.WithString("id",
+		mcp.Required(),
+		mcp.Description("The history ID of the deployment to roll back to, or 'previous' for the deployment before the current one."),
+	),
+	mcp.WithBoolean("disable_auto_sync",
+		mcp.Description("If the application has automated sync, turn it off before rolling back; otherwise the call is refused (default: false). Automated sync stays off until it is enabled again, unless the rollback itself fails."),
+	),
+	mcp.WithBoolean("wait",
+		mcp.Description("Wait until the rollback operation finishes and return a summary of the outcome (default: false)."),
+	),
+	mcp.WithNumber("wait_timeout_seconds",
+		mcp.Description(fmt.Sprintf("Optional. How long to wait, in seconds (default: %d, max: %d).", int(DefaultWaitTimeout.Seconds()), int(MaxWaitTimeout.Seconds()))),
+	),
+)
+
+// HandleRollbackApplication processes rollback_application tool requests
+func (r *Registry) HandleRollbackApplication(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	appName := request.GetString("name", "")
+	id := request.GetString("id", "")
+	disableAutoSync := request.GetBool("disable_auto_sync", false)
+	var wait *syncWait
+	if request.GetBool("wait", false) {
+		timeout, err := waitTimeout(request, "wait_timeout_seconds")
+		if err != nil {
+			return invalidArgument(ctx, err.Error()), nil
+		}
+		wait = &syncWait{timeout: timeout, progress: newProgressReporter(ctx, request)}
+	}
+
+	// Get the gRPC client for this call
+	argoClient, err := r.client(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to create gRPC client: %v", err)), nil
+	}
+	defer func() { _ = argoClient.Close() }()
+
+	return rollbackApplicationHandler(ctx, argoClient, appName, id, disableAutoSync, wait)
+}
+
+// RollbackOutcome summarizes a rollback
+type RollbackOutcome struct {
+	Application      string       `json:"application"`
+	ID               int64        `json:"id"`
+	Revisions        []string     `json:"revisions"`
+	AutoSyncDisabled bool         `json:"autoSyncDisabled,omitempty"`
+	Message          string       `json:"message"`
+	Wait             *WaitOutcome `json:"wait,omitempty"`
+}
+
+// rollbackApplicationHandler handles the core logic for rolling back an application.
+// This is separated out to enable testing with mocked clients.
+func rollbackApplicationHandler(
+	ctx context.Context,
+	argoClient client.Interface,
+	appName string,
+	id string,
+	disableAutoSync bool,
+	wait *syncWait,
+) (*mcp.CallToolResult, error) {
+	if appName == "" {
+		return invalidArgument(ctx, "Application name is required"), nil
+	}
+	if id == "" {
+		return invalidArgument(ctx, "id is required"), nil
+	}
+
+	app, err := argoClient.GetApplication(ctx, appName)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get application: %v", err)), nil
+	}
+	deployment, err := rollbackTarget(app.Status.History, id)
+	if err != nil {
+		return invalidArgument(ctx, err.Error()), nil
+	}
+
+	outcome := RollbackOutcome{
+		Application: app.Name,
+		ID:          deployment.ID,
+		Revisions:   deployment.Revisions,
+	}
+	if len(outcome.Revisions) == 0 {
+		outcome.Revisions = []string{deployment.Revision}
+	}
+
+	// Argo CD refuses to roll back applications with automated sync, which would undo the rollback
+	var disabled *v1alpha1.Application
+	var automated *v1alpha1.SyncPolicyAutomated
+	if app.Spec.SyncPolicy != nil && app.Spec.SyncPolicy.Automated != nil {
+		if !disableAutoSync {
+			return mcp.NewToolResultError(fmt.Sprintf("Application %s has automated sync enabled, which would undo the rollback; pass disable_auto_sync=true to turn it off first", appName)), nil
+		}
+		automated = app.Spec.SyncPolicy.Automated
+		updated := app.DeepCopy()
+		updated.Spec.SyncPolicy.Automated = nil
+		if disabled, err = argoClient.UpdateApplication(ctx, updated); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to disable automated sync: %v", err)), nil
+		}
+		outcome.AutoSyncDisabled = true
+	}
+
+	app, err = argoClient.RollbackApplication(ctx, appName, deployment.ID)
+	if err != nil {
+		if disabled == nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to rollback application: %v", err)), nil
+		}
+		// Nothing was rolled back, so automated sync is turned back on
+		if restoreErr := restoreAutoSync(ctx, argoClient, disabled, automated); restoreErr != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to rollback application: %v; automated sync was disabled and could not be enabled again: %v", err, restoreErr)), nil
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to rollback application: %v; automated sync was enabled again", err)), nil
+	}
+	outcome.Message = fmt.Sprintf("Rolling back %s to deployment %d.", appName, deployment.ID)
+	if outcome.AutoSyncDisabled {
+		outcome.Message += " Automated sync was disabled and stays off until it is enabled again."
+	}
+
+	if wait != nil {
+		// The application stays out of sync with its target revision after a
+		// rollback, so only the rollback operation is waited for
+		outcome.Wait = waitForApplication(ctx, argoClient, app, WaitUntilOperation, wait.timeout, wait.progress)
+	}
+
+	jsonData, err := json.MarshalIndent(outcome, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to format response: %v", err)), nil
+	}
+	if outcome.Wait != nil && outcome.Wait.Result != WaitReached {
+		return mcp.NewToolResultError(fmt.Sprintf("Rollback of %s to deployment %d did not finish (%s): %s\n%s", appName, deployment.ID, outcome.Wait.Result, outcome.Wait.Message, jsonData)), nil
+	}
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// restoreAutoSync turns automated sync of app back on after a failed rollback,
+// even when the request that rolled back was cancelled
+func restoreAutoSync(ctx context.Context, argoClient client.Interface, app *v1alpha1.Application, automated *v1alpha1.SyncPolicyAutomated) error {
+	restored := app.DeepCopy()
+	if restored.Spec.SyncPolicy == nil {
+		restored.Spec.SyncPolicy = &v1alpha1.SyncPolicy{}
+	}
+	restored.Spec.SyncPolicy.Automated = automated
+	_, err := argoClient.UpdateApplication(context.WithoutCancel(ctx), restored)
+	return err
+}
+
+// rollbackTarget finds the deployment of history that id names
+func rollbackTarget(history v1alpha1.RevisionHistories, id string) (v1alpha1.RevisionHistory, error) {
+	if id == RollbackPrevious {
+		if len(history) < 2 {
+			return v1alpha1.RevisionHistory{}, fmt.Errorf("no previous deployment to roll back to: the history has %d entries", len(history))
+		}
+		return history[len(history)-2], nil
+	}
+
+	n, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return v1alpha1.RevisionHistory{}, fmt.Errorf("invalid id %q: expected a history ID or %q", id, RollbackPrevious)
+	}
+	for _, deployment := range history {
+		if deployment.ID == n {
+			return deployment, nil
+		}
+	}
+	ids := make([]string, len(history))
+	for i, deployment := range history {
+		ids[i] = strconv.FormatInt(deployment.ID, 10)
+	}
+	return v1alpha1.RevisionHistory{}, fmt.Errorf("deployment %d is not in the history (available: %s)", n, strings.Join(ids, ", "))
+}
@@ -0,0 +1,211 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/gitops-engine/pkg/health"
+	synccommon "github.com/argoproj/gitops-engine/pkg/sync/common"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client/mock"
+	"go.uber.org/mock/gomock"
+)
+
+// historyApplication returns the guestbook application with three deployments
+func historyApplication(autoSync bool) *v1alpha1.Application {
+	app := testApplication(v1alpha1.SyncStatusCodeSynced, health.HealthStatusHealthy)
+	app.Status.History = v1alpha1.RevisionHistories{deployment(1, "aaa111", 10), deployment(2, "bbb222", 20), deployment(3, "ccc333", 30)}
+	if autoSync {
+		app.Spec.SyncPolicy = &v1alpha1.SyncPolicy{Automated: &v1alpha1.SyncPolicyAutomated{SelfHeal: true}}
+	}
+	return &app
+}
+
+func TestRollbackApplicationHandler(t *testing.T) {
+	tests := []struct {
+		name            string
+		id              string
+		autoSync        bool
+		disableAutoSync bool
+		wantID          int64
+		wantRevision    string
+	}{
+		{name: "previous", id: RollbackPrevious, wantID: 2, wantRevision: "bbb222"},
+		{name: "by id", id: "1", wantID: 1, wantRevision: "aaa111"},
+		{name: "auto-sync disabled first", id: "1", autoSync: true, disableAutoSync: true, wantID: 1, wantRevision: "aaa111"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			app := historyApplication(tt.autoSync)
+			mockClient := mock.NewMockInterface(ctrl)
+			mockClient.EXPECT().GetApplication(gomock.Any(), "guestbook").Return(app, nil)
+			var calls []string
+			if tt.autoSync {
+				mockClient.EXPECT().UpdateApplication(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(ctx context.Context, updated *v1alpha1.Application) (*v1alpha1.Application, error) {
+						calls = append(calls, "update")
+						assert.Nil(t, updated.Spec.SyncPolicy.Automated)
+						return updated, nil
+					})
+			}
+			mockClient.EXPECT().RollbackApplication(gomock.Any(), "guestbook", tt.wantID).DoAndReturn(
+				func(ctx context.Context, name string, id int64) (*v1alpha1.Application, error) {
+					calls = append(calls, "rollback")
+					return app, nil
+				})
+
+			result, err := rollbackApplicationHandler(context.Background(), mockClient, "guestbook", tt.id, tt.disableAutoSync, nil)
+			require.NoError(t, err)
+			require.False(t, result.IsError, result.Content)
+			outcome := decodeResult[RollbackOutcome](t, result)
+			assert.Equal(t, tt.wantID, outcome.ID)
+			assert.Equal(t, []string{tt.wantRevision}, outcome.Revisions)
+			assert.Equal(t, tt.autoSync, outcome.AutoSyncDisabled)
+			assert.Nil(t, outcome.Wait)
+			if tt.autoSync {
+				assert.Equal(t, []string{"update", "rollback"}, calls, "auto-sync is disabled before the rollback")
+				assert.Contains(t, outcome.Message, "stays off")
+			}
+		})
+	}
+}
+
+func TestRollbackApplicationHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name          string
+		id            string
+		app           *v1alpha1.Application
+		errorContains string
+	}{
+		{
+			name:          "auto-sync enabled",
+			id:            RollbackPrevious,
+			app:           historyApplication(true),
+			errorContains: "has automated sync enabled, which would undo the rollback; pass disable_auto_sync=true",
+		},
+		{
+			name:          "unknown id",
+			id:            "7",
+			app:           historyApplication(false),
+			errorContains: "deployment 7 is not in the history (available: 1, 2, 3)",
+		},
+		{
+			name:          "invalid id",
+			id:            "latest",
+			app:           historyApplication(false),
+			errorContains: `invalid id "latest"`,
+		},
+		{
+			name: "no previous deployment",
+			id:   RollbackPrevious,
+			app: func() *v1alpha1.Application {
+				app := historyApplication(false)
+				app.Status.History = app.Status.History[:1]
+				return app
+			}(),
+			errorContains: "no previous deployment",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// Nothing is changed when the rollback is refused
+			mockClient := mock.NewMockInterface(ctrl)
+			mockClient.EXPECT().GetApplication(gomock.Any(), "guestbook").Return(tt.app, nil)
+
+			result, err := rollbackApplicationHandler(context.Background(), mockClient, "guestbook", tt.id, false, nil)
+			require.NoError(t, err)
+			assert.True(t, result.IsError)
+			assert.Contains(t, result.Content[0].(mcp.TextContent).Text, tt.errorContains)
+		})
+	}
+}
+
+func TestRollbackApplicationHandler_RollbackFailsAfterDisablingAutoSync(t *testing.T) {
+	tests := []struct {
+		name          string
+		restoreErr    error
+		errorContains string
+	}{
+		{name: "auto-sync restored", errorContains: "Failed to rollback application: comparison error; automated sync was enabled again"},
+		{name: "restore fails", restoreErr: errors.New("permission denied"), errorContains: "automated sync was disabled and could not be enabled again: permission denied"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockClient := mock.NewMockInterface(ctrl)
+			mockClient.EXPECT().GetApplication(gomock.Any(), "guestbook").Return(historyApplication(true), nil)
+			gomock.InOrder(
+				mockClient.EXPECT().UpdateApplication(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(ctx context.Context, updated *v1alpha1.Application) (*v1alpha1.Application, error) {
+						assert.Nil(t, updated.Spec.SyncPolicy.Automated)
+						updated = updated.DeepCopy()
+						updated.ResourceVersion = "2"
+						return updated, nil
+					}),
+				mockClient.EXPECT().RollbackApplication(gomock.Any(), "guestbook", int64(2)).Return(nil, errors.New("comparison error")),
+				mockClient.EXPECT().UpdateApplication(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(ctx context.Context, updated *v1alpha1.Application) (*v1alpha1.Application, error) {
+						assert.Equal(t, &v1alpha1.SyncPolicyAutomated{SelfHeal: true}, updated.Spec.SyncPolicy.Automated)
+						assert.Equal(t, "2", updated.ResourceVersion)
+						return updated, tt.restoreErr
+					}),
+			)
+
+			result, err := rollbackApplicationHandler(context.Background(), mockClient, "guestbook", RollbackPrevious, true, nil)
+			require.NoError(t, err)
+			assert.True(t, result.IsError)
+			assert.Contains(t, result.Content[0].(mcp.TextContent).Text, tt.errorContains)
+		})
+	}
+}
+
+func TestRollbackApplicationHandler_Wait(t *testing.T) {
+	tests := []struct {
+		name       string
+		phase      synccommon.OperationPhase
+		wantError  bool
+		wantResult string
+	}{
+		{name: "rollback succeeds", phase: synccommon.OperationSucceeded, wantResult: WaitReached},
+		{name: "rollback fails", phase: synccommon.OperationFailed, wantError: true, wantResult: WaitFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			running := operatingApplication(synccommon.OperationRunning, v1alpha1.SyncStatusCodeOutOfSync, health.HealthStatusProgressing)
+			mockClient := mock.NewMockInterface(ctrl)
+			mockClient.EXPECT().GetApplication(gomock.Any(), "guestbook").Return(historyApplication(false), nil)
+			mockClient.EXPECT().RollbackApplication(gomock.Any(), "guestbook", int64(2)).Return(&running, nil)
+			// After a rollback the application stays out of sync; the operation decides the outcome
+			watchEvents(mockClient, operatingApplication(tt.phase, v1alpha1.SyncStatusCodeOutOfSync, health.HealthStatusHealthy))
+
+			wait := &syncWait{timeout: time.Second}
+			result, err := rollbackApplicationHandler(context.Background(), mockClient, "guestbook", RollbackPrevious, false, wait)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantError, result.IsError)
+			outcome := decodeResult[RollbackOutcome](t, result)
+			require.NotNil(t, outcome.Wait)
+			assert.Equal(t, tt.wantResult, outcome.Wait.Result)
+			assert.Equal(t, WaitUntilOperation, outcome.Wait.Condition)
+		})
+	}
+}
@@ -0,0 +1,290 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/logging"
+	"github.com/yosida95/uritemplate/v3"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// subscribableURI matches the application resources clients can subscribe to
+var subscribableURI = uritemplate.MustNew(ApplicationResourceURI + "{?" + resourceFormatArgument + "," + InstanceArgument + "}")
+
+// Delays between attempts to watch an application again after its client was replaced
+var (
+	rewatchRetryInitial = time.Second
+	rewatchRetryMax     = 30 * time.Second
+)
+
+// errNoSession is returned when a subscription request does not come from a client session
+var errNoSession = errors.New("subscriptions require a client session")
+
+// notifyFunc sends notifications/resources/updated for uri to a session
+type notifyFunc func(sessionID, uri string) error
+
+// subscriptions fans the changes of watched applications out to the sessions
+// subscribed to them. Each application is watched once, however many sessions
+// subscribe to it, and the watch stops when the last subscriber leaves.
+type subscriptions struct {
+	clients ClientProvider
+	notify  notifyFunc
+
+	mu       sync.Mutex
+	watchers map[watchKey]*appWatcher
+}
+
+// watchKey identifies a watched application
+type watchKey struct {
+	instance string
+	name     string
+}
+
+// subscriber is a session subscribed to a resource URI
+type subscriber struct {
+	session string
+	uri     string
+}
+
+// appWatcher follows one application and remembers the status last notified
+type appWatcher struct {
+	cancel      context.CancelFunc
+	subscribers map[subscriber]struct{}
+	state       appState
+}
+
+// appState is the part of an application whose changes are notified
+type appState struct {
+	sync   v1alpha1.SyncStatusCode
+	health string
+}
+
+func newAppState(app *v1alpha1.Application) appState {
+	return appState{sync: app.Status.Sync.Status, health: string(app.Status.Health.Status)}
+}
+
+func newSubscriptions(clients ClientProvider, notify notifyFunc) *subscriptions {
+	return &subscriptions{
+		clients:  clients,
+		notify:   notify,
+		watchers: make(map[watchKey]*appWatcher),
+	}
+}
+
+// Subscribe implements server.Subscriber for argocd://applications/{name} resources
+func (r *Registry) Subscribe(ctx context.Context, uri string) error {
+	if r.subscriptions == nil {
+		return fmt.Errorf("subscriptions are not enabled")
+	}
+	return r.subscriptions.subscribe(ctx, uri)
+}
+
+// Unsubscribe implements server.Subscriber
+func (r *Registry) Unsubscribe(ctx context.Context, uri string) error {
+	if r.subscriptions == nil {
+		return fmt.Errorf("subscriptions are not enabled")
+	}
+	return r.subscriptions.unsubscribe(ctx, uri)
+}
+
+// UnsubscribeSession implements server.Subscriber
+func (r *Registry) UnsubscribeSession(sessionID string) {
+	if r.subscriptions != nil {
+		r.subscriptions.unsubscribeSession(sessionID)
+	}
+}
+
+// parseSubscription returns the session and watched application of a subscription request
+func (s *subscriptions) parseSubscription(ctx context.Context, uri string) (subscriber, watchKey, error) {
+	session := server.ClientSessionFromContext(ctx)
+	if session == nil {
+		return subscriber{}, watchKey{}, errNoSession
+	}
+
+	values := subscribableURI.Match(uri)
+	name := values.Get("name").String()
+	if name == "" {
+		return subscriber{}, watchKey{}, fmt.Errorf("cannot subscribe to %s: only %s resources can be subscribed to", uri, ApplicationResourceURI)
+	}
+
+	instance := values.Get(InstanceArgument).String()
+	if instance == "" {
+		for _, i := range s.clients.Instances() {
+			if i.Default {
+				instance = i.Name
+			}
+		}
+	}
+	return subscriber{session: session.SessionID(), uri: uri}, watchKey{instance: instance, name: name}, nil
+}
+
+func (s *subscriptions) subscribe(ctx context.Context, uri string) error {
+	sub, key, err := s.parseSubscription(ctx, uri)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	if watcher, ok := s.watchers[key]; ok {
+		watcher.subscribers[sub] = struct{}{}
+		s.mu.Unlock()
+		return nil
+	}
+	s.mu.Unlock()
+
+	// Start watching outside the lock; the current status is the baseline for changes
+	watchCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	argoClient, err := s.clients.Client(watchCtx, key.instance)
+	if err != nil {
+		cancel()
+		return fmt.Errorf("failed to create gRPC client: %w", err)
+	}
+	app, err := argoClient.GetApplication(ctx, key.name)
+	if err != nil {
+		cancel()
+		_ = argoClient.Close()
+		return fmt.Errorf("failed to get application %s: %w", key.name, err)
+	}
+	events, err := argoClient.WatchApplications(watchCtx, key.name, app.ResourceVersion)
+	if err != nil {
+		cancel()
+		_ = argoClient.Close()
+		return fmt.Errorf("failed to watch application %s: %w", key.name, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if watcher, ok := s.watchers[key]; ok {
+		// Another session started the same watch meanwhile
+		cancel()
+		_ = argoClient.Close()
+		watcher.subscribers[sub] = struct{}{}
+		return nil
+	}
+	watcher := &appWatcher{
+		cancel:      cancel,
+		subscribers: map[subscriber]struct{}{sub: {}},
+		state:       newAppState(app),
+	}
+	s.watchers[key] = watcher
+	go s.run(watchCtx, key, watcher, argoClient, events, app.ResourceVersion)
+	return nil
+}
+
+func (s *subscriptions) unsubscribe(ctx context.Context, uri string) error {
+	sub, key, err := s.parseSubscription(ctx, uri)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if watcher, ok := s.watchers[key]; ok {
+		delete(watcher.subscribers, sub)
+		s.stopIfUnused(key, watcher)
+	}
+	return nil
+}
+
+func (s *subscriptions) unsubscribeSession(sessionID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, watcher := range s.watchers {
+		for sub := range watcher.subscribers {
+			if sub.session == sessionID {
+				delete(watcher.subscribers, sub)
+			}
+		}
+		s.stopIfUnused(key, watcher)
+	}
+}
+
+// stopIfUnused stops a watch without subscribers; s.mu must be held
+func (s *subscriptions) stopIfUnused(key watchKey, watcher *appWatcher) {
+	if len(watcher.subscribers) == 0 {
+		watcher.cancel()
+		delete(s.watchers, key)
+	}
+}
+
+// run notifies the subscribers of an application whenever its sync or health status changes
+func (s *subscriptions) run(ctx context.Context, key watchKey, watcher *appWatcher, argoClient client.Interface, events <-chan *v1alpha1.ApplicationWatchEvent, resourceVersion string) {
+	for {
+		for event := range events {
+			if event.Application.Name != key.name {
+				continue
+			}
+			resourceVersion = event.Application.ResourceVersion
+
+			s.mu.Lock()
+			state := newAppState(&event.Application)
+			changed := event.Type == watch.Deleted || state != watcher.state
+			watcher.state = state
+			var targets []subscriber
+			if changed {
+				for sub := range watcher.subscribers {
+					targets = append(targets, sub)
+				}
+			}
+			s.mu.Unlock()
+
+			for _, sub := range targets {
+				if err := s.notify(sub.session, sub.uri); err != nil {
+					logging.WithField("uri", sub.uri).WithError(err).Debug("Failed to notify subscriber")
+				}
+			}
+		}
+		_ = argoClient.Close()
+
+		// The watch ends with its last subscriber, or when its client was closed
+		// because the connection broke and was replaced; it then goes on with a new client
+		argoClient, events = s.rewatch(ctx, key, resourceVersion)
+		if events == nil {
+			return
+		}
+	}
+}
+
+// rewatch watches an application again with a client from the provider, retrying
+// with backoff until ctx is done. It returns a nil channel once ctx is done.
+func (s *subscriptions) rewatch(ctx context.Context, key watchKey, resourceVersion string) (client.Interface, <-chan *v1alpha1.ApplicationWatchEvent) {
+	delay := rewatchRetryInitial
+	for {
+		if ctx.Err() != nil {
+			return nil, nil
+		}
+		argoClient, err := s.clients.Client(ctx, key.instance)
+		if err == nil {
+			var events <-chan *v1alpha1.ApplicationWatchEvent
+			if events, err = argoClient.WatchApplications(ctx, key.name, resourceVersion); err == nil {
+				return argoClient, events
+			}
+			_ = argoClient.Close()
+		}
+		logging.WithField("application", key.name).WithError(err).Debug("Failed to watch application again")
+		// The version may have expired; the server sends the current state first without one
+		resourceVersion = ""
+
+		select {
+		case <-ctx.Done():
+			return nil, nil
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, rewatchRetryMax)
+	}
+}
+
+// notifyResourceUpdated returns a notifyFunc sending notifications through s
+func notifyResourceUpdated(s *server.MCPServer) notifyFunc {
+	return func(sessionID, uri string) error {
+		return s.SendNotificationToSpecificClient(sessionID, mcp.MethodNotificationResourceUpdated, map[string]any{"uri": uri})
+	}
+}
@@ -0,0 +1,194 @@
+package tools
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/gitops-engine/pkg/health"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client/mock"
+	"go.uber.org/mock/gomock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// fakeSession is an initialized client session that buffers its notifications
+type fakeSession struct {
+	id            string
+	notifications chan mcp.JSONRPCNotification
+}
+
+func newFakeSession(id string) *fakeSession {
+	return &fakeSession{id: id, notifications: make(chan mcp.JSONRPCNotification, 10)}
+}
+
+func (s *fakeSession) Initialize()       {}
+func (s *fakeSession) Initialized() bool { return true }
+func (s *fakeSession) NotificationChannel() chan<- mcp.JSONRPCNotification {
+	return s.notifications
+}
+func (s *fakeSession) SessionID() string { return s.id }
+
+// expectNotification waits for a resources/updated notification for uri
+func (s *fakeSession) expectNotification(t *testing.T, uri string) {
+	t.Helper()
+	select {
+	case notification := <-s.notifications:
+		assert.Equal(t, mcp.MethodNotificationResourceUpdated, notification.Method)
+		assert.Equal(t, uri, notification.Params.AdditionalFields["uri"])
+	case <-time.After(time.Second):
+		t.Fatalf("session %s was not notified about %s", s.id, uri)
+	}
+}
+
+func (s *fakeSession) expectNoNotification(t *testing.T) {
+	t.Helper()
+	select {
+	case notification := <-s.notifications:
+		t.Fatalf("session %s got unexpected notification %v", s.id, notification)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func testApplication(sync v1alpha1.SyncStatusCode, healthStatus health.HealthStatusCode) v1alpha1.Application {
+	return v1alpha1.Application{
+		ObjectMeta: metav1.ObjectMeta{Name: "guestbook", ResourceVersion: "1"},
+		Status: v1alpha1.ApplicationStatus{
+			Sync:   v1alpha1.SyncStatus{Status: sync},
+			Health: v1alpha1.HealthStatus{Status: healthStatus},
+		},
+	}
+}
+
+func TestRegistry_Subscriptions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	app := testApplication(v1alpha1.SyncStatusCodeSynced, health.HealthStatusHealthy)
+	events := make(chan *v1alpha1.ApplicationWatchEvent)
+	var watchCtx context.Context
+
+	mockClient := mock.NewMockInterface(ctrl)
+	mockClient.EXPECT().Close().Return(nil).AnyTimes()
+	mockClient.EXPECT().GetApplication(gomock.Any(), "guestbook").Return(&app, nil).AnyTimes()
+	// Both sessions share a single watch
+	mockClient.EXPECT().WatchApplications(gomock.Any(), "guestbook", "1").DoAndReturn(
+		func(ctx context.Context, name, resourceVersion string) (<-chan *v1alpha1.ApplicationWatchEvent, error) {
+			watchCtx = ctx
+			return events, nil
+		}).Times(1)
+
+	s := server.NewMCPServer("test", "1.0.0")
+	registry := newMockRegistry(mockClient)
+	registry.Register(s)
+
+	sessionA, sessionB := newFakeSession("a"), newFakeSession("b")
+	ctxA, ctxB := s.WithContext(context.Background(), sessionA), s.WithContext(context.Background(), sessionB)
+	require.NoError(t, s.RegisterSession(ctxA, sessionA))
+	require.NoError(t, s.RegisterSession(ctxB, sessionB))
+
+	const uri = "argocd://applications/guestbook"
+	const yamlURI = "argocd://applications/guestbook?format=yaml"
+	require.NoError(t, registry.Subscribe(ctxA, uri))
+	require.NoError(t, registry.Subscribe(ctxB, yamlURI))
+
+	// An unchanged status is not notified
+	events <- &v1alpha1.ApplicationWatchEvent{Type: watch.Modified, Application: app}
+	sessionA.expectNoNotification(t)
+
+	outOfSync := testApplication(v1alpha1.SyncStatusCodeOutOfSync, health.HealthStatusHealthy)
+	events <- &v1alpha1.ApplicationWatchEvent{Type: watch.Modified, Application: outOfSync}
+	sessionA.expectNotification(t, uri)
+	sessionB.expectNotification(t, yamlURI)
+
+	require.NoError(t, registry.Unsubscribe(ctxA, uri))
+	degraded := testApplication(v1alpha1.SyncStatusCodeOutOfSync, health.HealthStatusDegraded)
+	events <- &v1alpha1.ApplicationWatchEvent{Type: watch.Modified, Application: degraded}
+	sessionB.expectNotification(t, yamlURI)
+	sessionA.expectNoNotification(t)
+
+	// The watch stops with its last subscriber
+	registry.UnsubscribeSession("b")
+	select {
+	case <-watchCtx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("watch was not stopped")
+	}
+}
+
+func TestRegistry_SubscriptionsReplacedClient(t *testing.T) {
+	defer func(initial time.Duration) { rewatchRetryInitial = initial }(rewatchRetryInitial)
+	rewatchRetryInitial = time.Millisecond
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	app := testApplication(v1alpha1.SyncStatusCodeSynced, health.HealthStatusHealthy)
+	app.ResourceVersion = "1"
+	modified := testApplication(v1alpha1.SyncStatusCodeOutOfSync, health.HealthStatusHealthy)
+	modified.ResourceVersion = "5"
+	degraded := testApplication(v1alpha1.SyncStatusCodeOutOfSync, health.HealthStatusDegraded)
+	degraded.ResourceVersion = "6"
+
+	// The first client is closed partway through the watch, ending its events
+	oldEvents := make(chan *v1alpha1.ApplicationWatchEvent, 1)
+	oldClient := mock.NewMockInterface(ctrl)
+	oldClient.EXPECT().GetApplication(gomock.Any(), "guestbook").Return(&app, nil)
+	oldClient.EXPECT().WatchApplications(gomock.Any(), "guestbook", "1").Return(oldEvents, nil)
+	oldClosed := oldClient.EXPECT().Close().Return(nil)
+
+	// The watch goes on with the client the provider hands out now, from the last version seen
+	newEvents := make(chan *v1alpha1.ApplicationWatchEvent, 1)
+	newClient := mock.NewMockInterface(ctrl)
+	newClient.EXPECT().WatchApplications(gomock.Any(), "guestbook", "5").Return(newEvents, nil).After(oldClosed)
+	newClient.EXPECT().Close().Return(nil).AnyTimes()
+
+	clients := []client.Interface{oldClient, newClient}
+	registry := NewRegistry(ClientFactory(func(ctx context.Context) (client.Interface, error) {
+		c := clients[0]
+		if len(clients) > 1 {
+			clients = clients[1:]
+		}
+		return c, nil
+	}), Config{})
+	s := server.NewMCPServer("test", "1.0.0")
+	registry.Register(s)
+
+	session := newFakeSession("a")
+	ctx := s.WithContext(context.Background(), session)
+	require.NoError(t, s.RegisterSession(ctx, session))
+
+	const uri = "argocd://applications/guestbook"
+	require.NoError(t, registry.Subscribe(ctx, uri))
+
+	oldEvents <- &v1alpha1.ApplicationWatchEvent{Type: watch.Modified, Application: modified}
+	session.expectNotification(t, uri)
+	close(oldEvents)
+
+	newEvents <- &v1alpha1.ApplicationWatchEvent{Type: watch.Modified, Application: degraded}
+	session.expectNotification(t, uri)
+
+	registry.UnsubscribeSession("a")
+}
+
+func TestRegistry_SubscribeErrors(t *testing.T) {
+	s := server.NewMCPServer("test", "1.0.0")
+	registry := newMockRegistry(nil)
+	registry.Register(s)
+
+	session := newFakeSession("a")
+	ctx := s.WithContext(context.Background(), session)
+
+	assert.ErrorIs(t, registry.Subscribe(context.Background(), "argocd://applications/guestbook"), errNoSession)
+	assert.ErrorContains(t, registry.Subscribe(ctx, "argocd://projects/default"), "only argocd://applications/{name} resources")
+	assert.ErrorContains(t, registry.Subscribe(ctx, "argocd://applications/guestbook?instance=other"), "unknown")
+
+	unregistered := newMockRegistry(nil)
+	assert.Error(t, unregistered.Subscribe(ctx, "argocd://applications/guestbook"))
+}
@@ -4,15 +4,25 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client"
 )
 
+// Sync strategies of sync_application
+const (
+	SyncStrategyHook  = "hook"
+	SyncStrategyApply = "apply"
+)
+
 // SyncAppTool defines the sync_application tool schema
 var SyncAppTool = mcp.NewTool("sync_application",
-	mcp.WithDescription("Triggers a sync operation for a specific ArgoCD application."),
+	mcp.WithDescription("Triggers a sync operation for a specific ArgoCD application, optionally limited to some of its resources or to an explicit revision."),
 	mcp.WithDestructiveHintAnnotation(true),
 	mcp.WithString("name",
 		mcp.Required(),
@@ -24,33 +34,250 @@ var SyncAppTool = mcp.NewTool("sync_application",
 	mcp.WithBoolean("dry_run",
 		mcp.Description("Preview the sync operation without making actual changes (default: false)."),
 	),
+	mcp.WithString("resources",
+		mcp.Description("Optional. Comma-separated list of resources to sync in format '[group]:kind:[namespace/]name' (e.g., 'apps:Deployment:default/guestbook-ui,:Service:guestbook-ui'). Default: all resources."),
+	),
+	mcp.WithString("revision",
+		mcp.Description("Optional. Revision to sync to instead of the target revision of a single-source application."),
+	),
+	mcp.WithString("revisions",
+		mcp.Description("Optional. Comma-separated revisions to sync the sources of a multi-source application to, one per source position."),
+	),
+	mcp.WithString("source_positions",
+		mcp.Description("Optional. Comma-separated source positions, counting from 1, that the revisions apply to. Default: 1, 2, ... for each revision."),
+	),
+	mcp.WithString("sync_options",
+		mcp.Description("Optional. Comma-separated sync options (e.g., 'ServerSideApply=true,CreateNamespace=true,Replace=true')."),
+	),
+	mcp.WithString("strategy",
+		mcp.Description("Optional. 'hook' (default) runs sync hooks; 'apply' only applies the manifests, skipping hooks."),
+		mcp.Enum(SyncStrategyHook, SyncStrategyApply),
+	),
+	mcp.WithBoolean("force",
+		mcp.Description("Delete and recreate resources that cannot be patched (kubectl apply --force) (default: false)."),
+	),
+	mcp.WithNumber("retry_limit",
+		mcp.Description("Optional. Retry a failed sync up to this many times; -1 retries without limit. Default: no retry."),
+	),
+	mcp.WithString("retry_backoff_duration",
+		mcp.Description("Optional. Delay before the first retry, e.g. '5s' (default: Argo CD's default)."),
+	),
+	mcp.WithNumber("retry_backoff_factor",
+		mcp.Description("Optional. Factor the delay is multiplied by after each retry (default: Argo CD's default)."),
+	),
+	mcp.WithString("retry_backoff_max_duration",
+		mcp.Description("Optional. Maximum delay between retries, e.g. '3m' (default: Argo CD's default)."),
+	),
+	mcp.WithBoolean("wait",
+		mcp.Description("Wait until the application is synced and healthy and return a summary of the outcome instead of the application (default: false). A dry run, a sync of some resources or a sync to an explicit revision is only waited for until its operation finishes, as the application may stay out of sync."),
+	),
+	mcp.WithNumber("wait_timeout_seconds",
+		mcp.Description(fmt.Sprintf("Optional. How long to wait, in seconds (default: %d, max: %d).", int(DefaultWaitTimeout.Seconds()), int(MaxWaitTimeout.Seconds()))),
+	),
+	mcp.WithString(ConfirmTokenArgument,
+		mcp.Description(confirmTokenDescription),
+	),
 )
 
 // HandleSyncApplication processes sync_application tool requests
-func HandleSyncApplication(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func (r *Registry) HandleSyncApplication(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	// Extract parameters from mcp.CallToolRequest
 	appName := request.GetString("name", "")
-	prune := request.GetBool("prune", false)
-	dryRun := request.GetBool("dry_run", false)
-
-	// Create gRPC client
-	config := &client.Config{
-		ServerAddr:      os.Getenv("ARGOCD_SERVER"),
-		AuthToken:       os.Getenv("ARGOCD_AUTH_TOKEN"),
-		Insecure:        os.Getenv("ARGOCD_INSECURE") == "true",
-		PlainText:       os.Getenv("ARGOCD_PLAINTEXT") == "true",
-		GRPCWeb:         os.Getenv("ARGOCD_GRPC_WEB") == "true",
-		GRPCWebRootPath: os.Getenv("ARGOCD_GRPC_WEB_ROOT_PATH"),
+	sync, err := parseSyncRequest(request)
+	if err != nil {
+		return invalidArgument(ctx, err.Error()), nil
+	}
+	var wait *syncWait
+	if request.GetBool("wait", false) {
+		timeout, err := waitTimeout(request, "wait_timeout_seconds")
+		if err != nil {
+			return invalidArgument(ctx, err.Error()), nil
+		}
+		wait = &syncWait{timeout: timeout, progress: newProgressReporter(ctx, request)}
 	}
 
-	argoClient, err := client.New(config)
+	// Get the gRPC client for this call
+	argoClient, err := r.client(ctx, request)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to create gRPC client: %v", err)), nil
 	}
 	defer func() { _ = argoClient.Close() }()
 
+	// A pruning sync deletes resources, so it needs confirmation and approval unless it is a dry run
+	if appName != "" && sync.Prune && !sync.DryRun {
+		action := syncAction(appName, sync)
+		plan := func() (*ConfirmationPlan, error) {
+			return planPrune(ctx, argoClient, appName, sync.Resources)
+		}
+		if result := r.confirm(ctx, request, action, plan); result != nil {
+			return result, nil
+		}
+		if result := r.approve(ctx, request, action, plan); result != nil {
+			return result, nil
+		}
+	}
+
 	// Use the handler function with the real client
-	return syncApplicationHandler(ctx, argoClient, appName, prune, dryRun)
+	return syncApplicationHandler(ctx, argoClient, appName, sync, wait)
+}
+
+// syncAction describes a pruning sync canonically, so that a confirmation token
+// only confirms a sync with the same selection and options as the plan it was issued with
+func syncAction(appName string, sync client.SyncRequest) string {
+	resources := make([]string, len(sync.Resources))
+	for i, res := range sync.Resources {
+		resources[i] = res.Group + ":" + res.Kind + ":" + res.Namespace + "/" + res.Name
+	}
+	slices.Sort(resources)
+	revisions := make([]string, len(sync.Revisions))
+	for i, revision := range sync.Revisions {
+		revisions[i] = fmt.Sprintf("%d=%s", sync.SourcePositions[i], revision)
+	}
+	options := slices.Sorted(slices.Values(sync.SyncOptions))
+	strategy := SyncStrategyHook
+	if sync.Strategy != nil && sync.Strategy.Apply != nil {
+		strategy = SyncStrategyApply
+	}
+
+	action := fmt.Sprintf("sync application %s with prune", appName)
+	if len(resources) > 0 {
+		action += " of resources " + strings.Join(resources, ",")
+	} else {
+		action += " of all resources"
+	}
+	return action + fmt.Sprintf(" (revision=%q revisions=%q dry_run=%t sync_options=%q strategy=%s force=%t)",
+		sync.Revision, strings.Join(revisions, ","), sync.DryRun, strings.Join(options, ","), strategy, sync.Strategy.Force())
+}
+
+// parseSyncRequest reads the sync options of a sync_application request
+func parseSyncRequest(request mcp.CallToolRequest) (client.SyncRequest, error) {
+	// A plain sync is the zero SyncRequest
+	sync := client.SyncRequest{
+		Revision: request.GetString("revision", ""),
+		Prune:    request.GetBool("prune", false),
+		DryRun:   request.GetBool("dry_run", false),
+	}
+	if revisions := parseCommaSeparated(request.GetString("revisions", "")); len(revisions) > 0 {
+		sync.Revisions = revisions
+	}
+	if options := parseCommaSeparated(request.GetString("sync_options", "")); len(options) > 0 {
+		sync.SyncOptions = options
+	}
+
+	if sync.Revision != "" && len(sync.Revisions) > 0 {
+		return sync, fmt.Errorf("revision and revisions cannot be used together")
+	}
+	positions := parseCommaSeparated(request.GetString("source_positions", ""))
+	if len(positions) > 0 && len(positions) != len(sync.Revisions) {
+		return sync, fmt.Errorf("source_positions must have one position for each of the %d revisions", len(sync.Revisions))
+	}
+	for i := range sync.Revisions {
+		position := int64(i + 1)
+		if len(positions) > 0 {
+			var err error
+			position, err = strconv.ParseInt(positions[i], 10, 64)
+			if err != nil || position < 1 {
+				return sync, fmt.Errorf("invalid source position %q: positions count from 1", positions[i])
+			}
+		}
+		sync.SourcePositions = append(sync.SourcePositions, position)
+	}
+
+	for _, resource := range parseCommaSeparated(request.GetString("resources", "")) {
+		parsed, err := parseSyncResource(resource)
+		if err != nil {
+			return sync, err
+		}
+		sync.Resources = append(sync.Resources, parsed)
+	}
+	for _, option := range sync.SyncOptions {
+		if key, value, ok := strings.Cut(option, "="); !ok || key == "" || value == "" {
+			return sync, fmt.Errorf("invalid sync option %q: expected 'Key=value'", option)
+		}
+	}
+
+	force := request.GetBool("force", false)
+	switch strategy := request.GetString("strategy", ""); strategy {
+	case SyncStrategyApply:
+		sync.Strategy = &v1alpha1.SyncStrategy{Apply: &v1alpha1.SyncStrategyApply{Force: force}}
+	case SyncStrategyHook, "":
+		if strategy != "" || force {
+			sync.Strategy = &v1alpha1.SyncStrategy{Hook: &v1alpha1.SyncStrategyHook{SyncStrategyApply: v1alpha1.SyncStrategyApply{Force: force}}}
+		}
+	default:
+		return sync, fmt.Errorf("invalid strategy %q: expected %q or %q", strategy, SyncStrategyHook, SyncStrategyApply)
+	}
+
+	retry, err := parseRetryStrategy(request)
+	if err != nil {
+		return sync, err
+	}
+	sync.Retry = retry
+	return sync, nil
+}
+
+// parseSyncResource parses a resource in format [group]:kind:[namespace/]name
+func parseSyncResource(resource string) (v1alpha1.SyncOperationResource, error) {
+	parts := strings.Split(resource, ":")
+	if len(parts) != 3 || parts[1] == "" || parts[2] == "" {
+		return v1alpha1.SyncOperationResource{}, fmt.Errorf("invalid resource %q: expected '[group]:kind:[namespace/]name'", resource)
+	}
+	parsed := v1alpha1.SyncOperationResource{Group: parts[0], Kind: parts[1], Name: parts[2]}
+	if namespace, name, ok := strings.Cut(parts[2], "/"); ok {
+		parsed.Namespace, parsed.Name = namespace, name
+	}
+	if parsed.Name == "" {
+		return v1alpha1.SyncOperationResource{}, fmt.Errorf("invalid resource %q: the name is empty", resource)
+	}
+	return parsed, nil
+}
+
+// parseRetryStrategy reads the retry policy of a sync_application request, which is nil without retry_limit
+func parseRetryStrategy(request mcp.CallToolRequest) (*v1alpha1.RetryStrategy, error) {
+	limit := request.GetInt("retry_limit", 0)
+	backoff := v1alpha1.Backoff{
+		Duration:    request.GetString("retry_backoff_duration", ""),
+		MaxDuration: request.GetString("retry_backoff_max_duration", ""),
+	}
+	if factor := request.GetInt("retry_backoff_factor", 0); factor != 0 {
+		if factor < 1 {
+			return nil, fmt.Errorf("retry_backoff_factor must be at least 1")
+		}
+		factor64 := int64(factor)
+		backoff.Factor = &factor64
+	}
+	for _, duration := range []string{backoff.Duration, backoff.MaxDuration} {
+		if duration == "" {
+			continue
+		}
+		// Like Argo CD, durations without a unit are seconds
+		if _, err := strconv.Atoi(duration); err != nil {
+			if _, err := time.ParseDuration(duration); err != nil {
+				return nil, fmt.Errorf("invalid retry backoff duration %q: %v", duration, err)
+			}
+		}
+	}
+
+	if limit == 0 {
+		if backoff != (v1alpha1.Backoff{}) {
+			return nil, fmt.Errorf("retry backoff options require retry_limit")
+		}
+		return nil, nil
+	}
+	if limit < -1 {
+		return nil, fmt.Errorf("retry_limit must be -1 or more")
+	}
+	retry := &v1alpha1.RetryStrategy{Limit: int64(limit)}
+	if backoff != (v1alpha1.Backoff{}) {
+		retry.Backoff = &backoff
+	}
+	return retry, nil
+}
+
+// syncWait holds how long a sync waits for its outcome
+type syncWait struct {
+	timeout  time.Duration
+	progress *progressReporter
 }
 
 // syncApplicationHandler handles the core logic for syncing an application.
@@ -59,19 +286,28 @@ func syncApplicationHandler(
 	ctx context.Context,
 	argoClient client.Interface,
 	appName string,
-	prune bool,
-	dryRun bool,
+	sync client.SyncRequest,
+	wait *syncWait,
 ) (*mcp.CallToolResult, error) {
 	if appName == "" {
-		return mcp.NewToolResultError("Application name is required"), nil
+		return invalidArgument(ctx, "Application name is required"), nil
 	}
 
-	// Sync with empty revision to use latest
-	app, err := argoClient.SyncApplication(ctx, appName, "", prune, dryRun)
+	app, err := argoClient.SyncApplication(ctx, appName, sync)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to sync application: %v", err)), nil
 	}
 
+	if wait != nil {
+		// A dry run changes nothing, and the application stays out of sync after
+		// a partial sync or a sync to another revision, so only the operation is waited for
+		until := WaitUntilSyncedAndHealthy
+		if sync.DryRun || len(sync.Resources) > 0 || sync.Revision != "" || len(sync.Revisions) > 0 {
+			until = WaitUntilOperation
+		}
+		return waitResult(waitForApplication(ctx, argoClient, app, until, wait.timeout, wait.progress))
+	}
+
 	// Convert to JSON for better readability in MCP responses
 	jsonData, err := json.MarshalIndent(app, "", "  ")
 	if err != nil {
@@ -3,8 +3,17 @@ package tools
 import (
 	"context"
 	"testing"
+	"time"
 
+	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/gitops-engine/pkg/health"
+	synccommon "github.com/argoproj/gitops-engine/pkg/sync/common"
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client/mock"
+	"go.uber.org/mock/gomock"
 )
 
 func TestHandleSyncApplication(t *testing.T) {
@@ -89,13 +98,11 @@ func TestHandleSyncApplication(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Set environment variables
-			for k, v := range tt.envVars {
-				t.Setenv(k, v)
-			}
+			// Build a registry configured from the test settings
+			registry := newSettingsRegistry(tt.envVars)
 
 			// Execute handler
-			result, err := HandleSyncApplication(context.Background(), tt.request)
+			result, err := registry.HandleSyncApplication(context.Background(), tt.request)
 
 			// Check error expectation
 			if tt.wantError {
@@ -151,3 +158,105 @@ func TestSyncAppTool_Schema(t *testing.T) {
 		t.Error("Tool should have required fields defined (name should be required)")
 	}
 }
+
+func TestParseSyncRequest(t *testing.T) {
+	factor := int64(3)
+	tests := []struct {
+		name          string
+		args          map[string]interface{}
+		want          client.SyncRequest
+		errorContains string
+	}{
+		{
+			name: "plain sync",
+			args: map[string]interface{}{"name": "guestbook"},
+			want: client.SyncRequest{},
+		},
+		{
+			name: "resources, revision and options",
+			args: map[string]interface{}{
+				"resources":    "apps:Deployment:default/guestbook-ui, :Service:guestbook-ui",
+				"revision":     "v1.2.0",
+				"sync_options": "ServerSideApply=true,CreateNamespace=true",
+				"prune":        true,
+			},
+			want: client.SyncRequest{
+				Revision: "v1.2.0",
+				Prune:    true,
+				Resources: []v1alpha1.SyncOperationResource{
+					{Group: "apps", Kind: "Deployment", Namespace: "default", Name: "guestbook-ui"},
+					{Kind: "Service", Name: "guestbook-ui"},
+				},
+				SyncOptions: []string{"ServerSideApply=true", "CreateNamespace=true"},
+			},
+		},
+		{
+			name: "revisions of a multi-source application",
+			args: map[string]interface{}{"revisions": "main,18.0.1"},
+			want: client.SyncRequest{Revisions: []string{"main", "18.0.1"}, SourcePositions: []int64{1, 2}},
+		},
+		{
+			name: "revisions at source positions",
+			args: map[string]interface{}{"revisions": "18.0.1", "source_positions": "2"},
+			want: client.SyncRequest{Revisions: []string{"18.0.1"}, SourcePositions: []int64{2}},
+		},
+		{
+			name: "forced apply with retry",
+			args: map[string]interface{}{
+				"strategy": "apply", "force": true,
+				"retry_limit": float64(5), "retry_backoff_duration": "10s", "retry_backoff_factor": float64(3), "retry_backoff_max_duration": "5m",
+			},
+			want: client.SyncRequest{
+				Strategy: &v1alpha1.SyncStrategy{Apply: &v1alpha1.SyncStrategyApply{Force: true}},
+				Retry:    &v1alpha1.RetryStrategy{Limit: 5, Backoff: &v1alpha1.Backoff{Duration: "10s", Factor: &factor, MaxDuration: "5m"}},
+			},
+		},
+		{
+			name: "forced hook sync",
+			args: map[string]interface{}{"force": true},
+			want: client.SyncRequest{
+				Strategy: &v1alpha1.SyncStrategy{Hook: &v1alpha1.SyncStrategyHook{SyncStrategyApply: v1alpha1.SyncStrategyApply{Force: true}}},
+			},
+		},
+		{name: "revision and revisions", args: map[string]interface{}{"revision": "main", "revisions": "main"}, errorContains: "cannot be used together"},
+		{name: "positions without revisions", args: map[string]interface{}{"revisions": "a,b", "source_positions": "1"}, errorContains: "one position for each of the 2 revisions"},
+		{name: "invalid position", args: map[string]interface{}{"revisions": "a", "source_positions": "0"}, errorContains: `invalid source position "0"`},
+		{name: "invalid resource", args: map[string]interface{}{"resources": "Deployment/guestbook"}, errorContains: "expected '[group]:kind:[namespace/]name'"},
+		{name: "invalid sync option", args: map[string]interface{}{"sync_options": "ServerSideApply"}, errorContains: `invalid sync option "ServerSideApply"`},
+		{name: "invalid strategy", args: map[string]interface{}{"strategy": "replace"}, errorContains: `invalid strategy "replace"`},
+		{name: "backoff without limit", args: map[string]interface{}{"retry_backoff_duration": "5s"}, errorContains: "require retry_limit"},
+		{name: "invalid backoff", args: map[string]interface{}{"retry_limit": float64(2), "retry_backoff_duration": "soon"}, errorContains: `invalid retry backoff duration "soon"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sync, err := parseSyncRequest(mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "sync_application", Arguments: tt.args}})
+			if tt.errorContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, sync)
+		})
+	}
+}
+
+func TestSyncApplicationHandler_SelectedResources(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sync := client.SyncRequest{Resources: []v1alpha1.SyncOperationResource{{Group: "apps", Kind: "Deployment", Name: "guestbook-ui"}}}
+	started := operatingApplication(synccommon.OperationRunning, v1alpha1.SyncStatusCodeOutOfSync, health.HealthStatusHealthy)
+	mockClient := mock.NewMockInterface(ctrl)
+	mockClient.EXPECT().SyncApplication(gomock.Any(), "guestbook", sync).Return(&started, nil)
+	// Other resources may stay out of sync, so only the operation is waited for
+	watchEvents(mockClient, operatingApplication(synccommon.OperationSucceeded, v1alpha1.SyncStatusCodeOutOfSync, health.HealthStatusHealthy))
+
+	result, err := syncApplicationHandler(context.Background(), mockClient, "guestbook", sync, &syncWait{timeout: 5 * time.Second})
+	require.NoError(t, err)
+	require.False(t, result.IsError, result.Content)
+	outcome := decodeResult[WaitOutcome](t, result)
+	assert.Equal(t, WaitReached, outcome.Result)
+	assert.Equal(t, WaitUntilOperation, outcome.Condition)
+}
@@ -1,77 +1,198 @@
 package tools
 
 import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/audit"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/metrics"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/policy"
 )
 
-// RegisterAll registers all defined tools with the MCP server
-func RegisterAll(s *server.MCPServer) {
-	// Register list_application tool
-	s.AddTool(ListAppsTool, HandleListApplications)
-
-	// Register get_application tool
-	s.AddTool(GetAppTool, HandleGetApplication)
-
-	// Register get_application_manifests tool
-	s.AddTool(GetAppManifestsTool, HandleGetApplicationManifests)
-
-	// Register get_application_events tool
-	s.AddTool(GetAppEventsTool, HandleGetApplicationEvents)
-
-	// Register get_application_logs tool
-	s.AddTool(GetApplicationLogsToolDefinition, HandleGetApplicationLogs)
+// DefaultInstanceName is the instance name of a single-instance ClientFactory
+const DefaultInstanceName = "default"
 
-	// Register get_application_resource_tree tool
-	s.AddTool(GetApplicationResourceTreeTool, HandleGetApplicationResourceTree)
+// DefaultLogTailLines is the number of log lines returned when tail_lines is not specified
+const DefaultLogTailLines = 100
 
-	// Register create_application tool
-	s.AddTool(CreateAppTool, HandleCreateApplication)
-
-	// Register sync_application tool
-	s.AddTool(SyncAppTool, HandleSyncApplication)
+// ClientProvider hands out ArgoCD clients for the configured instances.
+// Handlers always Close the returned client, so providers handing out a shared
+// connection should return a client whose Close is a no-op (see client.Manager).
+type ClientProvider interface {
+	// Client returns the client of the named instance; an empty name selects the default instance
+	Client(ctx context.Context, instance string) (client.Interface, error)
+	// Instances returns the configured instances
+	Instances() []client.Instance
+}
 
-	// Register refresh_application tool
-	s.AddTool(RefreshAppTool, HandleRefreshApplication)
+// ClientFactory returns the ArgoCD client used to serve a single tool call.
+// It is a ClientProvider for a single instance named DefaultInstanceName.
+type ClientFactory func(ctx context.Context) (client.Interface, error)
 
-	// Register delete_application tool
-	s.AddTool(DeleteAppTool, HandleDeleteApplication)
+// Client returns a client from the factory; only the default instance is known
+func (f ClientFactory) Client(ctx context.Context, instance string) (client.Interface, error) {
+	if instance != "" && instance != DefaultInstanceName {
+		return nil, fmt.Errorf("%w: %q", client.ErrUnknownInstance, instance)
+	}
+	return f(ctx)
+}
 
-	// Register terminate_operation tool
-	s.AddTool(TerminateOperationTool, HandleTerminateOperation)
+// Instances returns the single default instance
+func (f ClientFactory) Instances() []client.Instance {
+	return []client.Instance{{Name: DefaultInstanceName, Default: true}}
+}
 
-	// Register list_project tool
-	s.AddTool(ListProjectsTool, HandleListProjects)
+// Config holds settings that shape tool behavior
+type Config struct {
+	// DefaultLogTailLines is used by get_application_logs when tail_lines is not given
+	DefaultLogTailLines int
+	// MaxResponseBytes is the size budget of list and large-output tools when max_bytes
+	// is not given (default DefaultMaxResponseBytes)
+	MaxResponseBytes int
+	// ReadOnly leaves out tools annotated as destructive
+	ReadOnly bool
+	// AllowTools, when not empty, restricts the tools to the named tools and toolsets
+	AllowTools []string
+	// DenyTools leaves out the named tools and toolsets; it wins over AllowTools
+	DenyTools []string
+	// ConfirmDestructive makes deletes and pruning syncs return a plan and a token
+	// that must be passed back before the action runs
+	ConfirmDestructive bool
+	// ConfirmTTL is how long a confirmation token stays valid (default DefaultConfirmTTL)
+	ConfirmTTL time.Duration
+	// ElicitDestructive asks the user to approve cascading deletes and pruning syncs through MCP elicitation
+	ElicitDestructive bool
+	// ElicitationFallback applies when the client does not support elicitation (default deny)
+	ElicitationFallback ElicitationFallback
+}
 
-	// Register get_project tool
-	s.AddTool(GetProjectTool, HandleGetProject)
+// Middleware wraps the handler of a tool. It receives the tool definition so that
+// it can act on the tool's name and annotations.
+type Middleware func(tool mcp.Tool, next server.ToolHandlerFunc) server.ToolHandlerFunc
 
-	// Register create_project tool
-	s.AddTool(CreateProjectTool, HandleCreateProject)
+// Option configures a Registry
+type Option func(*Registry)
 
-	// Register list_cluster tool
-	s.AddTool(ListClusterTool, HandleListCluster)
+// WithMiddleware wraps every registered tool handler with the given middleware.
+// Middlewares are applied in order, so the first one is the outermost.
+func WithMiddleware(mw Middleware) Option {
+	return func(r *Registry) {
+		r.middlewares = append(r.middlewares, mw)
+	}
+}
 
-	// Register get_cluster tool
-	s.AddTool(GetClusterTool, HandleGetCluster)
+// Registry holds the tool handlers and the dependencies they need
+type Registry struct {
+	clients     ClientProvider
+	config      Config
+	middlewares []Middleware
+	policy      *policy.Engine
+	audit       *audit.Logger
+	tracing     bool
+
+	confirmations *confirmations
+	auditUsers    auditUsers
+	subscriptions *subscriptions
+	completions   *completions
+}
 
-	// Register list_applicationset tool
-	s.AddTool(ListApplicationSetTool, HandleListApplicationSets)
+// NewRegistry creates a Registry whose handlers obtain their ArgoCD clients from clients
+func NewRegistry(clients ClientProvider, config Config, opts ...Option) *Registry {
+	if config.DefaultLogTailLines <= 0 {
+		config.DefaultLogTailLines = DefaultLogTailLines
+	}
+	if config.MaxResponseBytes <= 0 {
+		config.MaxResponseBytes = DefaultMaxResponseBytes
+	}
+
+	r := &Registry{
+		clients: clients,
+		config:  config,
+	}
+	if config.ConfirmDestructive {
+		r.confirmations = newConfirmations(config.ConfirmTTL)
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	if r.tracing {
+		r.clients = tracedProvider{r.clients}
+	}
+	r.completions = newCompletions(r.clients)
+	return r
+}
 
-	// Register get_applicationset tool
-	s.AddTool(GetApplicationSetTool, HandleGetApplicationSet)
+// client returns the ArgoCD client of the instance selected by the tool call
+func (r *Registry) client(ctx context.Context, request mcp.CallToolRequest) (client.Interface, error) {
+	argoClient, err := r.clients.Client(ctx, request.GetString(InstanceArgument, ""))
+	if err != nil {
+		metrics.SetErrorType(ctx, metrics.ErrorTypeConnection)
+	}
+	return argoClient, err
+}
 
-	// Register create_applicationset tool
-	s.AddTool(CreateApplicationSetTool, HandleCreateApplicationSet)
+// toolError returns an error result and reports its type to the metrics
+func toolError(ctx context.Context, errorType, message string) *mcp.CallToolResult {
+	metrics.SetErrorType(ctx, errorType)
+	return mcp.NewToolResultError(message)
+}
 
-	// Register delete_applicationset tool
-	s.AddTool(DeleteApplicationSetTool, HandleDeleteApplicationSet)
+// invalidArgument returns the error result of a call with invalid arguments
+func invalidArgument(ctx context.Context, message string) *mcp.CallToolResult {
+	return toolError(ctx, metrics.ErrorTypeInvalid, message)
+}
 
-	// Register list_repository tool
-	s.AddTool(ListRepositoryTool, HandleListRepository)
+// Tools returns the enabled tool definitions paired with their handlers
+func (r *Registry) Tools() []server.ServerTool {
+	var tools []server.ServerTool
+	multiInstance := len(r.clients.Instances()) > 1
+	for _, set := range r.toolsets() {
+		for _, tool := range set.tools {
+			if !r.config.enabled(set.name, tool.Tool) {
+				continue
+			}
+			if multiInstance && tool.Tool.Name != ListInstancesTool.Name {
+				tool.Tool = r.withInstanceArgument(tool.Tool)
+			}
+			// Only read tools shape their output; the results of actions are left as they are.
+			// Paginated tools render their pages themselves, so that max_bytes applies to the rendered output
+			handler := tool.Handler
+			if !isDestructive(tool.Tool) {
+				tool.Tool = withOutputArguments(tool.Tool)
+				if _, paginated := tool.Tool.InputSchema.Properties["cursor"]; !paginated {
+					handler = formatted(handler)
+				}
+			}
+			// Audit outside the policy check so that denied calls are recorded too
+			handler = r.auditCall(tool.Tool, r.enforcePolicy(set.name, tool.Tool, handler))
+			tool.Handler = r.traceCall(tool.Tool, r.wrap(tool.Tool, handler))
+			tools = append(tools, tool)
+		}
+	}
+	return tools
+}
 
-	// Register get_repository tool
-	s.AddTool(GetRepositoryTool, HandleGetRepository)
+// Register adds all tools, resource templates and prompts to the MCP server.
+// Subscriptions to application resources are notified through the same server.
+func (r *Registry) Register(s *server.MCPServer) {
+	s.AddTools(r.Tools()...)
+	s.AddResourceTemplates(r.ResourceTemplates()...)
+	if prompts := r.Prompts(); len(prompts) > 0 {
+		s.AddPrompts(prompts...)
+	}
+	if r.config.enabled(ToolsetApplications, GetAppTool) {
+		r.subscriptions = newSubscriptions(r.clients, notifyResourceUpdated(s))
+	}
+}
 
-	// Register get_user_info tool
-	s.AddTool(GetUserInfoTool, HandleGetUserInfo)
+// wrap applies the registry middlewares to a tool handler
+func (r *Registry) wrap(tool mcp.Tool, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	for i := len(r.middlewares) - 1; i >= 0; i-- {
+		handler = r.middlewares[i](tool, handler)
+	}
+	return handler
 }
@@ -0,0 +1,165 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client/mock"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/metrics"
+	"go.uber.org/mock/gomock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// newSettingsRegistry builds a Registry whose clients are configured from the given
+// ARGOCD_SERVER and ARGOCD_AUTH_TOKEN settings without touching the process environment
+func newSettingsRegistry(settings map[string]string) *Registry {
+	return NewRegistry(ClientFactory(func(ctx context.Context) (client.Interface, error) {
+		return client.New(&client.Config{
+			ServerAddr: settings["ARGOCD_SERVER"],
+			AuthToken:  settings["ARGOCD_AUTH_TOKEN"],
+		})
+	}), Config{})
+}
+
+// newMockRegistry builds a Registry whose handlers all use the given client
+func newMockRegistry(argoClient client.Interface, opts ...Option) *Registry {
+	return NewRegistry(ClientFactory(func(ctx context.Context) (client.Interface, error) {
+		return argoClient, nil
+	}), Config{}, opts...)
+}
+
+// decodeResult decodes the JSON of a tool result. Error results carry it after
+// their first line, the error message.
+func decodeResult[T any](t *testing.T, result *mcp.CallToolResult) T {
+	t.Helper()
+	require.NotNil(t, result)
+	text := result.Content[0].(mcp.TextContent).Text
+	if result.IsError {
+		var found bool
+		_, text, found = strings.Cut(text, "\n")
+		require.True(t, found, "error result without a summary: %s", result.Content[0].(mcp.TextContent).Text)
+	}
+	var decoded T
+	require.NoError(t, json.Unmarshal([]byte(text), &decoded), text)
+	return decoded
+}
+
+func TestRegistry_Tools(t *testing.T) {
+	registry := newMockRegistry(nil)
+
+	tools := registry.Tools()
+	require.NotEmpty(t, tools)
+
+	seen := make(map[string]bool)
+	for _, tool := range tools {
+		assert.NotEmpty(t, tool.Tool.Name)
+		assert.NotNil(t, tool.Handler, "tool %s has no handler", tool.Tool.Name)
+		assert.False(t, seen[tool.Tool.Name], "tool %s registered twice", tool.Tool.Name)
+		seen[tool.Tool.Name] = true
+	}
+	assert.True(t, seen["get_application"])
+	assert.True(t, seen["delete_application"])
+}
+
+func TestRegistry_Register(t *testing.T) {
+	s := server.NewMCPServer("test", "1.0.0")
+	registry := newMockRegistry(nil)
+	registry.Register(s)
+
+	response := s.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+	rpcResponse, ok := response.(mcp.JSONRPCResponse)
+	require.True(t, ok, "unexpected response %T", response)
+	listResult, ok := rpcResponse.Result.(mcp.ListToolsResult)
+	require.True(t, ok, "unexpected result %T", rpcResponse.Result)
+	assert.Len(t, listResult.Tools, len(registry.Tools()))
+}
+
+func TestRegistry_UsesClientFactory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := mock.NewMockInterface(ctrl)
+	mockClient.EXPECT().GetApplication(gomock.Any(), "test-app").Return(&v1alpha1.Application{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-app"},
+	}, nil)
+	mockClient.EXPECT().Close().Return(nil)
+
+	registry := newMockRegistry(mockClient)
+	result, err := registry.HandleGetApplication(context.Background(), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name:      "get_application",
+			Arguments: map[string]interface{}{"name": "test-app"},
+		},
+	})
+
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.False(t, result.IsError)
+}
+
+func TestRegistry_WithMiddleware(t *testing.T) {
+	var calls []string
+	record := func(label string) Middleware {
+		return func(tool mcp.Tool, next server.ToolHandlerFunc) server.ToolHandlerFunc {
+			return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				calls = append(calls, label+":"+tool.Name)
+				return next(ctx, request)
+			}
+		}
+	}
+
+	registry := NewRegistry(ClientFactory(func(ctx context.Context) (client.Interface, error) {
+		return nil, client.ErrServerAddrRequired
+	}), Config{}, WithMiddleware(record("outer")), WithMiddleware(record("inner")))
+
+	for _, tool := range registry.Tools() {
+		if tool.Tool.Name != "get_application" {
+			continue
+		}
+		result, err := tool.Handler(context.Background(), mcp.CallToolRequest{})
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+	}
+
+	assert.Equal(t, []string{"outer:get_application", "inner:get_application"}, calls)
+}
+
+func TestRegistry_ReportsErrorTypes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockClient := mock.NewMockInterface(ctrl)
+	mockClient.EXPECT().Close().Return(nil).AnyTimes()
+
+	failing := NewRegistry(ClientFactory(func(ctx context.Context) (client.Interface, error) {
+		return nil, client.ErrServerAddrRequired
+	}), Config{}, WithMiddleware(metrics.ToolMiddleware))
+	confirming := NewRegistry(ClientFactory(func(ctx context.Context) (client.Interface, error) {
+		return mockClient, nil
+	}), Config{ConfirmDestructive: true}, WithMiddleware(metrics.ToolMiddleware))
+
+	callTool(t, findTool(t, failing, "get_application").Handler, "get_application", map[string]interface{}{"name": "guestbook"})
+	callTool(t, findTool(t, newMockRegistry(mockClient, WithMiddleware(metrics.ToolMiddleware)), "get_operation_status").Handler, "get_operation_status", nil)
+	callTool(t, findTool(t, confirming, "delete_application").Handler, "delete_application", map[string]interface{}{
+		"name": "guestbook", ConfirmTokenArgument: "forged",
+	})
+
+	rec := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, metrics.Path, nil))
+	for _, want := range []string{
+		`argocd_mcp_tool_errors_total{error_type="connection",tool="get_application"} 1`,
+		`argocd_mcp_tool_errors_total{error_type="invalid_argument",tool="get_operation_status"} 1`,
+		`argocd_mcp_tool_errors_total{error_type="confirmation",tool="delete_application"} 1`,
+	} {
+		assert.Contains(t, rec.Body.String(), want)
+	}
+}
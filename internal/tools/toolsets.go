@@ -0,0 +1,120 @@
+package tools
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Toolset names group related tools so that they can be allowed or denied together
+const (
+	ToolsetApplications    = "applications"
+	ToolsetProjects        = "projects"
+	ToolsetClusters        = "clusters"
+	ToolsetRepositories    = "repositories"
+	ToolsetApplicationSets = "applicationsets"
+	ToolsetSession         = "session"
+)
+
+// toolset is a named group of tools
+type toolset struct {
+	name  string
+	tools []server.ServerTool
+}
+
+// toolsets returns all tools grouped by toolset
+func (r *Registry) toolsets() []toolset {
+	return []toolset{
+		{name: ToolsetApplications, tools: []server.ServerTool{
+			{Tool: ListAppsTool, Handler: r.HandleListApplications},
+			{Tool: GetAppTool, Handler: r.HandleGetApplication},
+			{Tool: GetAppManifestsTool, Handler: r.HandleGetApplicationManifests},
+			{Tool: GetAppEventsTool, Handler: r.HandleGetApplicationEvents},
+			{Tool: GetApplicationLogsToolDefinition, Handler: r.HandleGetApplicationLogs},
+			{Tool: GetApplicationResourceTreeTool, Handler: r.HandleGetApplicationResourceTree},
+			{Tool: GetAppHistoryTool, Handler: r.HandleGetApplicationHistory},
+			{Tool: DiffAppTool, Handler: r.HandleDiffApplication},
+			{Tool: GetOperationStatusTool, Handler: r.HandleGetOperationStatus},
+			{Tool: CreateAppTool, Handler: r.HandleCreateApplication},
+			{Tool: UpdateAppTool, Handler: r.HandleUpdateApplication},
+			{Tool: PatchAppTool, Handler: r.HandlePatchApplication},
+			{Tool: SyncAppTool, Handler: r.HandleSyncApplication},
+			{Tool: WaitForAppTool, Handler: r.HandleWaitForApplication},
+			{Tool: RollbackAppTool, Handler: r.HandleRollbackApplication},
+			{Tool: RefreshAppTool, Handler: r.HandleRefreshApplication},
+			{Tool: DeleteAppTool, Handler: r.HandleDeleteApplication},
+			{Tool: TerminateOperationTool, Handler: r.HandleTerminateOperation},
+		}},
+		{name: ToolsetProjects, tools: []server.ServerTool{
+			{Tool: ListProjectsTool, Handler: r.HandleListProjects},
+			{Tool: GetProjectTool, Handler: r.HandleGetProject},
+			{Tool: CreateProjectTool, Handler: r.HandleCreateProject},
+		}},
+		{name: ToolsetClusters, tools: []server.ServerTool{
+			{Tool: ListClusterTool, Handler: r.HandleListCluster},
+			{Tool: GetClusterTool, Handler: r.HandleGetCluster},
+		}},
+		{name: ToolsetApplicationSets, tools: []server.ServerTool{
+			{Tool: ListApplicationSetTool, Handler: r.HandleListApplicationSets},
+			{Tool: GetApplicationSetTool, Handler: r.HandleGetApplicationSet},
+			{Tool: CreateApplicationSetTool, Handler: r.HandleCreateApplicationSet},
+			{Tool: DeleteApplicationSetTool, Handler: r.HandleDeleteApplicationSet},
+		}},
+		{name: ToolsetRepositories, tools: []server.ServerTool{
+			{Tool: ListRepositoryTool, Handler: r.HandleListRepository},
+			{Tool: GetRepositoryTool, Handler: r.HandleGetRepository},
+		}},
+		{name: ToolsetSession, tools: []server.ServerTool{
+			{Tool: GetUserInfoTool, Handler: r.HandleGetUserInfo},
+			{Tool: ListInstancesTool, Handler: r.HandleListInstances},
+		}},
+	}
+}
+
+// Validate checks that the allow and deny lists only name known tools and toolsets
+func (c Config) Validate() error {
+	known := make(map[string]bool)
+	for _, set := range (&Registry{}).toolsets() {
+		known[set.name] = true
+		for _, tool := range set.tools {
+			known[tool.Tool.Name] = true
+		}
+	}
+
+	var unknown []string
+	for _, name := range slices.Concat(c.AllowTools, c.DenyTools) {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		names := make([]string, 0, len(known))
+		for name := range known {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("unknown tools or toolsets: %s (available: %s)", strings.Join(unknown, ", "), strings.Join(names, ", "))
+	}
+	return nil
+}
+
+// enabled reports whether a tool of the given toolset passes the read-only mode and the allow and deny lists
+func (c Config) enabled(set string, tool mcp.Tool) bool {
+	if c.ReadOnly && isDestructive(tool) {
+		return false
+	}
+	if len(c.AllowTools) > 0 && !slices.Contains(c.AllowTools, set) && !slices.Contains(c.AllowTools, tool.Name) {
+		return false
+	}
+	return !slices.Contains(c.DenyTools, set) && !slices.Contains(c.DenyTools, tool.Name)
+}
+
+// isDestructive reports whether a tool may modify ArgoCD. Tools without the
+// annotation are treated as destructive, as the MCP specification prescribes.
+func isDestructive(tool mcp.Tool) bool {
+	return tool.Annotations.DestructiveHint == nil || *tool.Annotations.DestructiveHint
+}
@@ -0,0 +1,82 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client"
+)
+
+func toolNames(registry *Registry) []string {
+	var names []string
+	for _, tool := range registry.Tools() {
+		names = append(names, tool.Tool.Name)
+	}
+	return names
+}
+
+func TestRegistry_ToolSelection(t *testing.T) {
+	factory := ClientFactory(func(ctx context.Context) (client.Interface, error) {
+		return nil, client.ErrServerAddrRequired
+	})
+
+	tests := []struct {
+		name        string
+		config      Config
+		wantTools   []string
+		unwantTools []string
+		wantCount   int
+	}{
+		{
+			name:      "all tools by default",
+			config:    Config{},
+			wantTools: []string{"list_application", "delete_application", "create_project", "get_user_info"},
+		},
+		{
+			name:        "read-only leaves out destructive tools",
+			config:      Config{ReadOnly: true},
+			wantTools:   []string{"list_application", "get_application", "refresh_application", "get_user_info"},
+			unwantTools: []string{"create_application", "sync_application", "delete_application", "terminate_operation", "create_project", "create_applicationset", "delete_applicationset"},
+		},
+		{
+			name:      "allow by toolset and tool name",
+			config:    Config{AllowTools: []string{"clusters", "get_user_info"}},
+			wantTools: []string{"list_cluster", "get_cluster", "get_user_info"},
+			wantCount: 3,
+		},
+		{
+			name:        "deny wins over allow",
+			config:      Config{AllowTools: []string{"projects"}, DenyTools: []string{"create_project"}},
+			wantTools:   []string{"list_project", "get_project"},
+			unwantTools: []string{"create_project"},
+			wantCount:   2,
+		},
+		{
+			name:        "deny toolset",
+			config:      Config{DenyTools: []string{"applications"}},
+			wantTools:   []string{"list_project"},
+			unwantTools: []string{"list_application", "sync_application"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			names := toolNames(NewRegistry(factory, tt.config))
+			for _, want := range tt.wantTools {
+				assert.Contains(t, names, want)
+			}
+			for _, unwant := range tt.unwantTools {
+				assert.NotContains(t, names, unwant)
+			}
+			if tt.wantCount > 0 {
+				assert.Len(t, names, tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	assert.NoError(t, Config{AllowTools: []string{"applications", "get_user_info"}, DenyTools: []string{"session"}}.Validate())
+	assert.ErrorContains(t, Config{DenyTools: []string{"delete_everything"}}.Validate(), "delete_everything")
+}
@@ -0,0 +1,90 @@
+package tools
+
+import (
+	"context"
+	"errors"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// WithTracing starts a span for every tool call, with a child span for each
+// ArgoCD client method the handler calls
+func WithTracing() Option {
+	return func(r *Registry) {
+		r.tracing = true
+	}
+}
+
+// tracedProvider hands out clients whose methods are traced
+type tracedProvider struct {
+	ClientProvider
+}
+
+func (p tracedProvider) Client(ctx context.Context, instance string) (client.Interface, error) {
+	c, err := p.ClientProvider.Client(ctx, instance)
+	if err != nil {
+		return nil, err
+	}
+	return client.WithTracing(c), nil
+}
+
+// traceCall wraps a tool handler in a span. A trace context passed by the client
+// in the request _meta (traceparent, tracestate) becomes the parent of the span.
+func (r *Registry) traceCall(tool mcp.Tool, next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	if !r.tracing {
+		return next
+	}
+
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if meta := request.Params.Meta; meta != nil {
+			ctx = otel.GetTextMapPropagator().Extract(ctx, metaCarrier(meta.AdditionalFields))
+		}
+
+		attrs := []attribute.KeyValue{
+			attribute.String("mcp.method.name", string(mcp.MethodToolsCall)),
+			attribute.String("gen_ai.tool.name", tool.Name),
+			attribute.String("argocd.instance", r.instanceName(request)),
+		}
+		if session := server.ClientSessionFromContext(ctx); session != nil {
+			attrs = append(attrs, attribute.String("mcp.session.id", session.SessionID()))
+		}
+		ctx, span := tracing.Tracer().Start(ctx, string(mcp.MethodToolsCall)+" "+tool.Name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(attrs...),
+		)
+
+		result, err := next(ctx, request)
+		spanErr := err
+		if spanErr == nil && result != nil && result.IsError {
+			spanErr = errors.New(resultText(result))
+		}
+		tracing.End(span, spanErr)
+		return result, err
+	}
+}
+
+// metaCarrier reads trace context fields from the _meta of an MCP request
+type metaCarrier map[string]any
+
+func (c metaCarrier) Get(key string) string {
+	value, _ := c[key].(string)
+	return value
+}
+
+func (c metaCarrier) Set(key, value string) {
+	c[key] = value
+}
+
+func (c metaCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
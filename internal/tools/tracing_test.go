@@ -0,0 +1,75 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client/mock"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRegistry_WithTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	})
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := mock.NewMockInterface(ctrl)
+	mockClient.EXPECT().Close().Return(nil).AnyTimes()
+	mockClient.EXPECT().GetApplication(gomock.Any(), "guestbook").Return(&v1alpha1.Application{}, nil)
+	mockClient.EXPECT().GetApplication(gomock.Any(), "missing").Return(nil, errors.New("not found"))
+
+	registry := newMockRegistry(mockClient, WithTracing())
+	handler := findTool(t, registry, "get_application").Handler
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	_, err := handler(context.Background(), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name:      "get_application",
+			Arguments: map[string]interface{}{"name": "guestbook"},
+			Meta: &mcp.Meta{AdditionalFields: map[string]any{
+				"traceparent": "00-" + traceID + "-00f067aa0ba902b7-01",
+			}},
+		},
+	})
+	require.NoError(t, err)
+
+	result, err := handler(context.Background(), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Name: "get_application", Arguments: map[string]interface{}{"name": "missing"}},
+	})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 4)
+
+	// Client spans end before the tool spans that contain them
+	clientSpan, toolSpan := spans[0], spans[1]
+	assert.Equal(t, "argocd.client/GetApplication", clientSpan.Name())
+	assert.Equal(t, "tools/call get_application", toolSpan.Name())
+	assert.Equal(t, toolSpan.SpanContext().SpanID(), clientSpan.Parent().SpanID())
+	assert.Equal(t, traceID, toolSpan.SpanContext().TraceID().String())
+	assert.Equal(t, codes.Unset, toolSpan.Status().Code)
+
+	failedClientSpan, failedToolSpan := spans[2], spans[3]
+	assert.Equal(t, codes.Error, failedClientSpan.Status().Code)
+	assert.Equal(t, codes.Error, failedToolSpan.Status().Code)
+	assert.NotEqual(t, traceID, failedToolSpan.SpanContext().TraceID().String())
+}
@@ -0,0 +1,159 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client"
+	"sigs.k8s.io/yaml"
+)
+
+// UpdateAppTool defines the update_application tool schema
+var UpdateAppTool = mcp.NewTool("update_application",
+	mcp.WithDescription("Replaces the spec of an existing ArgoCD application and shows a before/after diff. The server refuses the update when the application changed since resource_version was read, even while it is being applied."),
+	mcp.WithDestructiveHintAnnotation(true),
+	mcp.WithString("name",
+		mcp.Required(),
+		mcp.Description("The name of the application to update."),
+	),
+	mcp.WithString("spec",
+		mcp.Required(),
+		mcp.Description("The complete new application spec (JSON or YAML), as in the 'spec' of get_application. Fields left out are removed."),
+	),
+	mcp.WithString("resource_version",
+		mcp.Required(),
+		mcp.Description("The metadata.resourceVersion of the application the spec was based on, as returned by get_application."),
+	),
+	mcp.WithBoolean("dry_run",
+		mcp.Description("If true, only shows the diff without updating the application (default: false)."),
+	),
+)
+
+// HandleUpdateApplication processes update_application tool requests
+func (r *Registry) HandleUpdateApplication(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	name := request.GetString("name", "")
+	spec := request.GetString("spec", "")
+	resourceVersion := request.GetString("resource_version", "")
+	dryRun := request.GetBool("dry_run", false)
+
+	// Get the gRPC client for this call
+	argoClient, err := r.client(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to create gRPC client: %v", err)), nil
+	}
+	defer func() { _ = argoClient.Close() }()
+
+	return updateApplicationHandler(ctx, argoClient, name, spec, resourceVersion, dryRun)
+}
+
+// updateApplicationHandler handles the core logic for updating an application.
+// This is separated out to enable testing with mocked clients.
+func updateApplicationHandler(
+	ctx context.Context,
+	argoClient client.Interface,
+	name string,
+	specStr string,
+	resourceVersion string,
+	dryRun bool,
+) (*mcp.CallToolResult, error) {
+	if name == "" {
+		return invalidArgument(ctx, "Application name is required"), nil
+	}
+	if specStr == "" {
+		return invalidArgument(ctx, "spec is required"), nil
+	}
+	if resourceVersion == "" {
+		return invalidArgument(ctx, "resource_version is required"), nil
+	}
+
+	spec, err := decodeApplicationSpec([]byte(specStr))
+	if err != nil {
+		return invalidArgument(ctx, fmt.Sprintf("Failed to parse spec: %v", err)), nil
+	}
+
+	app, err := argoClient.GetApplication(ctx, name)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get application: %v", err)), nil
+	}
+	if app.ResourceVersion != resourceVersion {
+		return modifiedApplicationError(name, resourceVersion, app.ResourceVersion), nil
+	}
+
+	return applyApplicationSpec(ctx, argoClient, app, spec, dryRun)
+}
+
+// ApplicationChange reports a change to the spec of an application
+type ApplicationChange struct {
+	Name            string `json:"name"`
+	ResourceVersion string `json:"resourceVersion"`
+	Changed         bool   `json:"changed"`
+	DryRun          bool   `json:"dryRun,omitempty"`
+	Diff            string `json:"diff,omitempty"`
+}
+
+// applyApplicationSpec sets the spec of app, unless nothing changes or dryRun is
+// set, and reports the difference between the old and the new spec
+func applyApplicationSpec(ctx context.Context, argoClient client.Interface, app *v1alpha1.Application, spec v1alpha1.ApplicationSpec, dryRun bool) (*mcp.CallToolResult, error) {
+	diff, err := specDiff(app.Spec, spec)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to compare specs: %v", err)), nil
+	}
+	change := ApplicationChange{
+		Name:            app.Name,
+		ResourceVersion: app.ResourceVersion,
+		Changed:         diff != "",
+		DryRun:          dryRun,
+		Diff:            diff,
+	}
+
+	if change.Changed && !dryRun {
+		// The server applies the patch to the application as it stands, so the test
+		// refuses it when the application changed since app was read
+		patch, err := json.Marshal([]map[string]any{
+			{"op": "test", "path": "/metadata/resourceVersion", "value": app.ResourceVersion},
+			{"op": "replace", "path": "/spec", "value": spec},
+		})
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to encode spec: %v", err)), nil
+		}
+		updated, err := argoClient.PatchApplication(ctx, app.Name, string(patch), PatchTypeJSON)
+		if err != nil {
+			if current, getErr := argoClient.GetApplication(ctx, app.Name); getErr == nil && current.ResourceVersion != app.ResourceVersion {
+				return modifiedApplicationError(app.Name, app.ResourceVersion, current.ResourceVersion), nil
+			}
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to update application: %v", err)), nil
+		}
+		// The server may default fields, so the diff shows what it stored
+		if change.Diff, err = specDiff(app.Spec, updated.Spec); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to compare specs: %v", err)), nil
+		}
+		change.ResourceVersion = updated.ResourceVersion
+	}
+
+	jsonData, err := json.MarshalIndent(change, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to format response: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+func modifiedApplicationError(name, resourceVersion, current string) *mcp.CallToolResult {
+	return mcp.NewToolResultError(fmt.Sprintf("Application %s was modified since resourceVersion %s (now %s); get it again and reapply the changes to the current spec", name, resourceVersion, current))
+}
+
+// decodeApplicationSpec decodes a JSON or YAML application spec, rejecting unknown fields
+func decodeApplicationSpec(data []byte) (v1alpha1.ApplicationSpec, error) {
+	var spec v1alpha1.ApplicationSpec
+	jsonData, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return spec, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(jsonData))
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(&spec)
+	return spec, err
+}
@@ -0,0 +1,151 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client/mock"
+	"go.uber.org/mock/gomock"
+)
+
+func TestUpdateApplicationHandler(t *testing.T) {
+	spec := `{
+  "project": "default",
+  "source": {"repoURL": "https://github.com/example/repo", "path": "manifests", "targetRevision": "v2"},
+  "destination": {"server": "https://kubernetes.default.svc", "namespace": "default"}
+}`
+
+	t.Run("replaces the spec", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockClient := mock.NewMockInterface(ctrl)
+		mockClient.EXPECT().GetApplication(gomock.Any(), "test-app").Return(patchableApplication(), nil)
+		mockClient.EXPECT().PatchApplication(gomock.Any(), "test-app", gomock.Any(), PatchTypeJSON).DoAndReturn(serverPatch(t, patchableApplication()))
+
+		result, err := updateApplicationHandler(context.Background(), mockClient, "test-app", spec, "100", false)
+		require.NoError(t, err)
+		change := decodeResult[ApplicationChange](t, result)
+		assert.True(t, change.Changed)
+		assert.Equal(t, "101", change.ResourceVersion)
+		assert.Contains(t, change.Diff, "-  targetRevision: main\n+  targetRevision: v2\n")
+	})
+
+	t.Run("refuses a concurrent change", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		// The application changes between reading it and patching it
+		changed := patchableApplication()
+		changed.ResourceVersion = "101"
+		changed.Spec.Source.Path = "overlays/prod"
+		mockClient := mock.NewMockInterface(ctrl)
+		gomock.InOrder(
+			mockClient.EXPECT().GetApplication(gomock.Any(), "test-app").Return(patchableApplication(), nil),
+			mockClient.EXPECT().PatchApplication(gomock.Any(), "test-app", gomock.Any(), PatchTypeJSON).DoAndReturn(serverPatch(t, changed)),
+			mockClient.EXPECT().GetApplication(gomock.Any(), "test-app").Return(changed, nil),
+		)
+
+		result, err := updateApplicationHandler(context.Background(), mockClient, "test-app", spec, "100", false)
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "Application test-app was modified since resourceVersion 100 (now 101)")
+	})
+
+	t.Run("reports other update failures", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockClient := mock.NewMockInterface(ctrl)
+		mockClient.EXPECT().GetApplication(gomock.Any(), "test-app").Return(patchableApplication(), nil).Times(2)
+		mockClient.EXPECT().PatchApplication(gomock.Any(), "test-app", gomock.Any(), PatchTypeJSON).Return(nil, errors.New("permission denied"))
+
+		result, err := updateApplicationHandler(context.Background(), mockClient, "test-app", spec, "100", false)
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "Failed to update application: permission denied")
+	})
+
+	t.Run("accepts yaml and dry runs", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockClient := mock.NewMockInterface(ctrl)
+		mockClient.EXPECT().GetApplication(gomock.Any(), "test-app").Return(patchableApplication(), nil)
+
+		yamlSpec := "project: default\nsource:\n  repoURL: https://github.com/example/repo\n  path: manifests\n  targetRevision: main\ndestination:\n  name: in-cluster\n  namespace: default\n"
+		result, err := updateApplicationHandler(context.Background(), mockClient, "test-app", yamlSpec, "100", true)
+		require.NoError(t, err)
+		change := decodeResult[ApplicationChange](t, result)
+		assert.True(t, change.Changed)
+		assert.True(t, change.DryRun)
+		assert.Contains(t, change.Diff, "+  name: in-cluster")
+		assert.Contains(t, change.Diff, "-  server: https://kubernetes.default.svc")
+	})
+
+	tests := []struct {
+		name            string
+		spec            string
+		resourceVersion string
+		fetch           bool
+		getErr          error
+		errorContains   string
+	}{
+		{
+			name:            "missing spec",
+			resourceVersion: "100",
+			errorContains:   "spec is required",
+		},
+		{
+			name:          "missing resource version",
+			spec:          spec,
+			errorContains: "resource_version is required",
+		},
+		{
+			name:            "unknown field",
+			spec:            `{"project":"default","destinaton":{}}`,
+			resourceVersion: "100",
+			errorContains:   `unknown field "destinaton"`,
+		},
+		{
+			name:            "stale resource version",
+			spec:            spec,
+			resourceVersion: "99",
+			fetch:           true,
+			errorContains:   "Application test-app was modified since resourceVersion 99 (now 100)",
+		},
+		{
+			name:            "application not found",
+			spec:            spec,
+			resourceVersion: "100",
+			fetch:           true,
+			getErr:          errors.New("not found"),
+			errorContains:   "Failed to get application: not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockClient := mock.NewMockInterface(ctrl)
+			if tt.fetch {
+				app := patchableApplication()
+				if tt.getErr != nil {
+					app = nil
+				}
+				mockClient.EXPECT().GetApplication(gomock.Any(), "test-app").Return(app, tt.getErr)
+			}
+
+			result, err := updateApplicationHandler(context.Background(), mockClient, "test-app", tt.spec, tt.resourceVersion, false)
+			require.NoError(t, err)
+			assert.True(t, result.IsError)
+			assert.Contains(t, result.Content[0].(mcp.TextContent).Text, tt.errorContains)
+		})
+	}
+}
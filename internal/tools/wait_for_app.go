@@ -0,0 +1,394 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/gitops-engine/pkg/health"
+	synccommon "github.com/argoproj/gitops-engine/pkg/sync/common"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/logging"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// Conditions an application can be waited for
+const (
+	WaitUntilSyncedAndHealthy = "synced_and_healthy"
+	WaitUntilSynced           = "synced"
+	WaitUntilHealthy          = "healthy"
+	WaitUntilOperation        = "operation"
+)
+
+// Bounds of the time spent waiting for an application
+const (
+	DefaultWaitTimeout = 5 * time.Minute
+	MaxWaitTimeout     = 30 * time.Minute
+)
+
+// Results of waiting for an application
+const (
+	WaitReached   = "reached"
+	WaitFailed    = "failed"
+	WaitTimeout   = "timeout"
+	WaitCancelled = "cancelled"
+	WaitDeleted   = "deleted"
+)
+
+// methodNotificationProgress is the MCP progress notification
+const methodNotificationProgress = "notifications/progress"
+
+// WaitForAppTool defines the wait_for_application tool schema
+var WaitForAppTool = mcp.NewTool("wait_for_application",
+	mcp.WithDescription("Waits until an ArgoCD application reaches a condition or the timeout expires, and returns a compact summary of the outcome. A running operation is always waited for first. Progress notifications report the operation phase and the per-resource sync results while waiting."),
+	mcp.WithDestructiveHintAnnotation(false),
+	mcp.WithString("name",
+		mcp.Required(),
+		mcp.Description("The name of the application to wait for."),
+	),
+	mcp.WithString("until",
+		mcp.Description("The condition to wait for: synced_and_healthy (default), synced, healthy, or operation (the current operation succeeded)."),
+		mcp.Enum(WaitUntilSyncedAndHealthy, WaitUntilSynced, WaitUntilHealthy, WaitUntilOperation),
+	),
+	mcp.WithNumber("timeout_seconds",
+		mcp.Description(fmt.Sprintf("Optional. How long to wait, in seconds (default: %d, max: %d).", int(DefaultWaitTimeout.Seconds()), int(MaxWaitTimeout.Seconds()))),
+	),
+)
+
+// WaitOutcome summarizes how waiting for an application ended
+type WaitOutcome struct {
+	Application   string            `json:"application"`
+	Condition     string            `json:"condition"`
+	Result        string            `json:"result"`
+	Message       string            `json:"message,omitempty"`
+	Elapsed       string            `json:"elapsed"`
+	SyncStatus    string            `json:"syncStatus"`
+	HealthStatus  string            `json:"healthStatus"`
+	HealthMessage string            `json:"healthMessage,omitempty"`
+	Revision      string            `json:"revision,omitempty"`
+	Operation     *OperationOutcome `json:"operation,omitempty"`
+}
+
+// OperationOutcome summarizes the last operation of an application
+type OperationOutcome struct {
+	Phase      string            `json:"phase"`
+	Message    string            `json:"message,omitempty"`
+	StartedAt  string            `json:"startedAt,omitempty"`
+	FinishedAt string            `json:"finishedAt,omitempty"`
+	Resources  map[string]int    `json:"resources,omitempty"`
+	Failed     []ResourceOutcome `json:"failed,omitempty"`
+}
+
+// ResourceOutcome is the sync result of a resource that did not sync
+type ResourceOutcome struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	Message   string `json:"message,omitempty"`
+}
+
+// HandleWaitForApplication processes wait_for_application tool requests
+func (r *Registry) HandleWaitForApplication(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	appName := request.GetString("name", "")
+	until := request.GetString("until", WaitUntilSyncedAndHealthy)
+	timeout, err := waitTimeout(request, "timeout_seconds")
+	if err != nil {
+		return invalidArgument(ctx, err.Error()), nil
+	}
+
+	// Get the gRPC client for this call
+	argoClient, err := r.client(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to create gRPC client: %v", err)), nil
+	}
+	defer func() { _ = argoClient.Close() }()
+
+	return waitForApplicationHandler(ctx, argoClient, appName, until, timeout, newProgressReporter(ctx, request))
+}
+
+// waitForApplicationHandler handles the core logic for waiting for an application.
+// This is separated out to enable testing with mocked clients.
+func waitForApplicationHandler(
+	ctx context.Context,
+	argoClient client.Interface,
+	appName string,
+	until string,
+	timeout time.Duration,
+	progress *progressReporter,
+) (*mcp.CallToolResult, error) {
+	if appName == "" {
+		return invalidArgument(ctx, "Application name is required"), nil
+	}
+	if !validWaitCondition(until) {
+		return invalidArgument(ctx, fmt.Sprintf("Unknown condition %q (expected synced_and_healthy, synced, healthy or operation)", until)), nil
+	}
+
+	app, err := argoClient.GetApplication(ctx, appName)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to get application: %v", err)), nil
+	}
+	return waitResult(waitForApplication(ctx, argoClient, app, until, timeout, progress))
+}
+
+// waitTimeout reads a timeout in seconds from the request
+func waitTimeout(request mcp.CallToolRequest, argument string) (time.Duration, error) {
+	seconds := request.GetFloat(argument, 0)
+	switch {
+	case seconds < 0:
+		return 0, fmt.Errorf("%s must not be negative", argument)
+	case seconds == 0:
+		return DefaultWaitTimeout, nil
+	}
+	return min(time.Duration(seconds*float64(time.Second)), MaxWaitTimeout), nil
+}
+
+func validWaitCondition(until string) bool {
+	switch until {
+	case WaitUntilSyncedAndHealthy, WaitUntilSynced, WaitUntilHealthy, WaitUntilOperation:
+		return true
+	}
+	return false
+}
+
+// waitForApplication follows app through the watch API until it meets the
+// condition, its operation fails, or the timeout expires
+func waitForApplication(
+	ctx context.Context,
+	argoClient client.Interface,
+	app *v1alpha1.Application,
+	until string,
+	timeout time.Duration,
+	progress *progressReporter,
+) *WaitOutcome {
+	started := time.Now()
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	w := &appWait{until: until}
+	outcome := func(result, message string) *WaitOutcome {
+		return newWaitOutcome(app, until, result, message, time.Since(started))
+	}
+
+	progress.report(app)
+	if result, message := w.check(app); result != "" {
+		return outcome(result, message)
+	}
+
+	events, err := argoClient.WatchApplications(waitCtx, app.Name, app.ResourceVersion)
+	if err != nil {
+		return outcome(WaitFailed, fmt.Sprintf("failed to watch application: %v", err))
+	}
+	for done := false; !done; {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				if waitCtx.Err() == nil {
+					return outcome(WaitFailed, "the watch ended before the wait was over")
+				}
+				done = true
+				break
+			}
+			if event.Application.Name != app.Name {
+				continue
+			}
+			app = &event.Application
+			if event.Type == watch.Deleted {
+				return outcome(WaitDeleted, "the application was deleted")
+			}
+			progress.report(app)
+			if result, message := w.check(app); result != "" {
+				return outcome(result, message)
+			}
+		case <-waitCtx.Done():
+			done = true
+		}
+	}
+
+	if errors.Is(waitCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+		return outcome(WaitTimeout, fmt.Sprintf("%s not reached within %s", until, timeout))
+	}
+	return outcome(WaitCancelled, "the wait was cancelled")
+}
+
+// appWait tracks the state of an application against a wait condition
+type appWait struct {
+	until string
+	// operation is set once a running operation has been seen
+	operation bool
+}
+
+// check returns the result of the wait, or an empty result while it goes on
+func (w *appWait) check(app *v1alpha1.Application) (string, string) {
+	state := app.Status.OperationState
+	if app.Operation != nil || (state != nil && !state.Phase.Completed()) {
+		w.operation = true
+		return "", ""
+	}
+
+	// A finished operation decides the outcome when it was waited for
+	if state != nil && (w.operation || w.until == WaitUntilOperation) && !state.Phase.Successful() {
+		return WaitFailed, fmt.Sprintf("operation %s: %s", state.Phase, state.Message)
+	}
+
+	synced := app.Status.Sync.Status == v1alpha1.SyncStatusCodeSynced
+	healthy := app.Status.Health.Status == health.HealthStatusHealthy
+	switch {
+	case w.until == WaitUntilOperation,
+		w.until == WaitUntilSynced && synced,
+		w.until == WaitUntilHealthy && healthy,
+		w.until == WaitUntilSyncedAndHealthy && synced && healthy:
+		return WaitReached, ""
+	}
+	return "", ""
+}
+
+// newWaitOutcome summarizes the state of app when a wait ends
+func newWaitOutcome(app *v1alpha1.Application, until, result, message string, elapsed time.Duration) *WaitOutcome {
+	outcome := &WaitOutcome{
+		Application:   app.Name,
+		Condition:     until,
+		Result:        result,
+		Message:       message,
+		Elapsed:       elapsed.Round(time.Second).String(),
+		SyncStatus:    string(app.Status.Sync.Status),
+		HealthStatus:  string(app.Status.Health.Status),
+		HealthMessage: app.Status.Health.Message,
+		Revision:      app.Status.Sync.Revision,
+	}
+
+	state := app.Status.OperationState
+	if state == nil {
+		return outcome
+	}
+	outcome.Operation = &OperationOutcome{
+		Phase:     string(state.Phase),
+		Message:   state.Message,
+		StartedAt: state.StartedAt.Format(time.RFC3339),
+	}
+	if state.FinishedAt != nil {
+		outcome.Operation.FinishedAt = state.FinishedAt.Format(time.RFC3339)
+	}
+	if state.SyncResult == nil {
+		return outcome
+	}
+	if state.SyncResult.Revision != "" {
+		outcome.Revision = state.SyncResult.Revision
+	}
+	outcome.Operation.Resources = make(map[string]int)
+	for _, res := range state.SyncResult.Resources {
+		status := resourceResultStatus(res)
+		outcome.Operation.Resources[status]++
+		if resourceResultFailed(res) {
+			outcome.Operation.Failed = append(outcome.Operation.Failed, ResourceOutcome{
+				Kind:      res.Kind,
+				Namespace: res.Namespace,
+				Name:      res.Name,
+				Status:    status,
+				Message:   res.Message,
+			})
+		}
+	}
+	return outcome
+}
+
+// resourceResultFailed reports whether a resource failed to sync, or a hook failed
+func resourceResultFailed(res *v1alpha1.ResourceResult) bool {
+	return res.Status == synccommon.ResultCodeSyncFailed || res.HookPhase.Failed()
+}
+
+// resourceResultStatus is the sync result of a resource, or the phase of a hook
+func resourceResultStatus(res *v1alpha1.ResourceResult) string {
+	if res.Status != "" {
+		return string(res.Status)
+	}
+	if res.HookPhase != "" {
+		return string(res.HookPhase)
+	}
+	return "Pending"
+}
+
+// waitResult renders a wait outcome; waits that did not reach their condition are errors
+func waitResult(outcome *WaitOutcome) (*mcp.CallToolResult, error) {
+	jsonData, err := json.MarshalIndent(outcome, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to format response: %v", err)), nil
+	}
+	if outcome.Result != WaitReached {
+		return mcp.NewToolResultError(fmt.Sprintf("Application %s did not reach %s (%s): %s\n%s", outcome.Application, outcome.Condition, outcome.Result, outcome.Message, jsonData)), nil
+	}
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// progressReporter sends MCP progress notifications about an application to the
+// client of a tool call that asked for them with a progress token
+type progressReporter struct {
+	ctx      context.Context
+	token    mcp.ProgressToken
+	progress int
+	// results holds the last reported sync result of each resource
+	results map[string]string
+}
+
+func newProgressReporter(ctx context.Context, request mcp.CallToolRequest) *progressReporter {
+	p := &progressReporter{ctx: ctx, results: make(map[string]string)}
+	if request.Params.Meta != nil {
+		p.token = request.Params.Meta.ProgressToken
+	}
+	return p
+}
+
+// report notifies the phase of the operation and the resources whose sync result changed
+func (p *progressReporter) report(app *v1alpha1.Application) {
+	if p == nil || p.token == nil {
+		return
+	}
+	mcpServer := server.ServerFromContext(p.ctx)
+	if mcpServer == nil {
+		return
+	}
+
+	phase := "Idle"
+	var changes []string
+	if state := app.Status.OperationState; state != nil {
+		phase = string(state.Phase)
+		if state.SyncResult != nil {
+			for _, res := range state.SyncResult.Resources {
+				key := fmt.Sprintf("%s/%s", res.Kind, res.Name)
+				status := resourceResultStatus(res)
+				if p.results[key] == status {
+					continue
+				}
+				p.results[key] = status
+				change := fmt.Sprintf("%s %s", key, status)
+				if res.Message != "" && status != string(synccommon.ResultCodeSynced) {
+					change += " (" + res.Message + ")"
+				}
+				changes = append(changes, change)
+			}
+		}
+	}
+	sort.Strings(changes)
+
+	message := fmt.Sprintf("operation %s, sync %s, health %s", phase, app.Status.Sync.Status, app.Status.Health.Status)
+	if len(changes) > 0 {
+		message += ": " + strings.Join(changes, ", ")
+	}
+
+	p.progress++
+	err := mcpServer.SendNotificationToClient(p.ctx, methodNotificationProgress, map[string]any{
+		"progressToken": p.token,
+		"progress":      p.progress,
+		"message":       message,
+	})
+	if err != nil {
+		logging.WithField("application", app.Name).WithError(err).Debug("Failed to send progress notification")
+	}
+}
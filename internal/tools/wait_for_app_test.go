@@ -0,0 +1,287 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/gitops-engine/pkg/health"
+	synccommon "github.com/argoproj/gitops-engine/pkg/sync/common"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client"
+	"github.com/toyamagu-2021/argocd-mcp-server/internal/argocd/client/mock"
+	"go.uber.org/mock/gomock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// operatingApplication returns a guestbook application whose sync operation is in phase
+func operatingApplication(phase synccommon.OperationPhase, sync v1alpha1.SyncStatusCode, healthStatus health.HealthStatusCode, resources ...*v1alpha1.ResourceResult) v1alpha1.Application {
+	app := testApplication(sync, healthStatus)
+	app.Status.OperationState = &v1alpha1.OperationState{
+		Phase:      phase,
+		StartedAt:  metav1.NewTime(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)),
+		SyncResult: &v1alpha1.SyncOperationResult{Revision: "abc123", Resources: resources},
+	}
+	if phase.Completed() {
+		finished := metav1.NewTime(time.Date(2026, 1, 2, 3, 5, 5, 0, time.UTC))
+		app.Status.OperationState.FinishedAt = &finished
+	} else {
+		app.Operation = &v1alpha1.Operation{Sync: &v1alpha1.SyncOperation{}}
+	}
+	return app
+}
+
+// watchEvents makes the mock client stream events, closing the watch once its context ends
+func watchEvents(mockClient *mock.MockInterface, events ...v1alpha1.Application) {
+	mockClient.EXPECT().WatchApplications(gomock.Any(), "guestbook", "1").DoAndReturn(
+		func(ctx context.Context, name, resourceVersion string) (<-chan *v1alpha1.ApplicationWatchEvent, error) {
+			ch := make(chan *v1alpha1.ApplicationWatchEvent, len(events))
+			for _, app := range events {
+				ch <- &v1alpha1.ApplicationWatchEvent{Type: watch.Modified, Application: app}
+			}
+			go func() {
+				<-ctx.Done()
+				close(ch)
+			}()
+			return ch, nil
+		})
+}
+
+func TestWaitForApplicationHandler(t *testing.T) {
+	running := synccommon.OperationRunning
+	deployment := &v1alpha1.ResourceResult{Kind: "Deployment", Namespace: "default", Name: "guestbook-ui", Status: synccommon.ResultCodeSynced}
+	broken := &v1alpha1.ResourceResult{Kind: "Service", Namespace: "default", Name: "guestbook-ui", Status: synccommon.ResultCodeSyncFailed, Message: "field is immutable"}
+
+	tests := []struct {
+		name       string
+		until      string
+		timeout    time.Duration
+		app        v1alpha1.Application
+		watch      bool
+		events     []v1alpha1.Application
+		wantResult string
+		wantError  bool
+		check      func(t *testing.T, outcome WaitOutcome)
+	}{
+		{
+			name:       "already synced and healthy",
+			app:        testApplication(v1alpha1.SyncStatusCodeSynced, health.HealthStatusHealthy),
+			wantResult: WaitReached,
+		},
+		{
+			name:  "operation succeeds and the application becomes healthy",
+			watch: true,
+			app:   operatingApplication(running, v1alpha1.SyncStatusCodeOutOfSync, health.HealthStatusHealthy),
+			events: []v1alpha1.Application{
+				operatingApplication(running, v1alpha1.SyncStatusCodeOutOfSync, health.HealthStatusHealthy, deployment),
+				operatingApplication(synccommon.OperationSucceeded, v1alpha1.SyncStatusCodeSynced, health.HealthStatusProgressing, deployment),
+				operatingApplication(synccommon.OperationSucceeded, v1alpha1.SyncStatusCodeSynced, health.HealthStatusHealthy, deployment),
+			},
+			wantResult: WaitReached,
+			check: func(t *testing.T, outcome WaitOutcome) {
+				assert.Equal(t, "Healthy", outcome.HealthStatus)
+				assert.Equal(t, "abc123", outcome.Revision)
+				require.NotNil(t, outcome.Operation)
+				assert.Equal(t, "Succeeded", outcome.Operation.Phase)
+				assert.Equal(t, map[string]int{"Synced": 1}, outcome.Operation.Resources)
+				assert.Equal(t, "2026-01-02T03:05:05Z", outcome.Operation.FinishedAt)
+			},
+		},
+		{
+			name:  "synced only",
+			watch: true,
+			until: WaitUntilSynced,
+			app:   testApplication(v1alpha1.SyncStatusCodeOutOfSync, health.HealthStatusHealthy),
+			events: []v1alpha1.Application{
+				testApplication(v1alpha1.SyncStatusCodeSynced, health.HealthStatusDegraded),
+			},
+			wantResult: WaitReached,
+		},
+		{
+			name:  "failed operation",
+			watch: true,
+			app:   operatingApplication(running, v1alpha1.SyncStatusCodeOutOfSync, health.HealthStatusHealthy),
+			events: []v1alpha1.Application{
+				operatingApplication(synccommon.OperationFailed, v1alpha1.SyncStatusCodeOutOfSync, health.HealthStatusHealthy, deployment, broken),
+			},
+			wantResult: WaitFailed,
+			wantError:  true,
+			check: func(t *testing.T, outcome WaitOutcome) {
+				require.NotNil(t, outcome.Operation)
+				assert.Equal(t, map[string]int{"Synced": 1, "SyncFailed": 1}, outcome.Operation.Resources)
+				assert.Equal(t, []ResourceOutcome{{Kind: "Service", Namespace: "default", Name: "guestbook-ui", Status: "SyncFailed", Message: "field is immutable"}}, outcome.Operation.Failed)
+			},
+		},
+		{
+			name:       "failed earlier operation decides the operation condition",
+			until:      WaitUntilOperation,
+			app:        operatingApplication(synccommon.OperationError, v1alpha1.SyncStatusCodeOutOfSync, health.HealthStatusHealthy),
+			wantResult: WaitFailed,
+			wantError:  true,
+		},
+		{
+			name:       "timeout",
+			watch:      true,
+			timeout:    50 * time.Millisecond,
+			app:        testApplication(v1alpha1.SyncStatusCodeOutOfSync, health.HealthStatusHealthy),
+			wantResult: WaitTimeout,
+			wantError:  true,
+			check: func(t *testing.T, outcome WaitOutcome) {
+				assert.Contains(t, outcome.Message, "synced_and_healthy not reached within 50ms")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockClient := mock.NewMockInterface(ctrl)
+			mockClient.EXPECT().GetApplication(gomock.Any(), "guestbook").Return(&tt.app, nil)
+			if tt.watch {
+				watchEvents(mockClient, tt.events...)
+			}
+
+			until := tt.until
+			if until == "" {
+				until = WaitUntilSyncedAndHealthy
+			}
+			timeout := tt.timeout
+			if timeout == 0 {
+				timeout = 5 * time.Second
+			}
+			result, err := waitForApplicationHandler(context.Background(), mockClient, "guestbook", until, timeout, nil)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantError, result.IsError)
+
+			outcome := decodeResult[WaitOutcome](t, result)
+			assert.Equal(t, tt.wantResult, outcome.Result)
+			assert.Equal(t, "guestbook", outcome.Application)
+			assert.Equal(t, until, outcome.Condition)
+			if tt.check != nil {
+				tt.check(t, outcome)
+			}
+		})
+	}
+}
+
+func TestWaitForApplicationHandler_Errors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockClient := mock.NewMockInterface(ctrl)
+
+	result, err := waitForApplicationHandler(context.Background(), mockClient, "", WaitUntilSynced, time.Second, nil)
+	require.NoError(t, err)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "Application name is required")
+
+	result, err = waitForApplicationHandler(context.Background(), mockClient, "guestbook", "degraded", time.Second, nil)
+	require.NoError(t, err)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, `Unknown condition "degraded"`)
+
+	mockClient.EXPECT().GetApplication(gomock.Any(), "guestbook").Return(nil, errors.New("not found"))
+	result, err = waitForApplicationHandler(context.Background(), mockClient, "guestbook", WaitUntilSynced, time.Second, nil)
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "Failed to get application: not found")
+
+	// A watch that ends early fails the wait
+	app := testApplication(v1alpha1.SyncStatusCodeOutOfSync, health.HealthStatusHealthy)
+	mockClient.EXPECT().GetApplication(gomock.Any(), "guestbook").Return(&app, nil)
+	closed := make(chan *v1alpha1.ApplicationWatchEvent)
+	close(closed)
+	mockClient.EXPECT().WatchApplications(gomock.Any(), "guestbook", "1").Return(closed, nil)
+	result, err = waitForApplicationHandler(context.Background(), mockClient, "guestbook", WaitUntilSynced, time.Second, nil)
+	require.NoError(t, err)
+	assert.Equal(t, WaitFailed, decodeResult[WaitOutcome](t, result).Result)
+}
+
+func TestWaitTimeout(t *testing.T) {
+	request := func(seconds any) mcp.CallToolRequest {
+		return mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: map[string]any{"timeout_seconds": seconds}}}
+	}
+
+	timeout, err := waitTimeout(mcp.CallToolRequest{}, "timeout_seconds")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultWaitTimeout, timeout)
+
+	timeout, err = waitTimeout(request(90), "timeout_seconds")
+	require.NoError(t, err)
+	assert.Equal(t, 90*time.Second, timeout)
+
+	timeout, err = waitTimeout(request(86400), "timeout_seconds")
+	require.NoError(t, err)
+	assert.Equal(t, MaxWaitTimeout, timeout)
+
+	_, err = waitTimeout(request(-1), "timeout_seconds")
+	assert.ErrorContains(t, err, "timeout_seconds must not be negative")
+}
+
+func TestRegistry_WaitForApplicationProgress(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	deployment := &v1alpha1.ResourceResult{Kind: "Deployment", Namespace: "default", Name: "guestbook-ui", Status: synccommon.ResultCodeSynced}
+	hook := &v1alpha1.ResourceResult{Kind: "Job", Namespace: "default", Name: "migrate", HookPhase: synccommon.OperationRunning}
+	app := operatingApplication(synccommon.OperationRunning, v1alpha1.SyncStatusCodeOutOfSync, health.HealthStatusHealthy)
+
+	mockClient := mock.NewMockInterface(ctrl)
+	mockClient.EXPECT().Close().Return(nil).AnyTimes()
+	mockClient.EXPECT().GetApplication(gomock.Any(), "guestbook").Return(&app, nil)
+	watchEvents(mockClient,
+		operatingApplication(synccommon.OperationRunning, v1alpha1.SyncStatusCodeOutOfSync, health.HealthStatusHealthy, deployment, hook),
+		operatingApplication(synccommon.OperationSucceeded, v1alpha1.SyncStatusCodeSynced, health.HealthStatusHealthy, deployment, hook),
+	)
+
+	s := server.NewMCPServer("test", "1.0.0")
+	newMockRegistry(mockClient).Register(s)
+	session := newFakeSession("a")
+	ctx := s.WithContext(context.Background(), session)
+	require.NoError(t, s.RegisterSession(ctx, session))
+
+	response := s.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"wait_for_application","arguments":{"name":"guestbook"},"_meta":{"progressToken":"wait-1"}}}`))
+	encoded, err := json.Marshal(response)
+	require.NoError(t, err)
+	assert.Contains(t, string(encoded), `\"result\": \"reached\"`)
+
+	var messages []string
+	for len(session.notifications) > 0 {
+		notification := <-session.notifications
+		assert.Equal(t, methodNotificationProgress, notification.Method)
+		assert.Equal(t, "wait-1", notification.Params.AdditionalFields["progressToken"])
+		assert.EqualValues(t, len(messages)+1, notification.Params.AdditionalFields["progress"])
+		messages = append(messages, notification.Params.AdditionalFields["message"].(string))
+	}
+	assert.Equal(t, []string{
+		"operation Running, sync OutOfSync, health Healthy",
+		"operation Running, sync OutOfSync, health Healthy: Deployment/guestbook-ui Synced, Job/migrate Running",
+		"operation Succeeded, sync Synced, health Healthy",
+	}, messages)
+}
+
+func TestSyncApplicationHandler_Wait(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	deployment := &v1alpha1.ResourceResult{Kind: "Deployment", Namespace: "default", Name: "guestbook-ui", Status: synccommon.ResultCodeSynced}
+	started := operatingApplication(synccommon.OperationRunning, v1alpha1.SyncStatusCodeOutOfSync, health.HealthStatusHealthy)
+
+	mockClient := mock.NewMockInterface(ctrl)
+	mockClient.EXPECT().SyncApplication(gomock.Any(), "guestbook", client.SyncRequest{DryRun: true}).Return(&started, nil)
+	// A dry run is only waited for until its operation finishes
+	watchEvents(mockClient, operatingApplication(synccommon.OperationSucceeded, v1alpha1.SyncStatusCodeOutOfSync, health.HealthStatusHealthy, deployment))
+
+	result, err := syncApplicationHandler(context.Background(), mockClient, "guestbook", client.SyncRequest{DryRun: true}, &syncWait{timeout: 5 * time.Second})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	outcome := decodeResult[WaitOutcome](t, result)
+	assert.Equal(t, WaitReached, outcome.Result)
+	assert.Equal(t, WaitUntilOperation, outcome.Condition)
+	assert.Equal(t, "OutOfSync", outcome.SyncStatus)
+}
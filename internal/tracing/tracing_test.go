@@ -0,0 +1,47 @@
+package tracing
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+)
+
+func TestSetup_FileExporter(t *testing.T) {
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	})
+
+	path := filepath.Join(t.TempDir(), "spans.jsonl")
+	shutdown, err := Setup(context.Background(), Config{Exporter: ExporterFile, File: path, ServiceVersion: "1.2.3"})
+	require.NoError(t, err)
+
+	_, span := Tracer().Start(context.Background(), "test-span")
+	span.End()
+	require.NoError(t, shutdown(context.Background()))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.True(t, strings.Contains(string(data), `"Name":"test-span"`), string(data))
+	assert.Contains(t, string(data), "argocd-mcp-server")
+}
+
+func TestSetup_Disabled(t *testing.T) {
+	for _, exporter := range []string{"", ExporterNone} {
+		shutdown, err := Setup(context.Background(), Config{Exporter: exporter})
+		require.NoError(t, err)
+		assert.NoError(t, shutdown(context.Background()))
+	}
+}
+
+func TestSetup_UnknownExporter(t *testing.T) {
+	_, err := Setup(context.Background(), Config{Exporter: "zipkin"})
+	assert.ErrorContains(t, err, "unknown trace exporter")
+}